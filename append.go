@@ -0,0 +1,80 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+var (
+	// ErrNoContent is returned if there is no content to add to the note.
+	ErrNoContent = errors.New("no content to add")
+)
+
+// now returns the current time. It can be replaced in tests.
+var now = time.Now
+
+// AppendToNote adds the content to the end of the note. The content is
+// converted from markdown unless the RawNote option is set. If the
+// AddTimestamp option is set, the content is preceded by a heading with
+// the current time. The existing content of the note is left untouched.
+func AppendToNote(db Storager, ns NotestoreClient, title, content string, opts NoteOption) error {
+	return insertIntoNote(db, ns, title, content, opts, false)
+}
+
+// PrependToNote adds the content to the start of the note. The content is
+// converted the same way as for AppendToNote.
+func PrependToNote(db Storager, ns NotestoreClient, title, content string, opts NoteOption) error {
+	return insertIntoNote(db, ns, title, content, opts, true)
+}
+
+func insertIntoNote(db Storager, ns NotestoreClient, title, content string, opts NoteOption, prepend bool) error {
+	if strings.TrimSpace(content) == "" {
+		return ErrNoContent
+	}
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	body, err := ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return err
+	}
+	// The note body holds the inner ENML of the en-note element as is.
+	if err = decodeXML(body, n); err != nil {
+		return err
+	}
+	fragment := content
+	if opts&RawNote == 0 {
+		fragment = string(markdown.ToXML(content))
+	}
+	if opts&AddTimestamp != 0 {
+		fragment = "<h3>" + html.EscapeString(now().Format(timestampFormat)) + "</h3>" + fragment
+	}
+	if prepend {
+		n.Body = fragment + n.Body
+	} else {
+		n.Body = n.Body + fragment
+	}
+	return SaveChanges(ns, n, opts|RawNote)
+}
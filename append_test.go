@@ -0,0 +1,74 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendAndPrepend(t *testing.T) {
+	assert := assert.New(t)
+	existing := `<div>Existing <b>content</b></div><en-media hash="abc" type="image/png"/>`
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	setup := func() (*mockNS, *string) {
+		ns := nsWithNote(&Note{Title: "Ops log", GUID: "GUID", Notebook: &Notebook{GUID: "NB"}})
+		ns.getNoteContent = func(string) (string, error) {
+			return XMLHeader + "<en-note>" + existing + "</en-note>", nil
+		}
+		saved := new(string)
+		ns.updateNote = func(n *Note) error {
+			*saved = n.Body
+			return nil
+		}
+		return ns, saved
+	}
+	t.Run("append markdown", func(t *testing.T) {
+		ns, saved := setup()
+		err := AppendToNote(store, ns, "Ops log", "deploy **done**", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note>"+existing+"<p>deploy <strong>done</strong></p>\n</en-note>", *saved)
+	})
+	t.Run("prepend raw", func(t *testing.T) {
+		ns, saved := setup()
+		err := PrependToNote(store, ns, "Ops log", "<div>first</div>", RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div>first</div>"+existing+"</en-note>", *saved)
+	})
+	t.Run("append with timestamp", func(t *testing.T) {
+		now = func() time.Time { return time.Date(2018, 5, 1, 12, 30, 0, 0, time.Local) }
+		defer func() { now = time.Now }()
+		ns, saved := setup()
+		err := AppendToNote(store, ns, "Ops log", "<div>done</div>", RawNote|AddTimestamp)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note>"+existing+"<h3>2018-05-01 12:30:00</h3><div>done</div></en-note>", *saved)
+	})
+	t.Run("no content", func(t *testing.T) {
+		ns, _ := setup()
+		assert.Equal(ErrNoContent, AppendToNote(store, ns, "Ops log", " \n", DefaultNoteOption))
+	})
+	t.Run("note not found", func(t *testing.T) {
+		ns, _ := setup()
+		assert.Equal(ErrNoNoteFound, AppendToNote(store, ns, "Missing", "text", DefaultNoteOption))
+	})
+}
@@ -0,0 +1,330 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBulkWorkers is the default number of notes updated concurrently
+// by a bulk operation.
+const DefaultBulkWorkers = 4
+
+var (
+	// ErrInvalidRange is returned if an index range can't be parsed or is
+	// outside of the note list.
+	ErrInvalidRange = errors.New("invalid index range")
+	// ErrEmptySelection is returned if neither a search, a notebook or an
+	// index range is given for a bulk operation.
+	ErrEmptySelection = errors.New("a search, a notebook or an index range is required")
+	// ErrNoNotesSelected is returned if no notes match the selection.
+	ErrNoNotesSelected = errors.New("no notes selected")
+	// ErrNoTags is returned if a tag operation has no tags.
+	ErrNoTags = errors.New("no tags given")
+	// ErrEmptyTitle is returned if the rename pattern is empty.
+	ErrEmptyTitle = errors.New("title can't be empty")
+)
+
+// BulkSelection selects the notes a bulk operation acts on. If Indexes is
+// set, the notes are taken from the last note list. Otherwise the notes
+// matching the search and the notebook are selected. The search can be
+// an alias or a saved search, see ExpandSearch.
+type BulkSelection struct {
+	// Indexes are 1 based index ranges in the last note list, e.g. "1-5,8".
+	Indexes string
+	// Search is the search query.
+	Search string
+	// Notebook restricts the search to the notebook.
+	Notebook string
+}
+
+// SelectNotes returns the notes matching the selection.
+func SelectNotes(db Storager, ns NotestoreClient, sel *BulkSelection) ([]*Note, error) {
+	if sel.Indexes != "" {
+		list, err := db.GetSearch()
+		if err != nil {
+			return nil, err
+		}
+		indexes, err := ParseIndexRanges(sel.Indexes, len(list))
+		if err != nil {
+			return nil, err
+		}
+		notes := make([]*Note, len(indexes))
+		for i, index := range indexes {
+			notes[i] = list[index]
+		}
+		return notes, nil
+	}
+	if sel.Search == "" && sel.Notebook == "" {
+		return nil, ErrEmptySelection
+	}
+	search, err := ExpandSearch(db, ns, sel.Search)
+	if err != nil {
+		return nil, err
+	}
+	filter := &NoteFilter{Words: search, Order: NoteFilterOrderUpdated}
+	if sel.Notebook != "" {
+		nb, err := FindNotebook(db, ns, sel.Notebook)
+		if err != nil {
+			return nil, err
+		}
+		filter.NotebookGUID = nb.GUID
+	}
+	var notes []*Note
+	for {
+		batch, err := ns.FindNotes(filter, len(notes), noteBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, batch...)
+		if len(batch) < noteBatchSize {
+			break
+		}
+	}
+	if len(notes) == 0 {
+		return nil, ErrNoNotesSelected
+	}
+	return notes, nil
+}
+
+// ParseIndexRanges parses a comma separated list of 1 based indexes and
+// index ranges, e.g. "1-5,8", and returns the 0 based indexes. Duplicate
+// indexes are only returned once.
+func ParseIndexRanges(s string, max int) ([]int, error) {
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, ErrInvalidRange
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, ErrInvalidRange
+			}
+		}
+		if start < 1 || end < start || end > max {
+			return nil, ErrInvalidRange
+		}
+		for i := start - 1; i < end; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// BulkOperation is a change applied to each selected note.
+type BulkOperation struct {
+	// Name is the name of the operation.
+	Name     string
+	describe func(n *Note, i int) string
+	apply    func(ns NotestoreClient, n *Note, i int) error
+}
+
+// Describe returns a description of the change to the i:th note.
+func (o *BulkOperation) Describe(n *Note, i int) string {
+	return o.describe(n, i)
+}
+
+// BulkMove returns an operation that moves the notes to the notebook.
+func BulkMove(db Storager, ns NotestoreClient, notebook string) (*BulkOperation, error) {
+	nb, err := FindNotebook(db, ns, notebook)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkOperation{
+		Name:     "move",
+		describe: func(n *Note, i int) string { return n.Title + " → " + nb.Name },
+		apply: func(ns NotestoreClient, n *Note, i int) error {
+			n.Notebook = nb
+			return ns.UpdateNote(n)
+		},
+	}, nil
+}
+
+// BulkDelete returns an operation that moves the notes to the trash.
+func BulkDelete() *BulkOperation {
+	return &BulkOperation{
+		Name:     "delete",
+		describe: func(n *Note, i int) string { return n.Title },
+		apply:    func(ns NotestoreClient, n *Note, i int) error { return ns.DeleteNote(n.GUID) },
+	}
+}
+
+// BulkTag returns an operation that adds the tags to the notes.
+func BulkTag(tags []string) (*BulkOperation, error) {
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+	return &BulkOperation{
+		Name:     "tag",
+		describe: func(n *Note, i int) string { return n.Title + " +" + strings.Join(tags, " +") },
+		apply: func(ns NotestoreClient, n *Note, i int) error {
+			return changeTags(ns, n, func(current []string) []string {
+				for _, t := range tags {
+					if !containsString(current, t) {
+						current = append(current, t)
+					}
+				}
+				return current
+			})
+		},
+	}, nil
+}
+
+// BulkUntag returns an operation that removes the tags from the notes.
+func BulkUntag(tags []string) (*BulkOperation, error) {
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+	return &BulkOperation{
+		Name:     "untag",
+		describe: func(n *Note, i int) string { return n.Title + " -" + strings.Join(tags, " -") },
+		apply: func(ns NotestoreClient, n *Note, i int) error {
+			return changeTags(ns, n, func(current []string) []string {
+				kept := make([]string, 0, len(current))
+				for _, t := range current {
+					if !containsString(tags, t) {
+						kept = append(kept, t)
+					}
+				}
+				return kept
+			})
+		},
+	}, nil
+}
+
+// BulkRename returns an operation that renames the notes. In the pattern,
+// {{title}} is replaced with the current title and {{n}} with the note's
+// position in the selection, starting at 1.
+func BulkRename(pattern string) (*BulkOperation, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, ErrEmptyTitle
+	}
+	title := func(n *Note, i int) string {
+		r := strings.NewReplacer("{{title}}", n.Title, "{{n}}", strconv.Itoa(i+1))
+		return r.Replace(pattern)
+	}
+	return &BulkOperation{
+		Name:     "rename",
+		describe: func(n *Note, i int) string { return n.Title + " → " + title(n, i) },
+		apply: func(ns NotestoreClient, n *Note, i int) error {
+			n.Title = title(n, i)
+			return ns.UpdateNote(n)
+		},
+	}, nil
+}
+
+func changeTags(ns NotestoreClient, n *Note, change func([]string) []string) error {
+	current, err := ns.GetNoteTags(n.GUID)
+	if err != nil {
+		return err
+	}
+	tags := change(append([]string(nil), current...))
+	if equalStrings(current, tags) {
+		return nil
+	}
+	n.Tags = tags
+	return ns.UpdateNoteTags(n)
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BulkResult is the result of a bulk operation on a note.
+type BulkResult struct {
+	// Note is the note the operation was applied to.
+	Note *Note
+	// Description describes the change to the note.
+	Description string
+	// Err is the error returned by the notestore, if any.
+	Err error
+}
+
+// NotestoreFactory returns a new notestore client. A client can only be
+// used by one goroutine at a time so concurrent work needs one client per
+// goroutine.
+type NotestoreFactory func() (NotestoreClient, error)
+
+// RunBulk applies the operation to the notes using the given number of
+// concurrent workers. Each worker uses its own notestore client from
+// newNS. The results are returned in the same order as the notes.
+func RunBulk(newNS NotestoreFactory, notes []*Note, op *BulkOperation, workers int) ([]*BulkResult, error) {
+	if workers > len(notes) {
+		workers = len(notes)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	clients := make([]NotestoreClient, workers)
+	for w := range clients {
+		ns, err := newNS()
+		if err != nil {
+			return nil, err
+		}
+		clients[w] = ns
+	}
+	results := make([]*BulkResult, len(notes))
+	for i, n := range notes {
+		results[i] = &BulkResult{Note: n, Description: op.Describe(n, i)}
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for _, ns := range clients {
+		wg.Add(1)
+		go func(ns NotestoreClient) {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Err = op.apply(ns, notes[i], i)
+			}
+		}(ns)
+	}
+	for i := range notes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
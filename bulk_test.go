@@ -0,0 +1,202 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIndexRanges(t *testing.T) {
+	assert := assert.New(t)
+	indexes, err := ParseIndexRanges("1-3,5, 2,7-7", 10)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]int{0, 1, 2, 4, 6}, indexes, "Wrong indexes")
+	for _, s := range []string{"", "0", "3-1", "1-11", "a", "1-b", "1,,2"} {
+		_, err := ParseIndexRanges(s, 10)
+		assert.Equal(ErrInvalidRange, err, "Wrong error for "+s)
+	}
+}
+
+func TestSelectNotes(t *testing.T) {
+	assert := assert.New(t)
+	cached := []*Note{&Note{Title: "A"}, &Note{Title: "B"}, &Note{Title: "C"}}
+	store := &mockStore{
+		getSearch: func() ([]*Note, error) { return cached, nil },
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Notebooks: []*Notebook{&Notebook{Name: "Work", GUID: "WORK"}}}, nil
+		},
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	var filters []*NoteFilter
+	ns := &mockNS{
+		getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{&Notebook{Name: "Work", GUID: "WORK"}}, nil },
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			filters = append(filters, f)
+			// Return two full pages followed by a partial page.
+			if offset < 2*count {
+				return make([]*Note, count), nil
+			}
+			return []*Note{&Note{Title: "Last"}}, nil
+		},
+	}
+
+	t.Run("index ranges", func(t *testing.T) {
+		notes, err := SelectNotes(store, ns, &BulkSelection{Indexes: "3,1"})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Note{cached[2], cached[0]}, notes)
+	})
+	t.Run("search", func(t *testing.T) {
+		notes, err := SelectNotes(store, ns, &BulkSelection{Search: "report", Notebook: "Work"})
+		assert.NoError(err, "Should not return an error")
+		assert.Len(notes, 2*noteBatchSize+1, "All pages should be fetched")
+		assert.Equal("report", filters[0].Words, "Wrong search")
+		assert.Equal("WORK", filters[0].NotebookGUID, "Wrong notebook")
+	})
+	t.Run("empty selection", func(t *testing.T) {
+		_, err := SelectNotes(store, ns, &BulkSelection{})
+		assert.Equal(ErrEmptySelection, err, "Wrong error")
+	})
+}
+
+func TestBulkOperations(t *testing.T) {
+	assert := assert.New(t)
+	errFailed := errors.New("failed")
+	var mu sync.Mutex
+	tags := map[string][]string{"1": []string{"a"}, "2": []string{"a", "b"}, "3": nil}
+	var updated, deleted []string
+	ns := &mockNS{
+		getNoteTags: func(guid string) ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return tags[guid], nil
+		},
+		updateNoteTags: func(n *Note) error {
+			mu.Lock()
+			defer mu.Unlock()
+			tags[n.GUID] = n.Tags
+			return nil
+		},
+		updateNote: func(n *Note) error {
+			mu.Lock()
+			defer mu.Unlock()
+			updated = append(updated, n.Title)
+			return nil
+		},
+		deleteNote: func(guid string) error {
+			if guid == "2" {
+				return errFailed
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, guid)
+			return nil
+		},
+	}
+	notes := func() []*Note {
+		return []*Note{&Note{Title: "One", GUID: "1"}, &Note{Title: "Two", GUID: "2"}, &Note{Title: "Three", GUID: "3"}}
+	}
+	shared := func() (NotestoreClient, error) { return ns, nil }
+
+	t.Run("tag", func(t *testing.T) {
+		op, err := BulkTag([]string{"b", "c"})
+		assert.NoError(err, "Should not return an error")
+		results, _ := RunBulk(shared, notes(), op, 2)
+		assert.Equal("One +b +c", results[0].Description, "Wrong description")
+		assert.Equal([]string{"a", "b", "c"}, tags["1"])
+		assert.Equal([]string{"a", "b", "c"}, tags["2"])
+		assert.Equal([]string{"b", "c"}, tags["3"])
+	})
+	t.Run("untag", func(t *testing.T) {
+		op, err := BulkUntag([]string{"a", "c"})
+		assert.NoError(err, "Should not return an error")
+		RunBulk(shared, notes(), op, 2)
+		assert.Equal([]string{"b"}, tags["1"])
+		assert.Equal([]string{"b"}, tags["3"])
+		_, err = BulkUntag(nil)
+		assert.Equal(ErrNoTags, err, "Wrong error")
+	})
+	t.Run("rename", func(t *testing.T) {
+		op, err := BulkRename("{{n}}. {{title}}")
+		assert.NoError(err, "Should not return an error")
+		results, _ := RunBulk(shared, notes(), op, 3)
+		assert.Equal("Two → 2. Two", results[1].Description, "Wrong description")
+		sort.Strings(updated)
+		assert.Equal([]string{"1. One", "2. Two", "3. Three"}, updated)
+		_, err = BulkRename(" ")
+		assert.Equal(ErrEmptyTitle, err, "Wrong error")
+	})
+	t.Run("delete reports failures", func(t *testing.T) {
+		results, _ := RunBulk(shared, notes(), BulkDelete(), 0)
+		assert.NoError(results[0].Err)
+		assert.Equal(errFailed, results[1].Err, "Failure should be reported")
+		assert.NoError(results[2].Err)
+		assert.Equal([]string{"1", "3"}, deleted)
+	})
+}
+
+func TestRunBulkClientPerWorker(t *testing.T) {
+	assert := assert.New(t)
+	errConcurrent := errors.New("concurrent call")
+	var mu sync.Mutex
+	var deleted []string
+	created := 0
+	newNS := func() (NotestoreClient, error) {
+		created++
+		// The client is like the thrift client, a call while another call
+		// is in flight fails.
+		var busy int32
+		return &mockNS{deleteNote: func(guid string) error {
+			if !atomic.CompareAndSwapInt32(&busy, 0, 1) {
+				return errConcurrent
+			}
+			defer atomic.StoreInt32(&busy, 0)
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, guid)
+			return nil
+		}}, nil
+	}
+	var notes []*Note
+	for i := 0; i < 20; i++ {
+		notes = append(notes, &Note{Title: strconv.Itoa(i), GUID: strconv.Itoa(i)})
+	}
+	results, err := RunBulk(newNS, notes, BulkDelete(), 4)
+	assert.NoError(err, "Should not return an error")
+	for _, r := range results {
+		assert.NoError(r.Err, "A client should not be used concurrently")
+	}
+	assert.Len(deleted, 20, "All notes should be deleted")
+	assert.Equal(4, created, "Each worker should have a client")
+
+	created = 0
+	RunBulk(newNS, notes[:2], BulkDelete(), 4)
+	assert.Equal(2, created, "No more clients than notes should be created")
+
+	errConnect := errors.New("connect failed")
+	_, err = RunBulk(func() (NotestoreClient, error) { return nil, errConnect }, notes, BulkDelete(), 2)
+	assert.Equal(errConnect, err, "Wrong error returned")
+}
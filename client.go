@@ -42,6 +42,7 @@ func NewClient(config Configuration, store Storager, ns NotestoreClient, opts Cl
 	} else {
 		c.Editor = new(EnvEditor)
 	}
+	c.Confirmer = &TerminalConfirmer{In: os.Stdin, Out: os.Stdout}
 	return c
 }
 
@@ -68,7 +69,9 @@ type Client struct {
 	// Notestore is a client to interact with the note store.
 	NoteStore NotestoreClient
 	// Editor is the editor.
-	Editor       Editer
+	Editor Editer
+	// Confirmer is used to review changes before they are saved.
+	Confirmer    Confirmer
 	newCacheFile func(c *Client, filename string) (CacheFile, error)
 	clientOpts   ClientOption
 }
@@ -0,0 +1,113 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var appendNoteCmd = &cobra.Command{
+	Use:   "append \"note title\"",
+	Short: "Append stdin to a note.",
+	Long: `
+Append reads the content from stdin and adds it to the end of the note.
+The content is converted from markdown unless the raw flag is set, in
+which case it's added as ENML. The rest of the note is left untouched.
+
+Example:
+  echo "deploy done" | clinote note append "Ops log" --timestamp
+` + noteReferenceHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		insertIntoNote(cmd, args, clinote.AppendToNote)
+	},
+}
+
+var prependNoteCmd = &cobra.Command{
+	Use:   "prepend \"note title\"",
+	Short: "Prepend stdin to a note.",
+	Long: `
+Prepend reads the content from stdin and adds it to the start of the note.
+The content is converted from markdown unless the raw flag is set, in
+which case it's added as ENML. The rest of the note is left untouched.
+` + noteReferenceHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		insertIntoNote(cmd, args, clinote.PrependToNote)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(appendNoteCmd)
+	noteCmd.AddCommand(prependNoteCmd)
+	for _, c := range []*cobra.Command{appendNoteCmd, prependNoteCmd} {
+		c.Flags().Bool("raw", false, "The content is ENML instead of markdown.")
+		c.Flags().BoolP("timestamp", "t", false, "Add a heading with the current time.")
+	}
+}
+
+type insertFunc func(db clinote.Storager, ns clinote.NotestoreClient, title, content string, opts clinote.NoteOption) error
+
+func insertIntoNote(cmd *cobra.Command, args []string, insert insertFunc) {
+	if len(args) != 1 {
+		fmt.Println("❌ Note identifier required")
+		fmt.Printf("💡 Usage: echo \"text\" | clinote note %s \"Note Title\"\n", cmd.Name())
+		printNoteReferenceHelp()
+		return
+	}
+	opts := clinote.DefaultNoteOption
+	raw, err := cmd.Flags().GetBool("raw")
+	if err != nil {
+		fmt.Printf("❌ Invalid raw flag value: %v\n", err)
+		return
+	}
+	if raw {
+		opts |= clinote.RawNote
+	}
+	timestamp, err := cmd.Flags().GetBool("timestamp")
+	if err != nil {
+		fmt.Printf("❌ Invalid timestamp flag value: %v\n", err)
+		return
+	}
+	if timestamp {
+		opts |= clinote.AddTimestamp
+	}
+	content, err := ioutil.ReadAll(stdin())
+	if err != nil {
+		fmt.Printf("❌ Failed to read stdin: %v\n", err)
+		exit(1)
+	}
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	err = insert(client.Config.Store(), ns, args[0], string(content), opts)
+	if err != nil {
+		fmt.Printf("❌ Failed to update note: %v\n", err)
+		printNoteSuggestions(client.Config.Store(), ns, args[0], err)
+		if err == clinote.ErrNoContent {
+			fmt.Println("💡 Pipe the content to the command: echo \"text\" | clinote note " + cmd.Name() + " \"Note Title\"")
+		}
+		exit(1)
+	}
+	fmt.Println("✅ Note updated")
+}
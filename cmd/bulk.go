@@ -0,0 +1,235 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+const bulkSelectionHelp = `
+The notes are selected with --search and --notebook, or by index ranges
+from the last note list, e.g. "1-5,8". The changes are listed before they
+are applied. Use --dry-run to only list the changes.`
+
+var bulkCmd = &cobra.Command{
+	Use:   "bulk",
+	Short: "Move, delete, tag or rename many notes at once.",
+	Long: `
+Bulk applies an operation to many notes at once.
+` + bulkSelectionHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var bulkMoveCmd = &cobra.Command{
+	Use:   "move [index ranges] --to \"notebook\"",
+	Short: "Move notes to a notebook.",
+	Long: `
+Move moves the selected notes to the notebook.
+` + bulkSelectionHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			fmt.Printf("❌ Invalid to flag value: %v\n", err)
+			return
+		}
+		runBulk(cmd, args, func(db clinote.Storager, ns clinote.NotestoreClient) (*clinote.BulkOperation, error) {
+			if to == "" {
+				return nil, clinote.ErrNoNotebookFound
+			}
+			return clinote.BulkMove(db, ns, to)
+		})
+	},
+}
+
+var bulkDeleteCmd = &cobra.Command{
+	Use:   "delete [index ranges]",
+	Short: "Move notes to the trash.",
+	Long: `
+Delete moves the selected notes to the trash.
+` + bulkSelectionHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBulk(cmd, args, func(clinote.Storager, clinote.NotestoreClient) (*clinote.BulkOperation, error) {
+			return clinote.BulkDelete(), nil
+		})
+	},
+}
+
+var bulkTagCmd = &cobra.Command{
+	Use:   "tag [index ranges] --tag \"tag\"",
+	Short: "Add tags to notes.",
+	Long: `
+Tag adds the tags to the selected notes. Tags that don't exist are created.
+` + bulkSelectionHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		tags, err := cmd.Flags().GetStringSlice("tag")
+		if err != nil {
+			fmt.Printf("❌ Invalid tag flag value: %v\n", err)
+			return
+		}
+		runBulk(cmd, args, func(clinote.Storager, clinote.NotestoreClient) (*clinote.BulkOperation, error) {
+			return clinote.BulkTag(tags)
+		})
+	},
+}
+
+var bulkUntagCmd = &cobra.Command{
+	Use:   "untag [index ranges] --tag \"tag\"",
+	Short: "Remove tags from notes.",
+	Long: `
+Untag removes the tags from the selected notes.
+` + bulkSelectionHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		tags, err := cmd.Flags().GetStringSlice("tag")
+		if err != nil {
+			fmt.Printf("❌ Invalid tag flag value: %v\n", err)
+			return
+		}
+		runBulk(cmd, args, func(clinote.Storager, clinote.NotestoreClient) (*clinote.BulkOperation, error) {
+			return clinote.BulkUntag(tags)
+		})
+	},
+}
+
+var bulkRenameCmd = &cobra.Command{
+	Use:   "rename [index ranges] --title \"pattern\"",
+	Short: "Rename notes.",
+	Long: `
+Rename changes the title of the selected notes. In the title, {{title}}
+is replaced with the current title and {{n}} with the note's position in
+the selection, e.g. --title "[Archived] {{title}}".
+` + bulkSelectionHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			fmt.Printf("❌ Invalid title flag value: %v\n", err)
+			return
+		}
+		runBulk(cmd, args, func(clinote.Storager, clinote.NotestoreClient) (*clinote.BulkOperation, error) {
+			return clinote.BulkRename(title)
+		})
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(bulkCmd)
+	bulkCmd.AddCommand(bulkMoveCmd)
+	bulkCmd.AddCommand(bulkDeleteCmd)
+	bulkCmd.AddCommand(bulkTagCmd)
+	bulkCmd.AddCommand(bulkUntagCmd)
+	bulkCmd.AddCommand(bulkRenameCmd)
+	bulkCmd.PersistentFlags().StringP("search", "s", "", "Select the notes matching the search query.")
+	bulkCmd.PersistentFlags().StringP("notebook", "b", "", "Select the notes in the notebook.")
+	bulkCmd.PersistentFlags().Bool("dry-run", false, "Only list the changes.")
+	bulkCmd.PersistentFlags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+	bulkCmd.PersistentFlags().Int("workers", clinote.DefaultBulkWorkers, "Number of notes updated concurrently.")
+	bulkMoveCmd.Flags().String("to", "", "The notebook to move the notes to.")
+	bulkTagCmd.Flags().StringSliceP("tag", "t", nil, "Tag to add, can be repeated.")
+	bulkUntagCmd.Flags().StringSliceP("tag", "t", nil, "Tag to remove, can be repeated.")
+	bulkRenameCmd.Flags().String("title", "", "The new title pattern.")
+}
+
+// runBulk selects the notes, lists the changes and applies the operation
+// once confirmed.
+func runBulk(cmd *cobra.Command, args []string, newOp func(clinote.Storager, clinote.NotestoreClient) (*clinote.BulkOperation, error)) {
+	if len(args) > 1 {
+		fmt.Println("❌ Too many arguments")
+		fmt.Println("💡 Use comma separated index ranges: clinote note bulk delete 1-5,8")
+		return
+	}
+	sel := new(clinote.BulkSelection)
+	if len(args) == 1 {
+		sel.Indexes = args[0]
+	}
+	var err error
+	if sel.Search, err = cmd.Flags().GetString("search"); err != nil {
+		fmt.Printf("❌ Invalid search flag value: %v\n", err)
+		return
+	}
+	if sel.Notebook, err = cmd.Flags().GetString("notebook"); err != nil {
+		fmt.Printf("❌ Invalid notebook flag value: %v\n", err)
+		return
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		fmt.Printf("❌ Invalid dry-run flag value: %v\n", err)
+		return
+	}
+	workers, err := cmd.Flags().GetInt("workers")
+	if err != nil {
+		fmt.Printf("❌ Invalid workers flag value: %v\n", err)
+		return
+	}
+
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	db := client.Config.Store()
+	op, err := newOp(db, ns)
+	if err != nil {
+		fmt.Printf("❌ Invalid %s operation: %v\n", cmd.Name(), err)
+		cmd.Usage()
+		exit(1)
+	}
+	notes, err := clinote.SelectNotes(db, ns, sel)
+	if err != nil {
+		fmt.Printf("❌ Failed to select notes: %v\n", err)
+		fmt.Println("💡 Select notes with:")
+		fmt.Println("   • A search: --search \"query\"")
+		fmt.Println("   • A notebook: --notebook \"Notebook\"")
+		fmt.Println("   • Index ranges from: clinote note list")
+		exit(1)
+	}
+
+	fmt.Printf("%d notes to %s:\n", len(notes), op.Name)
+	for i, n := range notes {
+		fmt.Printf("   • %s\n", op.Describe(n, i))
+	}
+	if dryRun {
+		return
+	}
+	if !confirmed(cmd, fmt.Sprintf("Apply %s to %d notes?", op.Name, len(notes))) {
+		return
+	}
+
+	results, err := clinote.RunBulk(client.NewNoteStore, notes, op, workers)
+	if err != nil {
+		fmt.Printf("❌ Failed to connect to the notestore: %v\n", err)
+		exit(1)
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("❌ %s: %v\n", r.Description, r.Err)
+			continue
+		}
+		fmt.Printf("✅ %s\n", r.Description)
+	}
+	fmt.Printf("%d succeeded, %d failed\n", len(notes)-failed, failed)
+	if failed > 0 {
+		exit(1)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+// notebookFlags and stackFlags are the flags that take a notebook or a
+// stack name.
+var (
+	notebookFlags = []string{"notebook", "to", "move-to"}
+	stackFlags    = []string{"stack"}
+)
+
+// completeArgs returns the completions of the word being typed after the
+// arguments. The completions come from the command tree and the local
+// cache, nothing is fetched from the notestore.
+func completeArgs(db clinote.Storager, args []string, toComplete string) []string {
+	cmd, positional := findCommand(args)
+	if len(args) > 0 {
+		if f := lookupFlag(cmd, args[len(args)-1]); f != nil && takesValue(f) && !strings.Contains(args[len(args)-1], "=") {
+			return flagValues(db, f, toComplete)
+		}
+	}
+	if strings.HasPrefix(toComplete, "-") {
+		if i := strings.Index(toComplete, "="); i > 0 {
+			f := lookupFlag(cmd, toComplete[:i])
+			if f == nil {
+				return nil
+			}
+			values := flagValues(db, f, toComplete[i+1:])
+			for j, v := range values {
+				values[j] = toComplete[:i+1] + v
+			}
+			return values
+		}
+		return flagNames(cmd, toComplete)
+	}
+	var completions []string
+	if len(positional) == 0 {
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() && strings.HasPrefix(sub.Name(), toComplete) {
+				completions = append(completions, sub.Name())
+			}
+		}
+		completions = append(completions, argValues(db, cmd, toComplete)...)
+	}
+	return completions
+}
+
+// findCommand returns the command the arguments run and the positional
+// arguments given to it.
+func findCommand(args []string) (*cobra.Command, []string) {
+	cmd := RootCmd
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") {
+			if f := lookupFlag(cmd, arg); f != nil && takesValue(f) && !strings.Contains(arg, "=") {
+				i++
+			}
+			continue
+		}
+		if len(positional) == 0 {
+			if sub := subcommand(cmd, arg); sub != nil {
+				cmd = sub
+				continue
+			}
+		}
+		positional = append(positional, arg)
+	}
+	return cmd, positional
+}
+
+func subcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// commandFlags returns the command's flags and the persistent flags of
+// its parents.
+func commandFlags(cmd *cobra.Command) []*flag.Flag {
+	var flags []*flag.Flag
+	seen := make(map[string]bool)
+	add := func(f *flag.Flag) {
+		if !seen[f.Name] && !f.Hidden {
+			seen[f.Name] = true
+			flags = append(flags, f)
+		}
+	}
+	cmd.Flags().VisitAll(add)
+	for c := cmd; c != nil; c = c.Parent() {
+		c.PersistentFlags().VisitAll(add)
+	}
+	return flags
+}
+
+// lookupFlag returns the flag for the argument, --name, --name=value or
+// -n.
+func lookupFlag(cmd *cobra.Command, arg string) *flag.Flag {
+	name := strings.TrimLeft(arg, "-")
+	if i := strings.Index(name, "="); i >= 0 {
+		name = name[:i]
+	}
+	short := !strings.HasPrefix(arg, "--")
+	for _, f := range commandFlags(cmd) {
+		if (!short && f.Name == name) || (short && f.Shorthand == name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// takesValue returns true if the flag's value is the next argument.
+func takesValue(f *flag.Flag) bool {
+	return f.Value.Type() != "bool" && f.NoOptDefVal == ""
+}
+
+func flagNames(cmd *cobra.Command, prefix string) []string {
+	var names []string
+	for _, f := range commandFlags(cmd) {
+		if name := "--" + f.Name; strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func flagValues(db clinote.Storager, f *flag.Flag, prefix string) []string {
+	var values []string
+	switch {
+	case containsName(notebookFlags, f.Name):
+		values, _ = clinote.CompleteNotebookNames(db, prefix)
+	case containsName(stackFlags, f.Name):
+		values, _ = clinote.CompleteStackNames(db, prefix)
+	}
+	return values
+}
+
+// argValues returns the completions of the command's first argument. The
+// kind of argument is taken from the command's usage line.
+func argValues(db clinote.Storager, cmd *cobra.Command, prefix string) []string {
+	var values []string
+	switch {
+	case strings.Contains(cmd.Use, `"note title"`):
+		values, _ = clinote.CompleteNoteTitles(db, prefix)
+	case strings.Contains(cmd.Use, `"notebook name"`) && cmd != newBookCmd:
+		values, _ = clinote.CompleteNotebookNames(db, prefix)
+	case strings.Contains(cmd.Use, `"stack name"`):
+		values, _ = clinote.CompleteStackNames(db, prefix)
+	}
+	return values
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/storage"
+	"github.com/spf13/cobra"
+)
+
+// completeTimeout is how long the completion waits for the database lock.
+// The database is locked while another clinote command is running and a
+// completion that never returns is worse than no completion.
+const completeTimeout = time.Second
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish",
+	Short: "Print the shell completion script.",
+	Long: `
+Completion prints a script that completes commands, flags, note titles
+and notebook and stack names in bash, zsh or fish. Note titles come from
+the last search and the link index and the names from the notebook cache,
+nothing is fetched from Evernote while completing. Run "clinote notebook
+list" to refresh the notebook cache.
+
+Bash:
+  source <(clinote completion bash)
+
+Zsh:
+  source <(clinote completion zsh)
+
+Fish:
+  clinote completion fish > ~/.config/fish/completions/clinote.fish`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Shell must be given.")
+			exit(1)
+		}
+		script, ok := completionScripts[args[0]]
+		if !ok {
+			fmt.Printf("❌ Unknown shell %s, expected bash, zsh or fish.\n", args[0])
+			exit(1)
+		}
+		fmt.Print(script)
+	},
+}
+
+// completeCmd is called by the completion scripts with the words before
+// the cursor. The last argument is the word being completed.
+var completeCmd = &cobra.Command{
+	Use:                "__complete",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			return
+		}
+		args, toComplete := args[:len(args)-1], args[len(args)-1]
+		// Bash passes an opening quote as part of the word.
+		toComplete = strings.TrimLeft(toComplete, `"'`)
+		db, err := storage.OpenTimeout((new(clinote.DefaultConfig)).GetConfigFolder(), completeTimeout)
+		if err != nil {
+			return
+		}
+		defer db.Close()
+		for _, c := range completeArgs(db, args, toComplete) {
+			fmt.Println(c)
+		}
+	},
+}
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+const bashCompletion = `# bash completion for clinote
+
+_clinote() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local words=("${COMP_WORDS[@]:1:COMP_CWORD-1}")
+    local prefix=""
+    # Bash splits --flag=value into three words.
+    if [[ "$cur" == "=" ]]; then
+        prefix="="
+        cur=""
+    elif [[ "${words[${#words[@]}-1]}" == "=" ]]; then
+        unset 'words[${#words[@]}-1]'
+    fi
+    local IFS=$'\n'
+    local c completions=($(clinote __complete "${words[@]}" "$cur" 2>/dev/null))
+    COMPREPLY=()
+    for c in "${completions[@]}"; do
+        COMPREPLY+=("$prefix$(printf '%q' "$c")")
+    done
+}
+
+complete -o default -F _clinote clinote
+`
+
+const zshCompletion = `#compdef clinote
+# zsh completion for clinote
+
+_clinote() {
+    local -a completions
+    completions=("${(@f)$(clinote __complete "${(@Q)words[2,CURRENT-1]}" "${(Q)PREFIX}" 2>/dev/null)}")
+    completions=(${completions:#})
+    if (( ${#completions} )); then
+        compadd -a completions
+    else
+        _files
+    fi
+}
+
+if [[ "$funcstack[1]" == "_clinote" ]]; then
+    _clinote "$@"
+else
+    compdef _clinote clinote
+fi
+`
+
+const fishCompletion = `# fish completion for clinote
+
+function __clinote_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    clinote __complete $args (commandline -ct | string trim -l -c "\"'") 2>/dev/null
+end
+
+complete -c clinote -f -a '(__clinote_complete)'
+`
+
+func init() {
+	RootCmd.AddCommand(completionCmd)
+	RootCmd.AddCommand(completeCmd)
+}
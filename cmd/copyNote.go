@@ -0,0 +1,116 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/evernote"
+	"github.com/spf13/cobra"
+)
+
+var copyNoteCmd = &cobra.Command{
+	Use:   "copy \"note title\"",
+	Short: "Copy a note to a notebook or another account.",
+	Long: `
+Copy creates a copy of the note in the notebook. If no notebook is given,
+a duplicate of the note is created in the same notebook.
+
+With --to-credential, the note is copied to the account of another stored
+credential. The content, attributes, tags and attached files are copied.
+The index of the credential is shown by: clinote user list. If no notebook
+is given, the note is created in the default notebook of the account.
+` + noteReferenceHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		args, ok := interactiveArgs(cmd, args)
+		if !ok {
+			return
+		}
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote note copy \"Note Title\" --notebook \"Notebook\"")
+			printNoteReferenceHelp()
+			return
+		}
+		nb, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Printf("❌ Invalid notebook flag value: %v\n", err)
+			return
+		}
+		credIndex, err := cmd.Flags().GetInt("to-credential")
+		if err != nil {
+			fmt.Printf("❌ Invalid to-credential flag value: %v\n", err)
+			return
+		}
+
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		if !cmd.Flags().Changed("to-credential") {
+			err = clinote.CopyNote(db, ns, args[0], nb)
+			if err != nil {
+				fmt.Printf("❌ Failed to copy note: %v\n", err)
+				printNoteSuggestions(db, ns, args[0], err)
+				printCopyTroubleshooting()
+				exit(1)
+			}
+			fmt.Println("✅ Note copied")
+			return
+		}
+
+		// Index is a 1 based index for the user.
+		cred, err := clinote.GetCredential(client.Config.UserStore(), credIndex-1)
+		if err != nil {
+			fmt.Printf("❌ Credential index %d is invalid: %v\n", credIndex, err)
+			fmt.Println("💡 View available credentials: clinote user list")
+			exit(1)
+		}
+		dst, err := evernote.NewClientWithCredential(client.Config, cred).GetNoteStore()
+		if err != nil {
+			fmt.Printf("❌ Failed to connect to the account \"%s\": %v\n", cred.Name, err)
+			exit(1)
+		}
+		err = clinote.TransferNote(db, ns, dst, args[0], nb)
+		if err != nil {
+			fmt.Printf("❌ Failed to copy note to \"%s\": %v\n", cred.Name, err)
+			printNoteSuggestions(db, ns, args[0], err)
+			printCopyTroubleshooting()
+			exit(1)
+		}
+		fmt.Printf("✅ Note copied to \"%s\"\n", cred.Name)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(copyNoteCmd)
+	copyNoteCmd.Flags().StringP("notebook", "b", "", "The notebook to copy the note to.")
+	copyNoteCmd.Flags().Int("to-credential", 0, "Copy the note to the account of the credential with this index.")
+	copyNoteCmd.Flags().BoolP("interactive", "i", false, "Pick the note from a list.")
+}
+
+func printCopyTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • Check the notebook exists: clinote notebook list")
+	fmt.Println("   • Copying between accounts requires full access API keys")
+	fmt.Println("   • Check network connection for Evernote sync")
+}
@@ -19,7 +19,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -29,13 +28,17 @@ var deleteNoteCmd = &cobra.Command{
 	Use:   "delete \"note title\"",
 	Short: "Delete note.",
 	Long: `Moves the note into the trash. The note may still be undeleted, unless it is expunged.
-To expunge the note you need to use the official client or the web client.`,
+The note can be restored or expunged with the trash command.
+` + noteReferenceHelp,
 	Run: func(cmd *cobra.Command, args []string) {
+		args, ok := interactiveArgs(cmd, args)
+		if !ok {
+			return
+		}
 		if len(args) != 1 {
 			fmt.Println("❌ Note identifier required")
 			fmt.Println("💡 Usage: clinote note delete \"Note Title\"")
-			fmt.Println("   • Use exact note title (case sensitive)")
-			fmt.Println("   • Or use note index from: clinote note list")
+			printNoteReferenceHelp()
 			return
 		}
 		nb, err := cmd.Flags().GetString("notebook")
@@ -53,12 +56,13 @@ To expunge the note you need to use the official client or the web client.`,
 		err = clinote.DeleteNote(client.Config.Store(), ns, args[0], nb)
 		if err != nil {
 			fmt.Printf("❌ Failed to delete note: %v\n", err)
+			printNoteSuggestions(client.Config.Store(), ns, args[0], err)
 			fmt.Println("💡 Possible causes:")
 			fmt.Println("   • Note not found or already deleted")
 			fmt.Println("   • Network connectivity issues")
 			fmt.Println("   • Insufficient permissions")
 			fmt.Println("   • Note is being edited elsewhere")
-			os.Exit(1)
+			exit(1)
 		}
 	},
 }
@@ -66,4 +70,5 @@ To expunge the note you need to use the official client or the web client.`,
 func init() {
 	noteCmd.AddCommand(deleteNoteCmd)
 	deleteNoteCmd.Flags().StringP("notebook", "b", "", "The notebook of the note.")
+	deleteNoteCmd.Flags().BoolP("interactive", "i", false, "Pick the note from a list.")
 }
@@ -0,0 +1,80 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var deleteNotebookCmd = &cobra.Command{
+	Use:   "delete \"notebook name\"",
+	Short: "Permanently remove a notebook.",
+	Long: `
+Delete permanently removes the notebook. The notes in the notebook
+are removed with it unless they are moved to another notebook first
+with the move-to flag.
+
+Removing a notebook requires an API key with full access.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Notebook name required")
+			fmt.Println("💡 Usage: clinote notebook delete \"Notebook Name\"")
+			fmt.Println("   • List notebooks: clinote notebook list")
+			return
+		}
+		moveTo, err := cmd.Flags().GetString("move-to")
+		if err != nil {
+			fmt.Printf("❌ Invalid move-to parameter: %v\n", err)
+			fmt.Println("💡 Tip: Use --move-to \"Notebook Name\"")
+			return
+		}
+		question := fmt.Sprintf("Permanently remove \"%s\" and all its notes?", args[0])
+		if moveTo != "" {
+			question = fmt.Sprintf("Move the notes to \"%s\" and remove \"%s\"?", moveTo, args[0])
+		}
+		if !confirmed(cmd, question) {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.DeleteNotebook(client.Config.Store(), ns, args[0], moveTo)
+		if err != nil {
+			fmt.Printf("❌ Failed to delete notebook: %v\n", err)
+			fmt.Println("💡 Possible causes:")
+			fmt.Println("   • Notebook not found")
+			fmt.Println("   • The API key doesn't have full access")
+			fmt.Println("   • The notebook is the default notebook")
+			fmt.Println("   • Network connectivity issues")
+			exit(1)
+		}
+		fmt.Println("✅ Notebook deleted")
+	},
+}
+
+func init() {
+	notebookCmd.AddCommand(deleteNotebookCmd)
+	deleteNotebookCmd.Flags().StringP("move-to", "m", "", "Move the notes to this notebook before deleting.")
+	deleteNotebookCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+}
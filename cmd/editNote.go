@@ -19,7 +19,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/TcM1911/clinote"
@@ -35,11 +34,16 @@ with the editor defined by the environment variable $EDITOR.
 
 The first line will be used as the note title and the rest is encoded as
 the note content.
+` + noteReferenceHelp + `
 
 To change to title, the title flag can be used.
 
 The note can be moved to another notebook by defining the new notebook
-with the notebook flag.`,
+with the notebook flag.
+
+If the confirm flag is set, or the confirm-edit setting is enabled, the
+changes are shown as a diff once the editor is closed. The changes can
+then be saved, edited again or discarded.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		raw, err := cmd.Flags().GetBool("raw")
 		if err != nil {
@@ -63,6 +67,16 @@ with the notebook flag.`,
 		if err != nil {
 			return
 		}
+		confirm, err := cmd.Flags().GetBool("confirm")
+		if err != nil {
+			fmt.Printf("❌ Invalid confirm flag value: %v\n", err)
+			fmt.Println("💡 Tip: Use --confirm (no value needed) to review changes before saving")
+			return
+		}
+		args, ok := interactiveArgs(cmd, args)
+		if !ok {
+			return
+		}
 		client := defaultClient()
 		defer client.Close()
 		ns, err := client.GetNoteStore()
@@ -78,8 +92,14 @@ with the notebook flag.`,
 		if raw {
 			opts = opts | clinote.RawNote
 		}
+		if settings, err := client.Config.Store().GetSettings(); err == nil && settings.ConfirmEdit {
+			confirm = true
+		}
+		if confirm {
+			opts = opts | clinote.ConfirmChanges
+		}
 		if recover {
-			c := clinote.NewClient(client.Config, client.Config.Store(), ns, clinote.DefaultClientOptions)
+			c := noteClient(client, ns, clinote.DefaultClientOptions)
 			err := clinote.EditNote(c, "", opts|clinote.UseRecoveryPointNote)
 			if err != nil {
 				fmt.Printf("❌ Failed to recover previous note: %v\n", err)
@@ -87,29 +107,48 @@ with the notebook flag.`,
 				fmt.Println("   • No recovery point available")
 				fmt.Println("   • Recovery file corrupted")
 				fmt.Println("   • Storage permission issues")
-				os.Exit(1)
+				exit(1)
 			}
 			return
 		}
 		if len(args) != 1 {
 			fmt.Println("❌ Note identifier required")
 			fmt.Println("💡 Usage: clinote note edit \"Note Title\"")
-			fmt.Println("   • Use exact note title (case sensitive)")
-			fmt.Println("   • Or use note index from: clinote note list")
+			printNoteReferenceHelp()
 			return
 		}
-		if title != "" {
-			clinote.ChangeTitle(client.Config.Store(), ns, args[0], title)
-		}
-		if notebook != "" {
-			clinote.MoveNote(client.Config.Store(), ns, args[0], notebook)
+		if title != "" || notebook != "" {
+			// Resolve the note once so the reference stays valid after
+			// the title or notebook has been changed.
+			n, err := clinote.GetNote(client.Config.Store(), ns, args[0], "")
+			if err != nil {
+				fmt.Printf("❌ Failed to find note: %v\n", err)
+				printNoteSuggestions(client.Config.Store(), ns, args[0], err)
+				printNoteReferenceHelp()
+				exit(1)
+			}
+			ref := clinote.GUIDPrefix + n.GUID
+			if title != "" {
+				if err = clinote.ChangeTitle(client.Config.Store(), ns, ref, title); err != nil {
+					fmt.Printf("❌ Failed to change title: %v\n", err)
+					exit(1)
+				}
+			}
+			if notebook != "" {
+				if err = clinote.MoveNote(client.Config.Store(), ns, ref, notebook); err != nil {
+					fmt.Printf("❌ Failed to move note: %v\n", err)
+					fmt.Println("💡 List notebooks: clinote notebook list")
+					exit(1)
+				}
+			}
 		}
 
 		if title == "" && notebook == "" {
-			c := clinote.NewClient(client.Config, client.Config.Store(), ns, clinote.DefaultClientOptions)
+			c := noteClient(client, ns, clinote.DefaultClientOptions)
 			err := clinote.EditNote(c, args[0], opts)
 			if err != nil {
 				fmt.Printf("❌ Failed to edit note: %v\n", err)
+				printNoteSuggestions(client.Config.Store(), ns, args[0], err)
 				fmt.Println("💡 Troubleshooting:")
 				fmt.Println("   • Check if note exists: clinote note list --search \"title\"")
 				fmt.Println("   • Verify editor: echo $EDITOR")
@@ -117,7 +156,7 @@ with the notebook flag.`,
 				if strings.Contains(err.Error(), "not found") {
 					fmt.Println("   • Note may have been deleted or moved")
 				}
-				os.Exit(1)
+				exit(1)
 			}
 		}
 	},
@@ -129,4 +168,6 @@ func init() {
 	editNoteCmd.Flags().StringP("notebook", "b", "", "Move the note to notebook.")
 	editNoteCmd.Flags().Bool("raw", false, "Use raw content instead of markdown version.")
 	editNoteCmd.Flags().Bool("recover", false, "Recover previous note that failed to save.")
+	editNoteCmd.Flags().Bool("confirm", false, "Review the changes before they are saved.")
+	editNoteCmd.Flags().BoolP("interactive", "i", false, "Pick the note from a list.")
 }
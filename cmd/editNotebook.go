@@ -19,7 +19,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -86,7 +85,7 @@ define the new stack.`,
 			fmt.Println("   • Network connectivity issues")
 			fmt.Println("   • Insufficient permissions")
 			fmt.Println("   • Notebook not found")
-			os.Exit(1)
+			exit(1)
 		}
 		fmt.Println("✅ Notebook updated successfully")
 	},
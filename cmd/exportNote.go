@@ -0,0 +1,142 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var exportNoteCmd = &cobra.Command{
+	Use:   "export \"note title\"",
+	Short: "Export a note or a notebook as HTML.",
+	Long: `
+Export writes the note as a self-contained HTML file. Images are embedded
+in the file, attached files become download links and checkboxes are kept.
+The file is written to stdout unless --out is given.
+
+The format can be html or print. The print format is styled for printing
+or saving as a PDF from a browser.
+
+With --notebook and no note, every note in the notebook is exported to
+the --out folder together with an index.html page linking the notes.
+
+  clinote note export "Meeting notes" --out meeting.html
+  clinote note export "Meeting notes" --format print --out meeting.html
+  clinote note export --notebook Work --out ./work
+` + noteReferenceHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			fmt.Printf("❌ Invalid format flag value: %v\n", err)
+			return
+		}
+		out, err := cmd.Flags().GetString("out")
+		if err != nil {
+			fmt.Printf("❌ Invalid out flag value: %v\n", err)
+			return
+		}
+		notebook, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Printf("❌ Invalid notebook flag value: %v\n", err)
+			return
+		}
+		if len(args) == 0 && notebook != "" {
+			exportNotebook(notebook, out, format)
+			return
+		}
+		args, ok := interactiveArgs(cmd, args)
+		if !ok {
+			return
+		}
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier or notebook required")
+			fmt.Println("💡 Usage: clinote note export \"Note Title\" --out note.html")
+			fmt.Println("   • Or export a notebook: clinote note export --notebook \"Notebook\" --out ./folder")
+			printNoteReferenceHelp()
+			return
+		}
+
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		buf := new(bytes.Buffer)
+		err = clinote.ExportNoteHTML(db, ns, args[0], buf, format)
+		if err != nil {
+			fmt.Printf("❌ Failed to export note: %v\n", err)
+			printNoteSuggestions(db, ns, args[0], err)
+			printExportTroubleshooting()
+			exit(1)
+		}
+		if out == "" {
+			buf.WriteTo(os.Stdout)
+			return
+		}
+		if err = ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+			fmt.Printf("❌ Failed to write %s: %v\n", out, err)
+			exit(1)
+		}
+		fmt.Printf("✅ Note exported to %s\n", out)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(exportNoteCmd)
+	exportNoteCmd.Flags().StringP("format", "f", clinote.ExportFormatHTML, "Export format: html or print.")
+	exportNoteCmd.Flags().StringP("out", "o", "", "File, or folder for a notebook, to write to.")
+	exportNoteCmd.Flags().StringP("notebook", "b", "", "Export all the notes in the notebook.")
+	exportNoteCmd.Flags().BoolP("interactive", "i", false, "Pick the note from a list.")
+}
+
+func exportNotebook(notebook, out, format string) {
+	if out == "" {
+		fmt.Println("❌ Output folder required")
+		fmt.Println("💡 Usage: clinote note export --notebook \"Notebook\" --out ./folder")
+		exit(1)
+	}
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	notes, err := clinote.ExportNotebookHTML(client.Config.Store(), ns, notebook, out, format)
+	if err != nil {
+		fmt.Printf("❌ Failed to export notebook: %v\n", err)
+		printExportTroubleshooting()
+		fmt.Println("   • List notebooks: clinote notebook list")
+		exit(1)
+	}
+	fmt.Printf("✅ Exported %d notes to %s\n", len(notes), out)
+}
+
+func printExportTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • Use --format html or --format print")
+	fmt.Println("   • Check note title spelling (case sensitive)")
+	fmt.Println("   • Check that the output folder is writable")
+}
@@ -1,14 +1,40 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/clinote/evernote"
+	"github.com/TcM1911/clinote/markdown"
 	"github.com/TcM1911/clinote/storage"
+	"github.com/TcM1911/clinote/tui"
+	"github.com/spf13/cobra"
 )
 
+// exit ends the command with the status code. The shell replaces it so
+// a failing command doesn't end the session.
+var exit = os.Exit
+
+// dbLockTimeout is how long a command waits for another clinote process,
+// like serve or the shell, to release the database.
+const dbLockTimeout = 10 * time.Second
+
 func defaultClient() *evernote.Client {
+	if session != nil {
+		return session.evernoteClient()
+	}
 	cfg := &clinote.DefaultConfig{}
-	db, err := storage.Open(cfg.GetConfigFolder())
+	db, err := storage.OpenTimeout(cfg.GetConfigFolder(), dbLockTimeout)
+	if err == storage.ErrDatabaseLocked {
+		printDatabaseLocked()
+		exit(1)
+	}
 	if err != nil {
 		panic("Error when opening the database: " + err.Error())
 	}
@@ -18,17 +44,173 @@ func defaultClient() *evernote.Client {
 }
 
 func newClient(opts clinote.ClientOption) *clinote.Client {
-	cfg := new(clinote.DefaultConfig)
-	db, err := storage.Open(cfg.GetConfigFolder())
-	if err != nil {
-		panic("Error when opening the database: " + err.Error())
-	}
-	cfg.DB = db
-	cfg.UDB = db
-	ec := evernote.NewClient(cfg)
+	ec := defaultClient()
 	ns, err := ec.GetNoteStore()
 	if err != nil {
 		panic("Error when getting notestore: " + err.Error())
 	}
-	return clinote.NewClient(cfg, db, ns, opts)
+	return noteClient(ec, ns, opts)
+}
+
+// noteClient returns a client using the notestore. Edits are confirmed
+// with answers read from stdin().
+func noteClient(ec *evernote.Client, ns clinote.NotestoreClient, opts clinote.ClientOption) *clinote.Client {
+	c := clinote.NewClient(ec.Config, ec.Config.Store(), ns, opts)
+	c.Confirmer = &clinote.TerminalConfirmer{In: stdin(), Out: os.Stdout}
+	return c
+}
+
+// openDB opens the database. The shell's database is returned if the
+// command is run in the shell.
+func openDB() (*storage.Database, error) {
+	if session != nil {
+		return session.db, nil
+	}
+	return storage.OpenTimeout((new(clinote.DefaultConfig)).GetConfigFolder(), dbLockTimeout)
+}
+
+// printDatabaseLocked tells the user that another clinote process holds
+// the database.
+func printDatabaseLocked() {
+	fmt.Println("❌ The database is in use by another clinote process")
+	fmt.Println("💡 Stop clinote serve, rpc, tui or shell in the other terminal and try again")
+}
+
+// stdinReader buffers os.Stdin for commands run outside the shell.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// stdin returns the reader for user input and piped content. In the
+// shell, the shell's reader is returned so input it has buffered isn't
+// lost. All input must be read through it for the same reason.
+func stdin() *bufio.Reader {
+	if session != nil && session.input != nil {
+		return session.input
+	}
+	return stdinReader
+}
+
+// readLine reads a line of user input without the line ending.
+func readLine() (string, error) {
+	line, err := stdin().ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// askForConfirmation prompts the user with the question and returns
+// true if the user answers yes.
+func askForConfirmation(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := readLine()
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmed returns true if the yes flag is set or the user
+// confirms the question.
+func confirmed(cmd *cobra.Command, question string) bool {
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		fmt.Printf("❌ Invalid yes flag value: %v\n", err)
+		return false
+	}
+	return yes || askForConfirmation(question)
+}
+
+// noteReferenceHelp describes the ways a note can be referenced.
+const noteReferenceHelp = `
+The note can be referenced by its title, by "Notebook/Title", by
+guid:<GUID or GUID prefix> or by #<index> from the last note list.`
+
+func printNoteReferenceHelp() {
+	fmt.Println("💡 Reference the note by:")
+	fmt.Println("   • Exact title (case sensitive): \"Note Title\"")
+	fmt.Println("   • Notebook and title: \"Notebook/Note Title\"")
+	fmt.Println("   • GUID or GUID prefix: guid:1a2b3c4d")
+	fmt.Println("   • Index from: clinote note list, for example #1")
+}
+
+// interactiveArgs lets the user pick a note if the interactive flag is
+// set and returns the arguments with a reference to the picked note. False
+// is returned if no note was picked.
+func interactiveArgs(cmd *cobra.Command, args []string) ([]string, bool) {
+	interactive, err := cmd.Flags().GetBool("interactive")
+	if err != nil || !interactive {
+		return args, true
+	}
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return nil, false
+	}
+	db := client.Config.Store()
+	pickerName := clinote.BuiltinPicker
+	if settings, err := db.GetSettings(); err == nil && settings.Picker != "" {
+		pickerName = settings.Picker
+	}
+	picker := clinote.NewPicker(pickerName, stdin(), os.Stdout)
+	n, err := clinote.PickNote(db, ns, picker, "")
+	if err == clinote.ErrNoSelection {
+		return nil, false
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to pick a note: %v\n", err)
+		return nil, false
+	}
+	return []string{clinote.GUIDPrefix + n.GUID}, true
+}
+
+// printNoteSuggestions prints notes with titles similar to the reference
+// if the error is because no note was found.
+func printNoteSuggestions(db clinote.Storager, ns clinote.NotestoreClient, ref string, err error) {
+	if err != clinote.ErrNoNoteFound {
+		return
+	}
+	notes, err := clinote.SuggestNotes(db, ns, ref, 5)
+	if err != nil || len(notes) == 0 {
+		return
+	}
+	fmt.Println("💡 Did you mean:")
+	for _, n := range notes {
+		guid := n.GUID
+		if len(guid) > 8 {
+			guid = guid[:8]
+		}
+		fmt.Printf("   • %s (%s%s)\n", n.Title, clinote.GUIDPrefix, guid)
+	}
+}
+
+// isTerminal returns true if the file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalOptions returns the options used to render notes for stdout.
+// Colors are disabled if NO_COLOR is set or the terminal is dumb.
+func terminalOptions() markdown.TerminalOptions {
+	width, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return markdown.TerminalOptions{
+		Color:      os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb",
+		Hyperlinks: os.Getenv("TERM") != "dumb",
+		Width:      width,
+	}
+}
+
+// terminalHeight returns the height of the terminal. $LINES is used if
+// stty can't report the size. It is 0 if the height is unknown.
+func terminalHeight() int {
+	if _, height, err := tui.TerminalSize(); err == nil {
+		return height
+	}
+	height, _ := strconv.Atoi(os.Getenv("LINES"))
+	return height
 }
@@ -0,0 +1,111 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Open the daily journal note.",
+	Long: `
+Journal opens the journal note for the day in the editor. If the note
+doesn't exist, it's created in the journal notebook, seeded from the
+journal template if one is configured.
+
+The notebook, title pattern and template are configured with:
+  clinote user set journal-notebook "Journal"
+  clinote user set journal-title "{{date}} Journal"
+  clinote user set journal-template "daily"
+
+The title pattern supports {{date}}, {{weekday}}, {{year}}, {{month}}
+and {{day}}.
+
+Use --append to add a timestamped line to the note without opening the
+editor, and --date to open a past entry. The date can be today,
+yesterday, tomorrow, -N for N days ago or YYYY-MM-DD.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dateStr, err := cmd.Flags().GetString("date")
+		if err != nil {
+			fmt.Printf("❌ Invalid date flag value: %v\n", err)
+			return
+		}
+		text, err := cmd.Flags().GetString("append")
+		if err != nil {
+			fmt.Printf("❌ Invalid append flag value: %v\n", err)
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Printf("❌ Invalid raw flag value: %v\n", err)
+			return
+		}
+		date, err := clinote.ParseJournalDate(dateStr)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			exit(1)
+		}
+
+		c := newClient(clinote.DefaultClientOptions)
+		defer c.Store.Close()
+		settings, err := c.Store.GetSettings()
+		if err != nil {
+			fmt.Printf("❌ Cannot load user settings: %v\n", err)
+			exit(1)
+		}
+		j := clinote.JournalFromSettings(settings)
+
+		if cmd.Flags().Changed("append") {
+			if err = clinote.AppendToJournal(c, j, date, text); err != nil {
+				fmt.Printf("❌ Failed to add to the journal: %v\n", err)
+				printJournalTroubleshooting()
+				exit(1)
+			}
+			fmt.Printf("✅ Added to \"%s\"\n", j.Title(date))
+			return
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		if err = clinote.OpenJournal(c, j, date, opts); err != nil {
+			fmt.Printf("❌ Failed to open the journal: %v\n", err)
+			printJournalTroubleshooting()
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(journalCmd)
+	journalCmd.Flags().StringP("append", "a", "", "Add a timestamped line to the journal note.")
+	journalCmd.Flags().StringP("date", "d", "today", "The journal date: today, yesterday, -N or YYYY-MM-DD.")
+	journalCmd.Flags().Bool("raw", false, "Edit the note in raw mode.")
+}
+
+func printJournalTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • Check the journal settings: clinote user set")
+	fmt.Println("   • Check the journal notebook exists: clinote notebook list")
+	fmt.Println("   • Check the journal template exists: clinote template list")
+	fmt.Println("   • Check if $EDITOR environment variable is set")
+}
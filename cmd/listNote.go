@@ -19,15 +19,15 @@ package main
 
 import (
 	"fmt"
-	"log"
 	"os"
+	"time"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
 )
 
 var listNoteCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [@alias]",
 	Short: "List note based on a search filter.",
 	Long: `
 List returns a list of notes based on a search filter.
@@ -39,7 +39,24 @@ Count can be used to restrict the maximum number of notes
 returned.
 
 If no search term is given, a wild card search will be used.
-The notes will be sorted by the modified time.`,
+The notes will be sorted by the modified time.
+
+The search term can be the name of a search alias or a saved
+search prefixed with @, e.g. clinote note list @work-todos.
+See: clinote search --help
+
+The filter flags are compiled into Evernote search grammar and
+combined with the search term:
+
+  clinote note list --tag work --not-tag done --todo unchecked
+  clinote note list --created-after -7 --has-attachment
+  clinote note list --source web.clip --intitle recipe
+
+Dates can be today, yesterday, -N for N days ago or YYYY-MM-DD.
+
+With --offline, the query is evaluated locally against the notes
+from the last note list instead of searching on the server. The
+matching notes become the new last note list.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		findNotes(cmd, args)
 	},
@@ -50,6 +67,17 @@ func init() {
 	listNoteCmd.Flags().IntP("count", "c", 20, "How many notes to show in the result.")
 	listNoteCmd.Flags().StringP("search", "s", "", "Search term.")
 	listNoteCmd.Flags().StringP("notebook", "b", "", "Restrict search to notebook.")
+	listNoteCmd.Flags().StringSlice("tag", nil, "Only notes with the tag.")
+	listNoteCmd.Flags().StringSlice("not-tag", nil, "Only notes without the tag.")
+	listNoteCmd.Flags().String("created-after", "", "Only notes created on or after the date.")
+	listNoteCmd.Flags().String("updated-before", "", "Only notes last updated before the date.")
+	listNoteCmd.Flags().Bool("has-attachment", false, "Only notes with attached files.")
+	listNoteCmd.Flags().String("todo", "", "Only notes with checkboxes: any, checked or unchecked.")
+	listNoteCmd.Flags().Lookup("todo").NoOptDefVal = "any"
+	listNoteCmd.Flags().Bool("reminder", false, "Only notes with a reminder.")
+	listNoteCmd.Flags().String("source", "", "Only notes created by the source, e.g. web.clip or mobile.*.")
+	listNoteCmd.Flags().String("intitle", "", "Only notes with the text in the title.")
+	listNoteCmd.Flags().Bool("offline", false, "Filter the last note list locally instead of searching.")
 }
 
 func findNotes(cmd *cobra.Command, args []string) {
@@ -78,14 +106,42 @@ func findNotes(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	if search != "" {
-		filter.Words = search
+	if search == "" && len(args) == 1 {
+		search = args[0]
+	}
+	builder, err := queryBuilder(cmd)
+	if err != nil {
+		fmt.Printf("❌ Invalid filter: %v\n", err)
+		fmt.Println("💡 Dates can be today, yesterday, -N or YYYY-MM-DD")
+		exit(1)
+	}
+
+	offline, err := cmd.Flags().GetBool("offline")
+	if err != nil {
+		fmt.Printf("❌ Invalid offline flag value: %v\n", err)
+		return
+	}
+	if offline {
+		findCachedNotes(client.Config.Store(), builder, search, searchBook, c)
+		return
 	}
 
 	ns, err := client.GetNoteStore()
 	if err != nil {
 		return
 	}
+	if search != "" {
+		builder.Words, err = clinote.ExpandSearch(client.Config.Store(), ns, search)
+		if err != nil {
+			printExpandSearchError(search, err)
+			exit(1)
+		}
+	}
+	filter.Words, err = builder.Build()
+	if err != nil {
+		printInvalidQueryError(err)
+		exit(1)
+	}
 	if searchBook != "" {
 		book, err := clinote.FindNotebook(client.Config.Store(), ns, searchBook)
 		if err != nil {
@@ -94,21 +150,144 @@ func findNotes(cmd *cobra.Command, args []string) {
 			fmt.Println("   • List notebooks: clinote notebook list")
 			fmt.Println("   • Remove filter: omit --notebook flag")
 			fmt.Println("   • Check spelling and try again")
-			os.Exit(1)
+			exit(1)
 		}
 		filter.NotebookGUID = book.GUID
 	}
 
 	list, err := clinote.FindNotes(ns, filter, 0, c)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Printf("❌ Search failed: %v\n", err)
+		fmt.Println("💡 Troubleshooting:")
+		fmt.Println("   • Check network connection")
+		fmt.Println("   • Verify authentication: clinote user login")
+		exit(1)
+	}
+	writeNoteList(client.Config.Store(), ns, list)
+}
+
+// queryBuilder returns a query builder with the filter flags set.
+func queryBuilder(cmd *cobra.Command) (*clinote.QueryBuilder, error) {
+	flags := cmd.Flags()
+	b := new(clinote.QueryBuilder)
+	var err error
+	if b.Tags, err = flags.GetStringSlice("tag"); err != nil {
+		return nil, err
+	}
+	if b.NotTags, err = flags.GetStringSlice("not-tag"); err != nil {
+		return nil, err
+	}
+	if b.HasAttachment, err = flags.GetBool("has-attachment"); err != nil {
+		return nil, err
+	}
+	if b.Todo, err = flags.GetString("todo"); err != nil {
+		return nil, err
+	}
+	if b.Reminder, err = flags.GetBool("reminder"); err != nil {
+		return nil, err
+	}
+	if b.Source, err = flags.GetString("source"); err != nil {
+		return nil, err
+	}
+	if b.InTitle, err = flags.GetString("intitle"); err != nil {
+		return nil, err
 	}
-	err = client.Config.Store().SaveSearch(list)
+	if b.CreatedAfter, err = dateFlag(cmd, "created-after"); err != nil {
+		return nil, err
+	}
+	if b.UpdatedBefore, err = dateFlag(cmd, "updated-before"); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// dateFlag returns the date given with the flag. The zero time is
+// returned if the flag isn't set.
+func dateFlag(cmd *cobra.Command, name string) (time.Time, error) {
+	s, err := cmd.Flags().GetString(name)
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+	date, err := clinote.ParseJournalDate(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s: %v", name, err)
+	}
+	return date, nil
+}
+
+// findCachedNotes lists the notes from the last note list matching the
+// query without contacting the server.
+func findCachedNotes(db clinote.Storager, b *clinote.QueryBuilder, search, notebook string, count int) {
+	var err error
+	if search != "" {
+		b.Words, err = clinote.ExpandSearch(db, nil, search)
+		if err != nil {
+			printExpandSearchError(search, err)
+			fmt.Println("   • Saved searches need a connection, omit --offline")
+			exit(1)
+		}
+	}
+	query, err := b.Build()
+	if err != nil {
+		printInvalidQueryError(err)
+		exit(1)
+	}
+	list, err := clinote.FindCachedNotes(db, query, notebook)
+	if err != nil {
+		fmt.Printf("❌ Cannot filter the cached notes: %v\n", err)
+		fmt.Println("💡 Run clinote note list without --offline to refresh the cache")
+		exit(1)
+	}
+	if count > 0 && len(list) > count {
+		list = list[:count]
+	}
+	// Save the filtered list so the notes can be referenced by index.
+	err = db.SaveSearch(list)
+	if err != nil {
+		printSaveSearchError(err)
+		exit(1)
+	}
+	cache, err := db.GetNotebookCache()
+	if err != nil {
+		fmt.Printf("❌ Cannot load the cached notebooks: %v\n", err)
+		fmt.Println("💡 Run clinote notebook list to refresh the cache")
+		exit(1)
+	}
+	clinote.WriteNoteListing(os.Stdout, list, cache.Notebooks)
+}
+
+func printExpandSearchError(search string, err error) {
+	fmt.Printf("❌ Cannot expand search '%s': %v\n", search, err)
+	fmt.Println("💡 List the aliases and saved searches:")
+	fmt.Println("   • clinote search alias list")
+	fmt.Println("   • clinote search saved list")
+}
+
+func printSaveSearchError(err error) {
+	fmt.Printf("❌ Failed to save the note list: %v\n", err)
+	fmt.Println("💡 Check:")
+	fmt.Println("   • Disk space available")
+	fmt.Println("   • Write permissions to config directory")
+}
+
+func printInvalidQueryError(err error) {
+	fmt.Printf("❌ Invalid search: %v\n", err)
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • Close all quotes in the search term")
+	fmt.Println("   • Dates in created: and updated: use YYYYMMDD or day-N")
+	fmt.Println("   • todo: takes *, true or false")
+}
+
+// writeNoteList saves the list as the last note list so the notes can be
+// referenced by index, and writes the note listing.
+func writeNoteList(db clinote.Storager, ns clinote.NotestoreClient, list []*clinote.Note) {
+	err := db.SaveSearch(list)
 	if err != nil {
-		log.Fatal(err)
+		printSaveSearchError(err)
+		exit(1)
 	}
 
-	nbs, err := clinote.GetNotebooks(client.Config.Store(), ns, false)
+	nbs, err := clinote.GetNotebooks(db, ns, false)
 	if err != nil {
 		fmt.Printf("❌ Cannot retrieve notebook list: %v\n", err)
 		fmt.Println("💡 Troubleshooting:")
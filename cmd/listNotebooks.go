@@ -29,23 +29,31 @@ var listNotebooksCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List notebooks.",
 	Long: `
-List notebooks returns all active notebooks.`,
+List notebooks returns all active notebooks. With the tree flag, the
+notebooks are grouped by stack and the number of notes in each notebook
+is shown.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		sync, err := cmd.Flags().GetBool("sync")
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
-		listNotebooks(sync)
+		tree, err := cmd.Flags().GetBool("tree")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		listNotebooks(sync, tree)
 	},
 }
 
 func init() {
 	notebookCmd.AddCommand(listNotebooksCmd)
 	listNotebooksCmd.Flags().BoolP("sync", "s", false, "Force a resync of notebooks from the server.")
+	listNotebooksCmd.Flags().BoolP("tree", "t", false, "Group the notebooks by stack and show note counts.")
 }
 
-func listNotebooks(sync bool) {
+func listNotebooks(sync, tree bool) {
 	client := defaultClient()
 	defer client.Close()
 	ns, err := client.GetNoteStore()
@@ -59,7 +67,16 @@ func listNotebooks(sync bool) {
 		fmt.Println("   • Check internet connection")
 		fmt.Println("   • Verify authentication: clinote user login")
 		fmt.Println("   • Check account status")
-		os.Exit(1)
+		exit(1)
+	}
+	if !tree {
+		clinote.WriteNotebookListing(os.Stdout, bs)
+		return
+	}
+	counts, err := clinote.GetNoteCounts(ns)
+	if err != nil {
+		fmt.Printf("❌ Cannot retrieve note counts: %v\n", err)
+		exit(1)
 	}
-	clinote.WriteNotebookListing(os.Stdout, bs)
+	clinote.WriteNotebookTree(os.Stdout, bs, counts)
 }
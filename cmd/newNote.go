@@ -19,6 +19,8 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/TcM1911/clinote"
@@ -35,7 +37,26 @@ note.
 If no notebook is given, the default notebook will be used.
 
 The new note can be open in the $EDITOR by using the edit
-flag.`,
+flag.
+
+With the template flag, the template is rendered into the note
+before it's opened in the editor.
+
+The content of the note can be read from a file with the file flag
+or from stdin by giving "-" as argument. If no title is given, the
+first heading in the content is used, or else the file name.
+
+Several notes can be created at once with the from-files flag. Each
+file given as argument is saved as a note.
+
+The content is markdown unless the raw flag is set, in which case it's
+ENML or HTML. HTML elements and attributes not allowed by Evernote are
+removed.
+
+Examples:
+  clinote note new --title "Report" --file report.md
+  cat report.md | clinote note new --title "Report" -
+  clinote note new --from-files --notebook "Reports" --tag ci *.md`,
 	Run: func(cmd *cobra.Command, args []string) {
 		title, err := cmd.Flags().GetString("title")
 		if err != nil {
@@ -43,19 +64,34 @@ flag.`,
 			fmt.Println("💡 Tip: Use --title \"Your Note Title\" or -t \"Your Note Title\"")
 			return
 		}
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			fmt.Printf("❌ Failed to parse file name: %v\n", err)
+			return
+		}
+		fromFiles, err := cmd.Flags().GetBool("from-files")
+		if err != nil {
+			fmt.Printf("❌ Invalid from-files flag value: %v\n", err)
+			return
+		}
+		tags, err := cmd.Flags().GetStringSlice("tag")
+		if err != nil {
+			fmt.Printf("❌ Failed to parse tags: %v\n", err)
+			fmt.Println("💡 Tip: Use --tag \"tag name\" once for each tag")
+			return
+		}
+		tmpl, err := cmd.Flags().GetString("template")
+		if err != nil {
+			fmt.Printf("❌ Failed to parse template name: %v\n", err)
+			fmt.Println("💡 List templates: clinote template list")
+			return
+		}
 		edit, err := cmd.Flags().GetBool("edit")
 		if err != nil {
 			fmt.Printf("❌ Invalid edit flag value: %v\n", err)
 			fmt.Println("💡 Tip: Use --edit or -e (no value needed)")
 			return
 		}
-		if title == "" && !edit {
-			fmt.Println("❌ Note title is required when not using edit mode")
-			fmt.Println("💡 Options:")
-			fmt.Println("   • Add a title: clinote note new --title \"My Note\"")
-			fmt.Println("   • Use edit mode: clinote note new --edit")
-			return
-		}
 		notebook, err := cmd.Flags().GetString("notebook")
 		if err != nil {
 			fmt.Printf("❌ Failed to parse notebook name: %v\n", err)
@@ -69,7 +105,26 @@ flag.`,
 			fmt.Println("💡 Tip: Use --raw (no value needed) to edit in XML format")
 			return
 		}
-		createNote(title, notebook, edit, raw)
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		if fromFiles {
+			createNotesFromFiles(args, notebook, tags, opts)
+			return
+		}
+		if file != "" || (len(args) == 1 && args[0] == "-") {
+			createNoteFromContent(title, file, notebook, tags, opts)
+			return
+		}
+		if title == "" && !edit && tmpl == "" {
+			fmt.Println("❌ Note title is required when not using edit mode")
+			fmt.Println("💡 Options:")
+			fmt.Println("   • Add a title: clinote note new --title \"My Note\"")
+			fmt.Println("   • Use edit mode: clinote note new --edit")
+			return
+		}
+		createNote(title, notebook, tmpl, tags, edit, raw)
 	},
 }
 
@@ -79,34 +134,41 @@ func init() {
 	newNoteCmd.Flags().StringP("notebook", "b", "", "The notebook to save note to, if not set the default notebook will be used.")
 	newNoteCmd.Flags().BoolP("edit", "e", false, "Open note in the editor.")
 	newNoteCmd.Flags().Bool("raw", false, "Edit the content in raw mode.")
+	newNoteCmd.Flags().StringP("file", "f", "", "Read the note content from the file.")
+	newNoteCmd.Flags().Bool("from-files", false, "Create a note from each file given as argument.")
+	newNoteCmd.Flags().StringSlice("tag", nil, "Tag to add to the note, can be repeated.")
+	newNoteCmd.Flags().String("template", "", "Create the note from the template and open it in the editor.")
 }
 
-func createNote(title, notebook string, edit, raw bool) {
+func createNote(title, notebook, tmpl string, tags []string, edit, raw bool) {
 	c := newClient(clinote.DefaultClientOptions)
 	defer c.Store.Close()
 
-	note := new(clinote.Note)
+	note := &clinote.Note{Tags: tags}
 	if title == "" {
-		note.Title = "Untitled note"
+		note.Title = clinote.DefaultNoteTitle
 	} else {
 		note.Title = title
 	}
-	if notebook != "" {
-		nb, err := clinote.FindNotebook(c.Store, c.NoteStore, notebook)
-		if err != nil {
-			fmt.Printf("❌ Notebook '%s' not found: %v\n", notebook, err)
-			fmt.Println("💡 Available options:")
-			fmt.Println("   • List notebooks: clinote notebook list")
-			fmt.Println("   • Create new notebook: clinote notebook new \"Notebook Name\"")
-			fmt.Println("   • Use default notebook: omit --notebook flag")
-			return
-		}
-		note.Notebook = nb
+	nb, ok := findNewNoteNotebook(c, notebook)
+	if !ok {
+		return
 	}
+	note.Notebook = nb
 	opts := clinote.DefaultNoteOption
 	if raw {
 		opts |= clinote.RawNote
 	}
+	if tmpl != "" {
+		if err := clinote.CreateNoteFromTemplate(c, note, tmpl, opts); err != nil {
+			fmt.Printf("❌ Failed to create note from template: %v\n", err)
+			fmt.Println("💡 Troubleshooting:")
+			fmt.Println("   • List templates: clinote template list")
+			fmt.Println("   • Check if $EDITOR environment variable is set")
+			fmt.Println("   • Check network connection for Evernote sync")
+		}
+		return
+	}
 	if edit {
 		if err := clinote.CreateAndEditNewNote(c, note, opts); err != nil {
 			fmt.Printf("❌ Failed to create and edit note: %v\n", err)
@@ -130,3 +192,109 @@ func createNote(title, notebook string, edit, raw bool) {
 		fmt.Println("   • Check account quota and permissions")
 	}
 }
+
+// createNoteFromContent creates a note with the content from the file or
+// stdin if file is an empty string.
+func createNoteFromContent(title, file, notebook string, tags []string, opts clinote.NoteOption) {
+	var content []byte
+	var err error
+	if file != "" {
+		content, err = ioutil.ReadFile(file)
+	} else {
+		content, err = ioutil.ReadAll(stdin())
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to read the content: %v\n", err)
+		exit(1)
+	}
+	c := newClient(clinote.DefaultClientOptions)
+	defer c.Store.Close()
+	nb, ok := findNewNoteNotebook(c, notebook)
+	if !ok {
+		exit(1)
+	}
+	note := &clinote.Note{Title: title, Notebook: nb, Tags: tags}
+	if note.Title == "" && file != "" {
+		note.Title = titleFromContentOrFile(string(content), file, opts)
+	}
+	if err = clinote.CreateNoteFromContent(c.NoteStore, note, string(content), opts); err != nil {
+		fmt.Printf("❌ Failed to save note: %v\n", err)
+		printNewNoteTroubleshooting()
+		exit(1)
+	}
+	fmt.Printf("✅ Created \"%s\"\n", note.Title)
+}
+
+// createNotesFromFiles creates a note for each file. The title is taken
+// from the first heading in the file or the file name.
+func createNotesFromFiles(files []string, notebook string, tags []string, opts clinote.NoteOption) {
+	if len(files) == 0 {
+		fmt.Println("❌ No files given")
+		fmt.Println("💡 Usage: clinote note new --from-files *.md")
+		return
+	}
+	c := newClient(clinote.DefaultClientOptions)
+	defer c.Store.Close()
+	nb, ok := findNewNoteNotebook(c, notebook)
+	if !ok {
+		exit(1)
+	}
+	failed := 0
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", file, err)
+			failed++
+			continue
+		}
+		note := &clinote.Note{
+			Title:    titleFromContentOrFile(string(content), file, opts),
+			Notebook: nb,
+			Tags:     tags,
+		}
+		if err = clinote.CreateNoteFromContent(c.NoteStore, note, string(content), opts); err != nil {
+			fmt.Printf("❌ %s: %v\n", file, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ %s: created \"%s\"\n", file, note.Title)
+	}
+	if failed > 0 {
+		fmt.Printf("❌ %d of %d files failed\n", failed, len(files))
+		printNewNoteTroubleshooting()
+		exit(1)
+	}
+}
+
+func titleFromContentOrFile(content, file string, opts clinote.NoteOption) string {
+	if title := clinote.TitleFromContent(content, opts&clinote.RawNote != 0); title != "" {
+		return title
+	}
+	name := filepath.Base(file)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// findNewNoteNotebook returns the notebook with the name. If the name is
+// an empty string, nil is returned so the default notebook is used.
+func findNewNoteNotebook(c *clinote.Client, notebook string) (*clinote.Notebook, bool) {
+	if notebook == "" {
+		return nil, true
+	}
+	nb, err := clinote.FindNotebook(c.Store, c.NoteStore, notebook)
+	if err != nil {
+		fmt.Printf("❌ Notebook '%s' not found: %v\n", notebook, err)
+		fmt.Println("💡 Available options:")
+		fmt.Println("   • List notebooks: clinote notebook list")
+		fmt.Println("   • Create new notebook: clinote notebook new \"Notebook Name\"")
+		fmt.Println("   • Use default notebook: omit --notebook flag")
+		return nil, false
+	}
+	return nb, true
+}
+
+func printNewNoteTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • Check network connection")
+	fmt.Println("   • Verify authentication: clinote user login")
+	fmt.Println("   • Check that raw content is valid ENML")
+}
@@ -19,7 +19,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -46,7 +45,7 @@ func createNotebook(cmd *cobra.Command, args []string) {
 		fmt.Println("❌ Notebook name required")
 		fmt.Println("💡 Usage: clinote notebook new \"Notebook Name\"")
 		fmt.Println("   • Use quotes if name contains spaces")
-		os.Exit(1)
+		exit(1)
 	}
 	nb := &clinote.Notebook{}
 	nb.Name = args[0]
@@ -55,7 +54,7 @@ func createNotebook(cmd *cobra.Command, args []string) {
 	if err != nil {
 		fmt.Printf("❌ Invalid stack parameter: %v\n", err)
 		fmt.Println("💡 Tip: Use --stack \"Stack Name\" to organize notebooks")
-		os.Exit(1)
+		exit(1)
 	}
 	if stack != "" {
 		nb.Stack = stack
@@ -65,7 +64,7 @@ func createNotebook(cmd *cobra.Command, args []string) {
 	if err != nil {
 		fmt.Printf("❌ Invalid default flag: %v\n", err)
 		fmt.Println("💡 Tip: Use --default (no value needed) to make this the default notebook")
-		os.Exit(1)
+		exit(1)
 	}
 
 	client := defaultClient()
@@ -83,6 +82,6 @@ func createNotebook(cmd *cobra.Command, args []string) {
 		fmt.Println("   • Invalid characters in name")
 		fmt.Println("   • Network connectivity issues")
 		fmt.Println("   • Account quota exceeded")
-		os.Exit(1)
+		exit(1)
 	}
 }
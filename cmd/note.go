@@ -18,6 +18,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 
@@ -28,8 +29,42 @@ import (
 var noteCmd = &cobra.Command{
 	Use:   "note \"note title\"",
 	Short: "View, edit and create a note.",
-	Long:  `Displays the content of a note.`,
+	Long: `Displays the content of a note.
+
+When the output is a terminal, the note is rendered with styles and
+long notes are shown in $PAGER. Use --plain to print the markdown.
+Colors are disabled if NO_COLOR is set.
+` + noteReferenceHelp,
 	Run: func(cmd *cobra.Command, args []string) {
+		args, ok := interactiveArgs(cmd, args)
+		if !ok {
+			return
+		}
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		getNote(cmd, args)
+	},
+}
+
+var showNoteCmd = &cobra.Command{
+	Use:   "show \"note title\"",
+	Short: "Display a note.",
+	Long: `
+Displays the content of a note. A previous version of the note can be
+displayed by using the version flag with the version's USN. The versions
+of the note can be listed with the history command.
+
+When the output is a terminal, the note is rendered with styles and
+long notes are shown in $PAGER. Use --plain to print the markdown.
+Colors are disabled if NO_COLOR is set.
+` + noteReferenceHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		args, ok := interactiveArgs(cmd, args)
+		if !ok {
+			return
+		}
 		if len(args) != 1 {
 			cmd.Usage()
 			return
@@ -41,6 +76,14 @@ var noteCmd = &cobra.Command{
 func init() {
 	RootCmd.AddCommand(noteCmd)
 	noteCmd.Flags().Bool("raw", false, "Display raw content instead of markdown encoded.")
+	noteCmd.Flags().Int("version", 0, "Display the version of the note with the USN.")
+	noteCmd.AddCommand(showNoteCmd)
+	showNoteCmd.Flags().Bool("raw", false, "Display raw content instead of markdown encoded.")
+	showNoteCmd.Flags().Int("version", 0, "Display the version of the note with the USN.")
+	noteCmd.Flags().BoolP("interactive", "i", false, "Pick the note from a list.")
+	showNoteCmd.Flags().BoolP("interactive", "i", false, "Pick the note from a list.")
+	noteCmd.Flags().Bool("plain", false, "Display the markdown without rendering it for the terminal.")
+	showNoteCmd.Flags().Bool("plain", false, "Display the markdown without rendering it for the terminal.")
 }
 
 func getNote(cmd *cobra.Command, args []string) {
@@ -55,21 +98,48 @@ func getNote(cmd *cobra.Command, args []string) {
 		fmt.Println("💡 Tip: Use --raw (no value needed) to display XML content")
 		return
 	}
+	version, err := cmd.Flags().GetInt("version")
+	if err != nil {
+		fmt.Printf("❌ Invalid version value: %v\n", err)
+		fmt.Println("💡 Tip: Use the USN from: clinote note history \"title\"")
+		return
+	}
 	client := defaultClient()
 	defer client.Close()
 	ns, err := client.GetNoteStore()
 	if err != nil {
 		return
 	}
-	n, err := clinote.GetNoteWithContent(client.Config.Store(), ns, name)
+	var n *clinote.Note
+	if version > 0 {
+		n, err = clinote.GetNoteVersionWithContent(client.Config.Store(), ns, name, version)
+	} else {
+		n, err = clinote.GetNoteWithContent(client.Config.Store(), ns, name)
+	}
 	if err != nil {
 		fmt.Printf("❌ Failed to retrieve note: %v\n", err)
+		printNoteSuggestions(client.Config.Store(), ns, name, err)
 		fmt.Println("💡 Troubleshooting:")
 		fmt.Println("   • Check note title spelling (case sensitive)")
 		fmt.Println("   • Search for notes: clinote note list --search \"partial title\"")
 		fmt.Println("   • List all notes: clinote note list")
-		fmt.Println("   • Use note index from list instead of title")
-		os.Exit(1)
+		printNoteReferenceHelp()
+		exit(1)
+	}
+	plain, err := cmd.Flags().GetBool("plain")
+	if err != nil {
+		fmt.Printf("❌ Invalid plain flag value: %v\n", err)
+		return
+	}
+	if raw || plain || !isTerminal(os.Stdout) {
+		clinote.WriteNote(os.Stdout, n, opts)
+		return
+	}
+	buf := new(bytes.Buffer)
+	clinote.WriteRenderedNote(buf, n, terminalOptions())
+	if err = clinote.PageText(os.Stdout, buf.String(), terminalHeight()); err != nil {
+		fmt.Printf("❌ Failed to run the pager: %v\n", err)
+		fmt.Println("💡 Set $PAGER to another pager or use --plain")
+		exit(1)
 	}
-	clinote.WriteNote(os.Stdout, n, opts)
 }
@@ -0,0 +1,165 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var historyNoteCmd = &cobra.Command{
+	Use:   "history \"note title\"",
+	Short: "List the versions of a note.",
+	Long: `
+History lists the versions of the note saved by Evernote. Each version
+is identified by its update sequence number (USN). The USN can be used
+with the show, diff and restore commands.
+
+Note versions are only available for premium accounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote note history \"Note Title\"")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		vs, err := clinote.GetNoteVersions(client.Config.Store(), ns, args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to retrieve note versions: %v\n", err)
+			printVersionTroubleshooting()
+			exit(1)
+		}
+		if len(vs) == 0 {
+			fmt.Println("No previous versions of the note have been saved")
+			return
+		}
+		clinote.WriteNoteVersionListing(os.Stdout, vs)
+	},
+}
+
+var diffNoteCmd = &cobra.Command{
+	Use:   "diff \"note title\"",
+	Short: "Show the changes made since a version of a note.",
+	Long: `
+Diff shows a unified diff between the version of the note defined by the
+version flag and the current note.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote note diff \"Note Title\" --version USN")
+			return
+		}
+		version, ok := parseVersionFlag(cmd)
+		if !ok {
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Printf("❌ Invalid raw flag value: %v\n", err)
+			return
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.DiffNoteVersion(os.Stdout, client.Config.Store(), ns, args[0], version, opts)
+		if err != nil {
+			fmt.Printf("❌ Failed to compare note versions: %v\n", err)
+			printVersionTroubleshooting()
+			exit(1)
+		}
+	},
+}
+
+var restoreNoteCmd = &cobra.Command{
+	Use:   "restore \"note title\"",
+	Short: "Restore a previous version of a note.",
+	Long: `
+Restore replaces the title and the content of the note with the version
+defined by the version flag. The current content is saved as a new version
+by Evernote, so the restore can be undone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote note restore \"Note Title\" --version USN")
+			return
+		}
+		version, ok := parseVersionFlag(cmd)
+		if !ok {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.RestoreNoteVersion(client.Config.Store(), ns, args[0], version)
+		if err != nil {
+			fmt.Printf("❌ Failed to restore note version: %v\n", err)
+			printVersionTroubleshooting()
+			exit(1)
+		}
+		fmt.Printf("✅ Note restored to version %d\n", version)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(historyNoteCmd)
+	noteCmd.AddCommand(diffNoteCmd)
+	noteCmd.AddCommand(restoreNoteCmd)
+	diffNoteCmd.Flags().Int("version", 0, "The USN of the version to compare with.")
+	diffNoteCmd.Flags().Bool("raw", false, "Compare raw content instead of markdown encoded.")
+	restoreNoteCmd.Flags().Int("version", 0, "The USN of the version to restore.")
+}
+
+func parseVersionFlag(cmd *cobra.Command) (int, bool) {
+	version, err := cmd.Flags().GetInt("version")
+	if err != nil {
+		fmt.Printf("❌ Invalid version value: %v\n", err)
+		fmt.Println("💡 Tip: Use --version USN")
+		return 0, false
+	}
+	if version <= 0 {
+		fmt.Println("❌ Note version required")
+		fmt.Println("💡 List the versions: clinote note history \"Note Title\"")
+		return 0, false
+	}
+	return version, true
+}
+
+func printVersionTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • Note versions require an Evernote premium account")
+	fmt.Println("   • Check the USN: clinote note history \"title\"")
+	fmt.Println("   • Check note title spelling (case sensitive)")
+}
@@ -0,0 +1,124 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var noteLinksCmd = &cobra.Command{
+	Use:   "links \"note title\"",
+	Short: "List the notes linked to by a note.",
+	Long: `
+Links lists the notes the note links to. The index of a note in the list
+can be used to open it, e.g. clinote note 1.
+
+In the editor, links to notes are shown as [[Note Title]]. New links are
+added by writing [[Note Title]] or [[Note Title|link text]], they are
+converted into note links when the note is saved.
+` + noteReferenceHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote note links \"Note Title\"")
+			printNoteReferenceHelp()
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		notes, err := clinote.NoteLinks(db, ns, args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to get the note's links: %v\n", err)
+			printNoteSuggestions(db, ns, args[0], err)
+			exit(1)
+		}
+		writeLinkedNotes(db, notes)
+	},
+}
+
+var noteBacklinksCmd = &cobra.Command{
+	Use:   "backlinks \"note title\"",
+	Short: "List the notes linking to a note.",
+	Long: `
+Backlinks lists the notes that link to the note. The links are read from
+a local index which is updated when notes are viewed or edited. Use
+--rebuild to index the links in all notes first.
+` + noteReferenceHelp,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote note backlinks \"Note Title\"")
+			printNoteReferenceHelp()
+			return
+		}
+		rebuild, err := cmd.Flags().GetBool("rebuild")
+		if err != nil {
+			fmt.Printf("❌ Invalid rebuild flag value: %v\n", err)
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		if rebuild {
+			count, err := clinote.RebuildLinkIndex(db, ns)
+			if err != nil {
+				fmt.Printf("❌ Failed to rebuild the link index: %v\n", err)
+				exit(1)
+			}
+			fmt.Printf("✅ Indexed %d notes\n", count)
+		}
+		notes, err := clinote.Backlinks(db, ns, args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to get the note's backlinks: %v\n", err)
+			printNoteSuggestions(db, ns, args[0], err)
+			exit(1)
+		}
+		if len(notes) == 0 && !rebuild {
+			fmt.Println("💡 No backlinks found, index all notes with: clinote note backlinks --rebuild")
+		}
+		writeLinkedNotes(db, notes)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(noteLinksCmd)
+	noteCmd.AddCommand(noteBacklinksCmd)
+	noteBacklinksCmd.Flags().Bool("rebuild", false, "Index the links in all notes first.")
+}
+
+// writeLinkedNotes writes the listing and saves it as the last note list
+// so the notes can be referenced by index.
+func writeLinkedNotes(db clinote.Storager, notes []*clinote.Note) {
+	if err := db.SaveSearch(notes); err != nil {
+		fmt.Printf("⚠️  Failed to save the note list: %v\n", err)
+	}
+	clinote.WriteLinkListing(os.Stdout, notes)
+}
@@ -23,8 +23,8 @@ import (
 
 var notebookCmd = &cobra.Command{
 	Use:   "notebook",
-	Short: "View, create and edit notebooks.",
-	Long:  `View, create and edit notebooks.`,
+	Short: "View, create, edit and delete notebooks.",
+	Long:  `View, create, edit and delete notebooks.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Usage()
 	},
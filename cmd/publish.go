@@ -0,0 +1,93 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish notebooks as a static web site.",
+	Long: `
+Publish writes the notes in the notebooks as a static web site that can be
+served by any web server. Every note gets a page and the pages can be
+navigated by stack, notebook and tag. Links between published notes point
+to the note's page, attached files are copied to the site and the start
+page has a search.
+
+The notebooks are selected with --notebook and --stack, both can be
+repeated. Existing files in the output folder are overwritten.
+
+  clinote publish --notebook Runbooks --out ./site
+  clinote publish --stack Ops --title "Ops runbooks" --out ./site`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := new(clinote.PublishOptions)
+		var err error
+		if opts.Notebooks, err = cmd.Flags().GetStringSlice("notebook"); err != nil {
+			fmt.Printf("❌ Invalid notebook flag value: %v\n", err)
+			return
+		}
+		if opts.Stacks, err = cmd.Flags().GetStringSlice("stack"); err != nil {
+			fmt.Printf("❌ Invalid stack flag value: %v\n", err)
+			return
+		}
+		if opts.Dir, err = cmd.Flags().GetString("out"); err != nil {
+			fmt.Printf("❌ Invalid out flag value: %v\n", err)
+			return
+		}
+		if opts.Title, err = cmd.Flags().GetString("title"); err != nil {
+			fmt.Printf("❌ Invalid title flag value: %v\n", err)
+			return
+		}
+		if opts.Dir == "" {
+			fmt.Println("❌ Output folder required")
+			fmt.Println("💡 Usage: clinote publish --notebook \"Notebook\" --out ./site")
+			exit(1)
+		}
+
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		result, err := clinote.PublishSite(client.Config.Store(), ns, opts)
+		if err != nil {
+			fmt.Printf("❌ Failed to publish: %v\n", err)
+			fmt.Println("💡 Troubleshooting:")
+			fmt.Println("   • Select notebooks with --notebook or --stack")
+			fmt.Println("   • List notebooks and stacks: clinote notebook list")
+			fmt.Println("   • Check that the output folder is writable")
+			exit(1)
+		}
+		fmt.Printf("✅ Published %d notes from %d notebooks to %s\n", result.Notes, result.Notebooks, opts.Dir)
+		fmt.Printf("   • %d tag pages, %d attachments\n", result.Tags, result.Attachments)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(publishCmd)
+	publishCmd.Flags().StringSliceP("notebook", "b", nil, "Notebook to publish.")
+	publishCmd.Flags().StringSlice("stack", nil, "Publish the notebooks in the stack.")
+	publishCmd.Flags().StringP("out", "o", "", "Folder to write the site to.")
+	publishCmd.Flags().String("title", clinote.DefaultSiteTitle, "Title of the site.")
+}
@@ -0,0 +1,86 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var recoveryCmd = &cobra.Command{
+	Use:   "recovery",
+	Short: "List notes changed by find and replace.",
+	Long: `
+Recovery lists the recovery journal. The journal holds the notes as they
+were before they were changed by find and replace, the most recent change
+first. A note can be restored with: clinote note recovery restore <index>`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := defaultClient()
+		defer client.Close()
+		entries, err := clinote.GetRecoveryJournal(client.Config.Store())
+		if err != nil {
+			fmt.Printf("❌ Cannot load the recovery journal: %v\n", err)
+			fmt.Println("💡 Check that the config folder is readable")
+			exit(1)
+		}
+		clinote.WriteRecoveryJournal(os.Stdout, entries)
+	},
+}
+
+var recoveryRestoreCmd = &cobra.Command{
+	Use:   "restore index",
+	Short: "Restore a note from the recovery journal.",
+	Long: `
+Restore replaces the content of the note with the content saved in the
+recovery journal. The current content is added to the journal first.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Journal index required")
+			fmt.Println("💡 List the journal: clinote note recovery")
+			return
+		}
+		index, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Printf("❌ %s is not a number\n", args[0])
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		// Index is a 1 based index for the user.
+		entry, err := clinote.RestoreRecoveryEntry(client.Config.Store(), ns, index-1)
+		if err != nil {
+			fmt.Printf("❌ Failed to restore note: %v\n", err)
+			fmt.Println("💡 List the journal: clinote note recovery")
+			exit(1)
+		}
+		fmt.Printf("✅ Restored \"%s\"\n", entry.Note.Title)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(recoveryCmd)
+	recoveryCmd.AddCommand(recoveryRestoreCmd)
+}
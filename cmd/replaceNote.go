@@ -0,0 +1,143 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var replaceNoteCmd = &cobra.Command{
+	Use:   "replace --search \"query\" --pattern REGEX --with TEXT",
+	Short: "Find and replace text across notes.",
+	Long: `
+Replace finds the notes matching the search and replaces the matches of
+the pattern with the text. Only the text of the notes is changed, never
+the markup or encrypted content. The replacement text can reference
+submatches of the pattern, e.g. $1. If no pattern is given, the search
+text is replaced.
+
+A diff is shown for each note before the change is saved. The original
+note is added to the recovery journal and can be restored with:
+  clinote note recovery restore <index>`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sel := new(clinote.BulkSelection)
+		var err error
+		if sel.Search, err = cmd.Flags().GetString("search"); err != nil {
+			fmt.Printf("❌ Invalid search flag value: %v\n", err)
+			return
+		}
+		if sel.Notebook, err = cmd.Flags().GetString("notebook"); err != nil {
+			fmt.Printf("❌ Invalid notebook flag value: %v\n", err)
+			return
+		}
+		pattern, err := cmd.Flags().GetString("pattern")
+		if err != nil {
+			fmt.Printf("❌ Invalid pattern flag value: %v\n", err)
+			return
+		}
+		with, err := cmd.Flags().GetString("with")
+		if err != nil {
+			fmt.Printf("❌ Invalid with flag value: %v\n", err)
+			return
+		}
+		ignoreCase, err := cmd.Flags().GetBool("ignore-case")
+		if err != nil {
+			fmt.Printf("❌ Invalid ignore-case flag value: %v\n", err)
+			return
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			fmt.Printf("❌ Invalid dry-run flag value: %v\n", err)
+			return
+		}
+		if sel.Search == "" {
+			fmt.Println("❌ A search is required")
+			fmt.Println("💡 Usage: clinote note replace --search \"old\" --with \"new\"")
+			return
+		}
+		if pattern == "" {
+			pattern = regexp.QuoteMeta(sel.Search)
+		}
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			fmt.Printf("❌ Invalid pattern: %v\n", err)
+			exit(1)
+		}
+
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		db := client.Config.Store()
+		rs, err := clinote.FindReplacements(db, ns, sel, re, with)
+		if err != nil {
+			fmt.Printf("❌ Failed to find notes: %v\n", err)
+			exit(1)
+		}
+		if len(rs) == 0 {
+			fmt.Println("No matches found")
+			return
+		}
+
+		saved, failed := 0, 0
+		for _, r := range rs {
+			fmt.Printf("\n%s (%d matches)\n", r.Original.Title, r.Count)
+			if err = clinote.WriteReplacementDiff(os.Stdout, r, true); err != nil {
+				fmt.Printf("❌ Failed to create diff: %v\n", err)
+			}
+			if dryRun || !confirmed(cmd, fmt.Sprintf("Save changes to \"%s\"?", r.Original.Title)) {
+				continue
+			}
+			if err = clinote.SaveReplacement(db, ns, r); err != nil {
+				failed++
+				fmt.Printf("❌ Failed to save \"%s\": %v\n", r.Original.Title, err)
+				continue
+			}
+			saved++
+		}
+		if dryRun {
+			fmt.Printf("\n%d notes would be changed\n", len(rs))
+			return
+		}
+		fmt.Printf("\n%d notes saved, %d failed\n", saved, failed)
+		if failed > 0 {
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(replaceNoteCmd)
+	replaceNoteCmd.Flags().StringP("search", "s", "", "Search query for the notes to change.")
+	replaceNoteCmd.Flags().StringP("notebook", "b", "", "Restrict the search to the notebook.")
+	replaceNoteCmd.Flags().StringP("pattern", "p", "", "Regular expression to replace, defaults to the search text.")
+	replaceNoteCmd.Flags().StringP("with", "w", "", "The replacement text.")
+	replaceNoteCmd.Flags().Bool("ignore-case", false, "Match the pattern case insensitively.")
+	replaceNoteCmd.Flags().Bool("dry-run", false, "Only show the changes.")
+	replaceNoteCmd.Flags().BoolP("yes", "y", false, "Save the changes without asking for confirmation.")
+}
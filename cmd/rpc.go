@@ -0,0 +1,75 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote/rpc"
+	"github.com/spf13/cobra"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Serve the notes with JSON-RPC over stdin and stdout.",
+	Long: `
+Rpc lets editors, assistants and other programs use the notes through
+JSON-RPC 2.0. Requests are read from stdin and responses are written to
+stdout, one JSON value per line. Errors that stop the server are written
+to stderr.
+
+The database stays locked while the server handles requests and until it
+has been idle for a few seconds. Other clinote commands wait up to 10
+seconds for it and then fail.
+
+Methods:
+  rpc.discover     Methods and the JSON schemas of their params and results.
+  notes.find       Search for notes, {"search", "notebook", "offset", "count"}.
+                   With "stream": true every page is sent as a notes.page
+                   notification before the result.
+  notes.get        A note with content, {"ref", "format": "markdown|enml|both"}.
+  notes.create     Create a note, {"title", "notebook", "tags", "markdown" or "enml"}.
+  notes.save       Save a note's content, {"ref", "title", "notebook", "markdown" or "enml"}.
+  notebooks.list   The notebooks, {"sync"}.
+
+MCP:
+  The server is also a Model Context Protocol server. Clients start with
+  initialize and list the tools with tools/list. The tools notes_find,
+  notes_get, notes_create, notes_save and notebooks_list take the same
+  params as the methods and are called with tools/call.
+
+  echo '{"jsonrpc": "2.0", "id": 1, "method": "notebooks.list"}' | clinote rpc`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to connect to the notestore: %v\n", err)
+			exit(1)
+		}
+		if err = rpc.NewServer(client.Config.Store(), ns).Serve(stdin(), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to serve: %v\n", err)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(rpcCmd)
+}
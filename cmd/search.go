@@ -0,0 +1,230 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Manage saved searches and search aliases.",
+	Long: `
+Search manages the saved searches in the account and local search
+aliases. Both can be used in place of a search query by prefixing the
+name with @, e.g.:
+  clinote note list @work-todos
+  clinote note bulk tag --search @work-todos --tag review
+
+Local aliases take precedence over saved searches with the same name.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var savedSearchCmd = &cobra.Command{
+	Use:   "saved",
+	Short: "Manage the saved searches in the account.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var savedSearchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the saved searches.",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		searches, err := clinote.GetSavedSearches(ns)
+		if err != nil {
+			fmt.Printf("❌ Failed to get the saved searches: %v\n", err)
+			exit(1)
+		}
+		clinote.WriteSavedSearchListing(os.Stdout, searches)
+	},
+}
+
+var savedSearchNewCmd = &cobra.Command{
+	Use:   "new \"name\" \"query\"",
+	Short: "Create a saved search.",
+	Long: `
+New saves the query as a saved search in the account. The query uses the
+Evernote search grammar, e.g. "notebook:Work tag:todo".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("❌ Name and query required")
+			fmt.Println("💡 Usage: clinote search saved new \"todos\" \"tag:todo\"")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if err = clinote.NewSavedSearch(ns, args[0], args[1]); err != nil {
+			fmt.Printf("❌ Failed to create saved search: %v\n", err)
+			exit(1)
+		}
+		fmt.Println("✅ Saved search created")
+	},
+}
+
+var savedSearchRunCmd = &cobra.Command{
+	Use:   "run \"name\"",
+	Short: "List the notes matching a saved search.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Saved search name required")
+			fmt.Println("💡 List the saved searches: clinote search saved list")
+			return
+		}
+		count, err := cmd.Flags().GetInt("count")
+		if err != nil {
+			fmt.Printf("⚠️  Invalid count value, using default (20): %v\n", err)
+			count = 20
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		list, err := clinote.RunSavedSearch(ns, args[0], count)
+		if err != nil {
+			fmt.Printf("❌ Failed to run saved search: %v\n", err)
+			fmt.Println("💡 List the saved searches: clinote search saved list")
+			exit(1)
+		}
+		writeNoteList(client.Config.Store(), ns, list)
+	},
+}
+
+var savedSearchDeleteCmd = &cobra.Command{
+	Use:   "delete \"name\"",
+	Short: "Permanently remove a saved search.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Saved search name required")
+			fmt.Println("💡 List the saved searches: clinote search saved list")
+			return
+		}
+		if !confirmed(cmd, fmt.Sprintf("Permanently remove the saved search \"%s\"?", args[0])) {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		if err = clinote.DeleteSavedSearch(ns, args[0]); err != nil {
+			fmt.Printf("❌ Failed to delete saved search: %v\n", err)
+			fmt.Println("💡 Removing saved searches requires a full access API key")
+			exit(1)
+		}
+		fmt.Println("✅ Saved search removed")
+	},
+}
+
+var searchAliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage local search aliases.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var searchAliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the search aliases.",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := defaultClient()
+		defer client.Close()
+		aliases, err := clinote.GetSearchAliases(client.Config.Store())
+		if err != nil {
+			fmt.Printf("❌ Cannot load the search aliases: %v\n", err)
+			fmt.Println("💡 Check that the config folder is readable")
+			exit(1)
+		}
+		clinote.WriteSearchAliasListing(os.Stdout, aliases)
+	},
+}
+
+var searchAliasSetCmd = &cobra.Command{
+	Use:   "set name \"query\"",
+	Short: "Create or change a search alias.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("❌ Alias name and query required")
+			fmt.Println("💡 Usage: clinote search alias set work-todos \"notebook:Work tag:todo\"")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		if err := clinote.SetSearchAlias(client.Config.Store(), args[0], args[1]); err != nil {
+			fmt.Printf("❌ Failed to save alias: %v\n", err)
+			fmt.Println("💡 Alias names can't contain spaces")
+			exit(1)
+		}
+		fmt.Printf("✅ Alias saved, use it with: clinote note list @%s\n", args[0])
+	},
+}
+
+var searchAliasRemoveCmd = &cobra.Command{
+	Use:   "remove name",
+	Short: "Remove a search alias.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Alias name required")
+			fmt.Println("💡 List the aliases: clinote search alias list")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		if err := clinote.RemoveSearchAlias(client.Config.Store(), args[0]); err != nil {
+			fmt.Printf("❌ Failed to remove alias: %v\n", err)
+			exit(1)
+		}
+		fmt.Println("✅ Alias removed")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(searchCmd)
+	searchCmd.AddCommand(savedSearchCmd)
+	searchCmd.AddCommand(searchAliasCmd)
+	savedSearchCmd.AddCommand(savedSearchListCmd)
+	savedSearchCmd.AddCommand(savedSearchNewCmd)
+	savedSearchCmd.AddCommand(savedSearchRunCmd)
+	savedSearchCmd.AddCommand(savedSearchDeleteCmd)
+	searchAliasCmd.AddCommand(searchAliasListCmd)
+	searchAliasCmd.AddCommand(searchAliasSetCmd)
+	searchAliasCmd.AddCommand(searchAliasRemoveCmd)
+	savedSearchRunCmd.Flags().IntP("count", "c", 20, "How many notes to show in the result.")
+	savedSearchDeleteCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+}
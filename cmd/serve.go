@@ -0,0 +1,162 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/TcM1911/clinote/server"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultListenAddr = "127.0.0.1:8457"
+	tokenEnv          = "CLINOTE_TOKEN"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the notes as a JSON HTTP API.",
+	Long: `
+Serve starts a local HTTP server with a JSON API for the notes so other
+tools and scripts can use them. Every request must have the API token in
+an "Authorization: Bearer <token>" header. The token is read from --token
+or the CLINOTE_TOKEN environment variable. If neither is set a random
+token is generated and printed.
+
+The database stays locked while the server handles requests and until it
+has been idle for a few seconds. Other clinote commands wait up to 10
+seconds for it and then fail.
+
+Endpoints:
+  GET    /v1/notes?search=&notebook=&offset=&count=
+  POST   /v1/notes                 {"title", "notebook", "markdown" or "enml", "tags"}
+  GET    /v1/notes/{guid}?format=markdown|enml|both
+  PATCH  /v1/notes/{guid}          {"title", "notebook", "markdown" or "enml"}
+  DELETE /v1/notes/{guid}
+  GET    /v1/notebooks
+  POST   /v1/notebooks             {"name", "stack", "default"}
+
+  clinote serve --listen 127.0.0.1:8457
+  curl -H "Authorization: Bearer $CLINOTE_TOKEN" localhost:8457/v1/notes?search=todo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			fmt.Printf("❌ Invalid listen flag value: %v\n", err)
+			return
+		}
+		token, err := cmd.Flags().GetString("token")
+		if err != nil {
+			fmt.Printf("❌ Invalid token flag value: %v\n", err)
+			return
+		}
+		if token == "" {
+			token = os.Getenv(tokenEnv)
+		}
+		generated := token == ""
+		if generated {
+			token = newToken()
+		}
+		if !isLoopback(addr) {
+			fmt.Printf("⚠️  %s is reachable from other machines, anyone with the token can read and change your notes\n", addr)
+		}
+
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			fmt.Printf("❌ Cannot connect to Evernote: %v\n", err)
+			fmt.Println("💡 Troubleshooting:")
+			fmt.Println("   • Check internet connection")
+			fmt.Println("   • Verify authentication: clinote user login")
+			exit(1)
+		}
+		handler, err := server.New(client.Config.Store(), ns, token)
+		if err != nil {
+			fmt.Printf("❌ Failed to start the server: %v\n", err)
+			exit(1)
+		}
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			fmt.Printf("❌ Failed to listen on %s: %v\n", addr, err)
+			fmt.Println("💡 Choose another address with --listen 127.0.0.1:PORT")
+			exit(1)
+		}
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		done := make(chan struct{})
+		go func() {
+			sig := make(chan os.Signal, 1)
+			signal.Notify(sig, os.Interrupt)
+			defer signal.Stop(sig)
+			<-sig
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(ctx)
+			close(done)
+		}()
+
+		fmt.Printf("✅ Serving the API on http://%s%s\n", l.Addr(), server.APIPrefix)
+		if generated {
+			fmt.Printf("   • Token: %s\n", token)
+			fmt.Printf("💡 Set %s to use the same token next time\n", tokenEnv)
+		}
+		fmt.Println("   • Press Ctrl+C to stop")
+		if err = srv.Serve(l); err != http.ErrServerClosed {
+			fmt.Printf("❌ Server failed: %v\n", err)
+			exit(1)
+		}
+		<-done
+	},
+}
+
+// newToken returns a random API token.
+func newToken() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic("Error when generating a token: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// isLoopback returns true if the address only listens on the loopback
+// interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("listen", defaultListenAddr, "Address to listen on.")
+	serveCmd.Flags().String("token", "", "API token, defaults to $"+tokenEnv+".")
+}
@@ -0,0 +1,61 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var setDefaultNotebookCmd = &cobra.Command{
+	Use:   "set-default \"notebook name\"",
+	Short: "Set the default notebook.",
+	Long: `
+Set-default makes the notebook the default notebook. New notes are
+created in the default notebook if no notebook is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Notebook name required")
+			fmt.Println("💡 Usage: clinote notebook set-default \"Notebook Name\"")
+			fmt.Println("   • List notebooks: clinote notebook list")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.SetDefaultNotebook(client.Config.Store(), ns, args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to set default notebook: %v\n", err)
+			fmt.Println("💡 Possible causes:")
+			fmt.Println("   • Notebook not found")
+			fmt.Println("   • Network connectivity issues")
+			fmt.Println("   • Insufficient permissions")
+			exit(1)
+		}
+		fmt.Printf("✅ \"%s\" is now the default notebook\n", args[0])
+	},
+}
+
+func init() {
+	notebookCmd.AddCommand(setDefaultNotebookCmd)
+}
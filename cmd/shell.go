@@ -0,0 +1,270 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/evernote"
+	"github.com/TcM1911/clinote/storage"
+	"github.com/TcM1911/clinote/tui"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+const (
+	shellPrompt      = "clinote> "
+	shellHistoryFile = "shell_history"
+	// maxShellHistory is the number of lines kept in the history file.
+	maxShellHistory = 1000
+)
+
+// session is the state shared by the commands run in the shell. It's nil
+// when a single command is run.
+var session *shellSession
+
+type shellSession struct {
+	db     *storage.Database
+	client *evernote.Client
+	// input is the shell's input. The commands read from it so they don't
+	// take input buffered for the shell.
+	input *bufio.Reader
+}
+
+// evernoteClient returns the session's client. The client is created on
+// first use so it can be replaced after the user changes credentials.
+func (s *shellSession) evernoteClient() *evernote.Client {
+	if s.client == nil {
+		s.client = evernote.NewClient(&clinote.DefaultConfig{DB: sessionStore{s.db}, UDB: s.db})
+	}
+	return s.client
+}
+
+// sessionStore is the database used by the commands in the shell. The
+// commands can't close it, it's closed when the shell exits.
+type sessionStore struct {
+	*storage.Database
+}
+
+func (sessionStore) Close() error {
+	return nil
+}
+
+// shellExit is the panic used to stop a command that calls exit.
+type shellExit int
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Run commands in an interactive shell.",
+	Long: `
+Shell runs clinote commands without starting a new process for each
+command. The database and the connection to Evernote are kept open for
+the whole session which makes the commands faster.
+
+Commands are typed without "clinote", for example "note list". Tab
+completes commands, flags, note titles and notebook names from the local
+cache. The history is kept between sessions and is browsed with the up
+and down keys.
+
+Type exit or press ctrl+d to leave the shell.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if session != nil {
+			fmt.Println("❌ Already running in the shell")
+			return
+		}
+		cfg := new(clinote.DefaultConfig)
+		db, err := storage.OpenTimeout(cfg.GetConfigFolder(), dbLockTimeout)
+		if err == storage.ErrDatabaseLocked {
+			printDatabaseLocked()
+			exit(1)
+		}
+		if err != nil {
+			fmt.Printf("❌ Database connection failed: %v\n", err)
+			exit(1)
+		}
+		session = &shellSession{db: db}
+		exit = func(code int) { panic(shellExit(code)) }
+		defer func() {
+			exit = os.Exit
+			session = nil
+			db.Close()
+		}()
+		// Ctrl+C stops the line being typed, not the shell.
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		defer signal.Stop(interrupts)
+
+		if _, err := defaultClient().GetNoteStore(); err != nil {
+			fmt.Printf("⚠️  Not connected to Evernote: %v\n", err)
+			fmt.Println("💡 Log in with: user login")
+		}
+		historyFile := filepath.Join(cfg.GetConfigFolder(), shellHistoryFile)
+		editor := tui.NewLineEditor(os.Stdin, os.Stdout)
+		editor.History = loadShellHistory(historyFile)
+		session.input = editor.Input()
+		editor.Complete = func(line string) (int, []string) {
+			return completeLine(db, line)
+		}
+		fmt.Println("CLInote shell, type help for the commands and exit to leave.")
+		for {
+			line, err := editor.ReadLine(shellPrompt)
+			if err == tui.ErrInterrupted {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			args, err := tui.SplitWords(line)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			if len(args) > 0 && args[0] == "clinote" {
+				args = args[1:]
+			}
+			if len(args) == 0 {
+				continue
+			}
+			appendShellHistory(historyFile, line)
+			switch args[0] {
+			case "exit", "quit":
+				return
+			case "shell":
+				fmt.Println("❌ Already running in the shell")
+				continue
+			}
+			runInShell(args)
+		}
+	},
+}
+
+// runInShell runs the command with the arguments. Panics and calls to
+// exit only stop the command.
+func runInShell(args []string) {
+	cmd, _, _ := RootCmd.Find(args)
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(shellExit); !ok {
+				fmt.Printf("❌ %v\n", r)
+			}
+		}
+		// The user commands change the credentials so the client is
+		// created again for the next command.
+		for c := cmd; c != nil; c = c.Parent() {
+			if c == userCmd {
+				session.client = nil
+			}
+		}
+	}()
+	resetFlags(RootCmd)
+	RootCmd.SetArgs(args)
+	// Cobra prints the errors.
+	RootCmd.Execute()
+}
+
+// resetFlags sets the flags of the command and its subcommands back to
+// their defaults so the flags given to one command don't stay set for
+// the next.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *flag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if f.Value.Type() == "stringSlice" {
+			// Setting a slice flag appends to it once it's changed so
+			// the value is replaced instead.
+			var def []string
+			if d := strings.Trim(f.DefValue, "[]"); d != "" {
+				def = strings.Split(d, ",")
+			}
+			fs := flag.NewFlagSet(f.Name, flag.ContinueOnError)
+			fs.StringSlice(f.Name, def, f.Usage)
+			f.Value = fs.Lookup(f.Name).Value
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// completeLine completes the line typed in the shell.
+func completeLine(db clinote.Storager, line string) (int, []string) {
+	words, start := tui.SplitPartial(line)
+	args, toComplete := words[:len(words)-1], words[len(words)-1]
+	if len(args) > 0 && args[0] == "clinote" {
+		args = args[1:]
+	}
+	completions := completeArgs(db, args, toComplete)
+	if len(args) == 0 && strings.HasPrefix("exit", toComplete) {
+		completions = append(completions, "exit")
+	}
+	for i, c := range completions {
+		completions[i] = tui.QuoteWord(c)
+	}
+	return start, completions
+}
+
+// loadShellHistory reads the history file. The file is trimmed if it has
+// grown too long.
+func loadShellHistory(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if s.Text() != "" {
+			lines = append(lines, s.Text())
+		}
+	}
+	f.Close()
+	if len(lines) > maxShellHistory {
+		lines = lines[len(lines)-maxShellHistory:]
+		// The history is only a convenience so errors are ignored.
+		if f, err = os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0600); err == nil {
+			f.WriteString(strings.Join(lines, "\n") + "\n")
+			f.Close()
+		}
+	}
+	return lines
+}
+
+func appendShellHistory(path, line string) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return
+	}
+	f.WriteString(line + "\n")
+	f.Close()
+}
+
+func init() {
+	RootCmd.AddCommand(shellCmd)
+}
@@ -0,0 +1,140 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var stackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "List, rename and remove notebook stacks.",
+	Long:  `List, rename and remove notebook stacks.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var stackListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stacks.",
+	Long: `
+List returns all stacks with the notebooks in them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		stacks, err := clinote.GetStacks(client.Config.Store(), ns)
+		if err != nil {
+			fmt.Printf("❌ Cannot retrieve stacks: %v\n", err)
+			printStackTroubleshooting()
+			exit(1)
+		}
+		if len(stacks) == 0 {
+			fmt.Println("No stacks found")
+			return
+		}
+		counts, err := clinote.GetNoteCounts(ns)
+		if err != nil {
+			fmt.Printf("⚠️  Cannot retrieve note counts: %v\n", err)
+		}
+		var nbs []*clinote.Notebook
+		for _, s := range stacks {
+			nbs = append(nbs, s.Notebooks...)
+		}
+		clinote.WriteNotebookTree(os.Stdout, nbs, counts)
+	},
+}
+
+var stackRenameCmd = &cobra.Command{
+	Use:   "rename \"stack name\" \"new name\"",
+	Short: "Rename a stack.",
+	Long: `
+Rename moves all the notebooks in the stack to the new stack.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			fmt.Println("❌ Stack name and new name required")
+			fmt.Println("💡 Usage: clinote stack rename \"Stack Name\" \"New Name\"")
+			fmt.Println("   • List stacks: clinote stack list")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.RenameStack(client.Config.Store(), ns, args[0], args[1])
+		if err != nil {
+			fmt.Printf("❌ Failed to rename stack: %v\n", err)
+			printStackTroubleshooting()
+			exit(1)
+		}
+		fmt.Println("✅ Stack renamed")
+	},
+}
+
+var stackRemoveCmd = &cobra.Command{
+	Use:   "remove \"stack name\"",
+	Short: "Remove a stack.",
+	Long: `
+Remove takes all the notebooks out of the stack. The notebooks and their
+notes are kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Stack name required")
+			fmt.Println("💡 Usage: clinote stack remove \"Stack Name\"")
+			fmt.Println("   • List stacks: clinote stack list")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.RemoveStack(client.Config.Store(), ns, args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to remove stack: %v\n", err)
+			printStackTroubleshooting()
+			exit(1)
+		}
+		fmt.Println("✅ Stack removed")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(stackCmd)
+	stackCmd.AddCommand(stackListCmd)
+	stackCmd.AddCommand(stackRenameCmd)
+	stackCmd.AddCommand(stackRemoveCmd)
+}
+
+func printStackTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • List stacks: clinote stack list")
+	fmt.Println("   • Check stack name spelling (case sensitive)")
+	fmt.Println("   • Check internet connection")
+}
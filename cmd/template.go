@@ -0,0 +1,145 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "List, create and edit note templates.",
+	Long: `
+Templates are markdown skeletons for new notes. They are stored in the
+templates folder in the config folder. Notes in the notebook set with
+"clinote user set template-notebook" can also be used as templates.
+
+The following placeholders are replaced when a note is created:
+  {{title}}     The note's title.
+  {{notebook}}  The note's notebook.
+  {{user}}      The active credential's name.
+  {{date}}      The current date.
+  {{time}}      The current time.
+  {{datetime}}  The current date and time.
+
+Create a note from a template with:
+  clinote note new --title "Weekly sync" --template meeting`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates.",
+	Long: `
+List returns the templates in the config folder and the template notebook.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := templateClient(true)
+		defer c.Store.Close()
+		ts, err := clinote.GetTemplates(c)
+		if err != nil {
+			fmt.Printf("❌ Cannot retrieve templates: %v\n", err)
+			printTemplateTroubleshooting()
+			exit(1)
+		}
+		clinote.WriteTemplateListing(os.Stdout, ts)
+	},
+}
+
+var templateNewCmd = &cobra.Command{
+	Use:   "new \"template name\"",
+	Short: "Create a new template.",
+	Long: `
+New creates a template in the config folder and opens it in the editor.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Template name required")
+			fmt.Println("💡 Usage: clinote template new \"name\"")
+			return
+		}
+		c := templateClient(false)
+		defer c.Store.Close()
+		if err := clinote.NewTemplate(c, args[0]); err != nil {
+			fmt.Printf("❌ Failed to create template: %v\n", err)
+			printTemplateTroubleshooting()
+			exit(1)
+		}
+	},
+}
+
+var templateEditCmd = &cobra.Command{
+	Use:   "edit \"template name\"",
+	Short: "Edit a template.",
+	Long: `
+Edit opens the template in the config folder in the editor. Templates
+in the template notebook are edited as notes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Template name required")
+			fmt.Println("💡 Usage: clinote template edit \"name\"")
+			return
+		}
+		c := templateClient(false)
+		defer c.Store.Close()
+		if err := clinote.EditTemplate(c, args[0]); err != nil {
+			fmt.Printf("❌ Failed to edit template: %v\n", err)
+			printTemplateTroubleshooting()
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateNewCmd)
+	templateCmd.AddCommand(templateEditCmd)
+}
+
+// templateClient returns a client for the template commands. Templates in
+// the config folder don't need Evernote, so the notestore is only used if
+// notebookTemplates is true and a template notebook is set.
+func templateClient(notebookTemplates bool) *clinote.Client {
+	client := defaultClient()
+	var ns clinote.NotestoreClient
+	settings, err := client.Config.Store().GetSettings()
+	if notebookTemplates && err == nil && settings.TemplateNotebook != "" {
+		if ns, err = client.GetNoteStore(); err != nil {
+			fmt.Printf("❌ Cannot connect to Evernote: %v\n", err)
+			fmt.Println("💡 Troubleshooting:")
+			fmt.Println("   • Check internet connection")
+			fmt.Println("   • Verify authentication: clinote user login")
+			fmt.Println("   • Templates in the config folder work offline: clinote template edit \"name\"")
+			client.Close()
+			exit(1)
+		}
+	}
+	return noteClient(client, ns, clinote.DefaultClientOptions)
+}
+
+func printTemplateTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • List templates: clinote template list")
+	fmt.Println("   • Check if $EDITOR environment variable is set")
+	fmt.Println("   • Check the template notebook: clinote notebook list")
+}
@@ -0,0 +1,180 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore and expunge deleted notes.",
+	Long:  `List, restore and expunge deleted notes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the notes in the trash.",
+	Long: `
+List returns the notes in the trash sorted by the modified time. The
+index of the note in the list can be used with the restore and expunge
+commands. The listing is kept apart from the note searches so it doesn't
+change the index of the notes used by the other commands.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		count, err := cmd.Flags().GetInt("count")
+		if err != nil {
+			fmt.Printf("⚠️  Invalid count value, using default (20): %v\n", err)
+			count = 20
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		list, err := clinote.GetTrash(ns, count)
+		if err != nil {
+			fmt.Printf("❌ Failed to list the trash: %v\n", err)
+			printTrashTroubleshooting()
+			exit(1)
+		}
+		err = client.Config.Store().SaveTrashSearch(list)
+		if err != nil {
+			fmt.Printf("❌ Failed to save the trash listing: %v\n", err)
+			fmt.Println("💡 The index of the notes can't be used with restore and expunge")
+			exit(1)
+		}
+		nbs, err := clinote.GetNotebooks(client.Config.Store(), ns, false)
+		if err != nil {
+			fmt.Printf("❌ Cannot retrieve notebook list: %v\n", err)
+			return
+		}
+		clinote.WriteNoteListing(os.Stdout, list, nbs)
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore \"note title\"|index",
+	Short: "Restore a note from the trash.",
+	Long: `
+Restore moves the note from the trash back to its notebook.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote trash restore \"Note Title\"")
+			fmt.Println("   • Or use note index from: clinote trash list")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.RestoreNote(client.Config.Store(), ns, args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to restore note: %v\n", err)
+			printTrashTroubleshooting()
+			exit(1)
+		}
+		fmt.Println("✅ Note restored")
+	},
+}
+
+var trashExpungeCmd = &cobra.Command{
+	Use:   "expunge \"note title\"|index",
+	Short: "Permanently remove a note from the trash.",
+	Long: `
+Expunge permanently removes the note from the trash. An expunged note
+can't be restored.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("❌ Note identifier required")
+			fmt.Println("💡 Usage: clinote trash expunge \"Note Title\"")
+			fmt.Println("   • Or use note index from: clinote trash list")
+			return
+		}
+		if !confirmed(cmd, fmt.Sprintf("Permanently remove \"%s\"?", args[0])) {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.ExpungeNote(client.Config.Store(), ns, args[0])
+		if err != nil {
+			fmt.Printf("❌ Failed to expunge note: %v\n", err)
+			printTrashTroubleshooting()
+			exit(1)
+		}
+		fmt.Println("✅ Note expunged")
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently remove all notes in the trash.",
+	Long: `
+Empty permanently removes all the notes in the trash. The notes can't
+be restored.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !confirmed(cmd, "Permanently remove all notes in the trash?") {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.EmptyTrash(ns)
+		if err != nil {
+			fmt.Printf("❌ Failed to empty the trash: %v\n", err)
+			printTrashTroubleshooting()
+			exit(1)
+		}
+		fmt.Println("✅ Trash emptied")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashExpungeCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	trashListCmd.Flags().IntP("count", "c", 20, "How many notes to show in the result.")
+	trashExpungeCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+	trashEmptyCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+}
+
+func printTrashTroubleshooting() {
+	fmt.Println("💡 Troubleshooting:")
+	fmt.Println("   • List the notes in the trash: clinote trash list")
+	fmt.Println("   • Check note title spelling (case sensitive)")
+	fmt.Println("   • Expunging requires a full access API key")
+}
@@ -0,0 +1,105 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/tui"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse and edit notes in a full-screen terminal UI.",
+	Long: `
+Tui shows the notebooks grouped by stack, the notes in the selected
+notebook and a preview of the selected note side by side.
+
+Keys:
+  tab, shift+tab   Switch between the notebooks, notes and preview.
+  up, down, j, k   Move the selection or scroll the preview.
+  enter            Open the notebook, preview the note or edit it.
+  /                Filter the notes by title, enter searches the notestore.
+  esc              Clear the search.
+  e                Edit the note in $EDITOR.
+  n                Create a note in the selected notebook.
+  r                Rename the note.
+  m                Move the note to another notebook, tab completes the name.
+  d                Move the note to the trash.
+  R                Refresh the notebooks and notes.
+  q                Quit.
+
+The notes shown are saved as the last search so they can be referred to
+by index from the command line afterwards.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Printf("❌ Invalid raw flag value: %v\n", err)
+			return
+		}
+		if !isTerminal(os.Stdout) {
+			fmt.Println("❌ The terminal UI needs a terminal")
+			fmt.Println("💡 List notes with: clinote note list")
+			exit(1)
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			fmt.Printf("❌ Cannot connect to Evernote: %v\n", err)
+			fmt.Println("💡 Troubleshooting:")
+			fmt.Println("   • Check internet connection")
+			fmt.Println("   • Verify authentication: clinote user login")
+			exit(1)
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		if settings, err := client.Config.Store().GetSettings(); err == nil && settings.ConfirmEdit {
+			opts |= clinote.ConfirmChanges
+		}
+		c := noteClient(client, ns, clinote.DefaultClientOptions)
+
+		term, err := tui.OpenTTY(os.Stdout)
+		if err != nil {
+			fmt.Printf("❌ Failed to start the terminal UI: %v\n", err)
+			exit(1)
+		}
+		if err = runTUI(tui.New(c, term, tui.Options{Color: terminalOptions().Color, NoteOptions: opts}), term); err != nil {
+			fmt.Printf("❌ Terminal UI failed: %v\n", err)
+			exit(1)
+		}
+	},
+}
+
+// runTUI runs the app and restores the terminal when it returns or
+// panics. It's a separate function so the terminal is restored before
+// exit is called.
+func runTUI(app *tui.App, term *tui.TTY) error {
+	defer term.Close()
+	return app.Run()
+}
+
+func init() {
+	RootCmd.AddCommand(tuiCmd)
+	tuiCmd.Flags().Bool("raw", false, "Edit notes in XML format.")
+}
@@ -18,14 +18,12 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"sort"
 	"strconv"
 
 	"github.com/TcM1911/clinote"
-	"github.com/TcM1911/clinote/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -56,7 +54,7 @@ var userListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all credentials",
 	Run: func(cmd *cobra.Command, args []string) {
-		db, err := storage.Open((new(clinote.DefaultConfig)).GetConfigFolder())
+		db, err := openDB()
 		if err != nil {
 			fmt.Printf("❌ Database connection failed: %v\n", err)
 			fmt.Println("💡 Troubleshooting:")
@@ -74,7 +72,7 @@ var userAddCmd = &cobra.Command{
 	Short: "Add new credential",
 	Long:  "Add a new credential set for the user. Please follow the instructions on https://dev.evernote.com/doc/articles/dev_tokens.php to generate access tokens.",
 	Run: func(cmd *cobra.Command, args []string) {
-		db, err := storage.Open((new(clinote.DefaultConfig)).GetConfigFolder())
+		db, err := openDB()
 		if err != nil {
 			fmt.Printf("❌ Database connection failed: %v\n", err)
 			fmt.Println("💡 Troubleshooting:")
@@ -91,7 +89,7 @@ var userRmCmd = &cobra.Command{
 	Use:   "remove",
 	Short: "Remove a credential",
 	Run: func(cmd *cobra.Command, args []string) {
-		db, err := storage.Open((new(clinote.DefaultConfig)).GetConfigFolder())
+		db, err := openDB()
 		if err != nil {
 			fmt.Printf("❌ Database connection failed: %v\n", err)
 			fmt.Println("💡 Troubleshooting:")
@@ -108,7 +106,7 @@ var userSetCmd = &cobra.Command{
 	Use:   "set",
 	Short: "Set a user configuration",
 	Run: func(cmd *cobra.Command, args []string) {
-		db, err := storage.Open((new(clinote.DefaultConfig)).GetConfigFolder())
+		db, err := openDB()
 		if err != nil {
 			fmt.Printf("❌ Database connection failed: %v\n", err)
 			fmt.Println("💡 Troubleshooting:")
@@ -127,6 +125,12 @@ var setConfigOpts = []struct {
 	desc string
 }{
 	{"credential", "An index value.", "Set the active credential for the user."},
+	{"confirm-edit", "true or false", "Review the changes before an edited note is saved."},
+	{"picker", "builtin or fzf", "The picker used by the interactive flag."},
+	{"template-notebook", "A notebook name.", "Notebook with shared note templates, \"\" to unset."},
+	{"journal-notebook", "A notebook name.", "Notebook for journal notes, \"\" for the default notebook."},
+	{"journal-title", "A title pattern.", "Journal note title, default \"{{date}} Journal\"."},
+	{"journal-template", "A template name.", "Template for new journal notes, \"\" to unset."},
 }
 
 func setConfig(store clinote.UserCredentialStore, db clinote.Storager, args []string) {
@@ -137,6 +141,18 @@ func setConfig(store clinote.UserCredentialStore, db clinote.Storager, args []st
 	switch args[0] {
 	case "credential":
 		setCredential(store, db, args[1])
+	case "confirm-edit":
+		setConfirmEdit(db, args[1])
+	case "picker":
+		setPicker(db, args[1])
+	case "template-notebook":
+		updateSettings(db, func(s *clinote.Settings) { s.TemplateNotebook = args[1] })
+	case "journal-notebook":
+		updateSettings(db, func(s *clinote.Settings) { s.JournalNotebook = args[1] })
+	case "journal-title":
+		updateSettings(db, func(s *clinote.Settings) { s.JournalTitle = args[1] })
+	case "journal-template":
+		updateSettings(db, func(s *clinote.Settings) { s.JournalTemplate = args[1] })
 	default:
 		printConfigOptions()
 	}
@@ -182,6 +198,39 @@ func setCredential(store clinote.UserCredentialStore, db clinote.Storager, strIn
 	}
 }
 
+func setConfirmEdit(db clinote.Storager, val string) {
+	confirm, err := strconv.ParseBool(val)
+	if err != nil {
+		fmt.Printf("%s is not true or false\n", val)
+		return
+	}
+	updateSettings(db, func(s *clinote.Settings) { s.ConfirmEdit = confirm })
+}
+
+func setPicker(db clinote.Storager, val string) {
+	if val != clinote.BuiltinPicker && val != clinote.FzfPicker {
+		fmt.Printf("%s is not %s or %s\n", val, clinote.BuiltinPicker, clinote.FzfPicker)
+		return
+	}
+	updateSettings(db, func(s *clinote.Settings) { s.Picker = val })
+}
+
+// updateSettings applies the change to the stored settings.
+func updateSettings(db clinote.Storager, change func(*clinote.Settings)) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		fmt.Printf("❌ Cannot load user settings: %v\n", err)
+		return
+	}
+	change(settings)
+	if err = db.StoreSettings(settings); err != nil {
+		fmt.Printf("❌ Failed to save settings: %v\n", err)
+		fmt.Println("💡 Check:")
+		fmt.Println("   • Disk space available")
+		fmt.Println("   • Write permissions to config directory")
+	}
+}
+
 func printConfigOptions() {
 	n := len(setConfigOpts)
 	vals, args, descs := make([]string, n, n), make([]string, n, n), make([]string, n, n)
@@ -274,10 +323,8 @@ func parseStringFlag(cmd *cobra.Command, flag, parseErr, scanLine string) string
 		}
 	}
 	if n == "" {
-		scanner := bufio.NewScanner(os.Stdin)
 		fmt.Print(scanLine)
-		scanner.Scan()
-		name = scanner.Text()
+		name, _ = readLine()
 	} else {
 		name = n
 	}
@@ -0,0 +1,96 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"sort"
+	"strings"
+)
+
+// CompleteNoteTitles returns the titles of the cached notes that start
+// with the prefix, ignoring case. The notes in the last search come first
+// followed by the other notes in the link index. Only the local cache is
+// used so it's fast enough for tab completion.
+func CompleteNoteTitles(db Storager, prefix string) ([]string, error) {
+	notes, err := db.GetSearch()
+	if err != nil {
+		return nil, err
+	}
+	var titles []string
+	seen := make(map[string]bool)
+	add := func(title string) {
+		if title != "" && !seen[title] && hasPrefixFold(title, prefix) {
+			seen[title] = true
+			titles = append(titles, title)
+		}
+	}
+	for _, n := range notes {
+		add(n.Title)
+	}
+	index, err := db.GetLinkIndex()
+	if err != nil {
+		return nil, err
+	}
+	var indexed []string
+	for _, e := range index.Notes {
+		indexed = append(indexed, e.Title)
+	}
+	sort.Strings(indexed)
+	for _, title := range indexed {
+		add(title)
+	}
+	return titles, nil
+}
+
+// CompleteNotebookNames returns the names of the cached notebooks that
+// start with the prefix, ignoring case. The cache is used even if it's
+// outdated.
+func CompleteNotebookNames(db Storager, prefix string) ([]string, error) {
+	list, err := db.GetNotebookCache()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, b := range list.Notebooks {
+		if hasPrefixFold(b.Name, prefix) {
+			names = append(names, b.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CompleteStackNames returns the names of the stacks in the notebook
+// cache that start with the prefix, ignoring case.
+func CompleteStackNames(db Storager, prefix string) ([]string, error) {
+	list, err := db.GetNotebookCache()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range groupByStack(list.Notebooks) {
+		if hasPrefixFold(s.Name, prefix) {
+			names = append(names, s.Name)
+		}
+	}
+	return names, nil
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,68 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteNoteTitles(t *testing.T) {
+	db := &mockStore{
+		getSearch: func() ([]*Note, error) {
+			return []*Note{{Title: "Weekly report"}, {Title: "Garden plan"}, {Title: "weekend"}}, nil
+		},
+		getLinkIndex: func() (*LinkIndex, error) {
+			return &LinkIndex{Notes: map[string]*LinkIndexEntry{
+				"1": {Title: "Wedding"},
+				"2": {Title: "Weekly report"},
+				"3": {Title: "Web links"},
+				"4": {Title: "Shopping"},
+			}}, nil
+		},
+	}
+	titles, err := CompleteNoteTitles(db, "we")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Weekly report", "weekend", "Web links", "Wedding"}, titles)
+
+	titles, err = CompleteNoteTitles(db, "")
+	assert.NoError(t, err)
+	assert.Len(t, titles, 6)
+}
+
+func TestCompleteNotebookAndStackNames(t *testing.T) {
+	db := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			// The cache is used even if it's outdated.
+			return &NotebookCacheList{Notebooks: []*Notebook{
+				{Name: "Work", Stack: "Life"},
+				{Name: "Inbox"},
+				{Name: "workouts", Stack: "Health"},
+				{Name: "Home", Stack: "Life"},
+			}}, nil
+		},
+	}
+	names, err := CompleteNotebookNames(db, "wor")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Work", "workouts"}, names)
+
+	stacks, err := CompleteStackNames(db, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Health", "Life"}, stacks)
+}
@@ -0,0 +1,86 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EditAction is what should be done with the changes made to a note.
+type EditAction int
+
+const (
+	// SaveEdit saves the changes to the notestore.
+	SaveEdit EditAction = iota
+	// ReEdit opens the edited note in the editor again.
+	ReEdit
+	// DiscardEdit throws away the changes.
+	DiscardEdit
+)
+
+var (
+	// ErrNoConfirmer is returned if changes should be confirmed but
+	// the client has no Confirmer.
+	ErrNoConfirmer = errors.New("no confirmer set")
+)
+
+// Confirmer lets the user review the changes made to a note before
+// they are saved.
+type Confirmer interface {
+	// Confirm shows the changes between the original and the edited
+	// note and returns what should be done with them.
+	Confirm(original, edited *Note, opts NoteOption) (EditAction, error)
+}
+
+// TerminalConfirmer shows a colored diff of the changes and prompts
+// the user for what to do.
+type TerminalConfirmer struct {
+	In  io.Reader
+	Out io.Writer
+	r   *bufio.Reader
+}
+
+// Confirm writes the diff to Out and reads the answer from In.
+func (c *TerminalConfirmer) Confirm(original, edited *Note, opts NoteOption) (EditAction, error) {
+	if c.r == nil {
+		c.r = bufio.NewReader(c.In)
+	}
+	err := WriteColoredNoteDiff(c.Out, original, edited, "original", "edited", opts)
+	if err != nil {
+		return DiscardEdit, err
+	}
+	for {
+		fmt.Fprint(c.Out, "Save changes? [s]ave, [e]dit again, [d]iscard: ")
+		line, err := c.r.ReadString('\n')
+		if err != nil && line == "" {
+			return DiscardEdit, err
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "s", "save", "y", "yes":
+			return SaveEdit, nil
+		case "e", "edit":
+			return ReEdit, nil
+		case "d", "discard", "n", "no":
+			return DiscardEdit, nil
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalConfirmer(t *testing.T) {
+	assert := assert.New(t)
+	original := &Note{Title: "Title", MD: "Old line"}
+	edited := &Note{Title: "Title", MD: "New line"}
+	tests := []struct {
+		name     string
+		input    string
+		expected EditAction
+	}{
+		{"save", "s\n", SaveEdit},
+		{"edit", "e\n", ReEdit},
+		{"discard", "d\n", DiscardEdit},
+		{"ask_again_on_unknown_answer", "x\nsave\n", SaveEdit},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			c := &TerminalConfirmer{In: strings.NewReader(test.input), Out: out}
+			action, err := c.Confirm(original, edited, DefaultNoteOption)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.expected, action, "Wrong action returned")
+			assert.Contains(out.String(), colorRed+"-Old line"+colorReset)
+			assert.Contains(out.String(), colorGreen+"+New line"+colorReset)
+		})
+	}
+	t.Run("discard_on_eof", func(t *testing.T) {
+		c := &TerminalConfirmer{In: strings.NewReader(""), Out: new(bytes.Buffer)}
+		action, err := c.Confirm(original, edited, DefaultNoteOption)
+		assert.Error(err, "Should return an error")
+		assert.Equal(DiscardEdit, action, "Should discard the changes")
+	})
+}
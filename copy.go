@@ -0,0 +1,71 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+// CopyNote copies the note to the notebook. If no notebook is given, a
+// duplicate of the note is created in the same notebook.
+func CopyNote(db Storager, ns NotestoreClient, title, notebook string) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	notebookGUID := n.Notebook.GUID
+	if notebook != "" {
+		nb, err := findNotebook(db, ns, notebook)
+		if err != nil {
+			return err
+		}
+		notebookGUID = nb.GUID
+	}
+	return ns.CopyNote(n.GUID, notebookGUID)
+}
+
+// TransferNote copies the note from the src notestore to the dst notestore.
+// The content, attributes, tags and resources of the note are copied. The
+// notes are resolved using the src notestore so db should be the storage
+// of the src account. If no notebook is given, the note is created in the
+// default notebook of the dst account.
+func TransferNote(db Storager, src, dst NotestoreClient, title, notebook string) error {
+	n, err := GetNote(db, src, title, "")
+	if err != nil {
+		return err
+	}
+	note, err := src.ExportNote(n.GUID)
+	if err != nil {
+		return err
+	}
+	note.GUID = ""
+	note.Notebook = nil
+	if notebook != "" {
+		// The notebook cache in db belongs to the src account so the
+		// notebooks are fetched from the dst notestore directly.
+		nbs, err := dst.GetAllNotebooks()
+		if err != nil {
+			return err
+		}
+		for _, nb := range nbs {
+			if nb.Name == notebook {
+				note.Notebook = nb
+			}
+		}
+		if note.Notebook == nil {
+			return ErrNoNotebookFound
+		}
+	}
+	return dst.CreateNote(note)
+}
@@ -0,0 +1,90 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyNote(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Notebooks: []*Notebook{&Notebook{Name: "Archive", GUID: "ARCHIVE"}}}, nil
+		},
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	var copied []string
+	ns := nsWithNote(&Note{Title: "Plan", GUID: "GUID", Notebook: &Notebook{GUID: "WORK"}})
+	ns.getAllNotebooks = func() ([]*Notebook, error) {
+		return []*Notebook{&Notebook{Name: "Archive", GUID: "ARCHIVE"}}, nil
+	}
+	ns.copyNote = func(guid, notebookGUID string) error {
+		copied = append(copied, guid+":"+notebookGUID)
+		return nil
+	}
+	assert.NoError(CopyNote(store, ns, "Plan", "Archive"), "Should not return an error")
+	assert.NoError(CopyNote(store, ns, "Plan", ""), "Should not return an error")
+	assert.Equal([]string{"GUID:ARCHIVE", "GUID:WORK"}, copied, "Wrong copies")
+	assert.Equal(ErrNoNotebookFound, CopyNote(store, ns, "Plan", "Missing"), "Wrong error")
+	assert.Equal(ErrNoNoteFound, CopyNote(store, ns, "Missing", ""), "Wrong error")
+}
+
+func TestTransferNote(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	src := nsWithNote(&Note{Title: "Plan", GUID: "GUID", Notebook: &Notebook{GUID: "WORK"}})
+	src.exportNote = func(guid string) (*Note, error) {
+		return &Note{
+			Title:      "Plan",
+			GUID:       guid,
+			Body:       "<en-note>plan</en-note>",
+			Notebook:   &Notebook{GUID: "WORK"},
+			Tags:       []string{"q3"},
+			Attributes: &NoteAttributes{Author: "joakim"},
+			Resources:  []*Resource{&Resource{Data: []byte("img"), Mime: "image/png"}},
+		}, nil
+	}
+	var created *Note
+	dst := &mockNS{
+		getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{&Notebook{Name: "Personal", GUID: "PERSONAL"}}, nil },
+		createNote:      func(n *Note) error { created = n; return nil },
+	}
+
+	t.Run("to notebook", func(t *testing.T) {
+		assert.NoError(TransferNote(store, src, dst, "Plan", "Personal"), "Should not return an error")
+		assert.Equal("", created.GUID, "GUID should be cleared")
+		assert.Equal("PERSONAL", created.Notebook.GUID, "Wrong notebook")
+		assert.Equal("<en-note>plan</en-note>", created.Body, "Content should be copied")
+		assert.Equal([]string{"q3"}, created.Tags, "Tags should be copied")
+		assert.Equal("joakim", created.Attributes.Author, "Attributes should be copied")
+		assert.Len(created.Resources, 1, "Resources should be copied")
+	})
+	t.Run("to default notebook", func(t *testing.T) {
+		assert.NoError(TransferNote(store, src, dst, "Plan", ""), "Should not return an error")
+		assert.Nil(created.Notebook, "Note should be created in the default notebook")
+	})
+	t.Run("missing notebook", func(t *testing.T) {
+		assert.Equal(ErrNoNotebookFound, TransferNote(store, src, dst, "Plan", "Work"), "Wrong error")
+	})
+}
@@ -0,0 +1,93 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bufio"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+// DefaultNoteTitle is used for new notes without a title.
+const DefaultNoteTitle = "Untitled note"
+
+var (
+	mdHeadingRegexp   = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	htmlHeadingRegexp = regexp.MustCompile(`(?is)<h[1-6][^>]*>(.*?)</h[1-6]>`)
+	htmlTagRegexp     = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// CreateNoteFromContent saves a new note with the content. The content is
+// markdown unless the RawNote option is set, in which case it's an ENML
+// document, an HTML document or an HTML fragment. If the note has no
+// title, the first heading in the content is used as the title.
+func CreateNoteFromContent(ns NotestoreClient, note *Note, content string, opts NoteOption) error {
+	raw := opts&RawNote != 0
+	if note.Title == "" {
+		note.Title = TitleFromContent(content, raw)
+	}
+	if note.Title == "" {
+		note.Title = DefaultNoteTitle
+	}
+	if raw {
+		body, err := enmlBody(content)
+		if err != nil {
+			return err
+		}
+		note.Body = body
+	} else {
+		note.MD = content
+	}
+	return SaveNewNote(ns, note, raw)
+}
+
+// TitleFromContent returns the text of the first heading in the content.
+// If the content has no heading, an empty string is returned.
+func TitleFromContent(content string, raw bool) string {
+	if raw {
+		m := htmlHeadingRegexp.FindStringSubmatch(content)
+		if m == nil {
+			return ""
+		}
+		return strings.TrimSpace(html.UnescapeString(htmlTagRegexp.ReplaceAllString(m[1], "")))
+	}
+	s := bufio.NewScanner(strings.NewReader(content))
+	for s.Scan() {
+		if m := mdHeadingRegexp.FindStringSubmatch(strings.TrimSpace(s.Text())); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// enmlBody returns the content that goes inside the en-note element. The
+// XML header and en-note element of an ENML document are removed. HTML is
+// converted to ENML by removing everything outside the body element and
+// the elements and attributes that ENML doesn't allow.
+func enmlBody(content string) (string, error) {
+	if strings.Contains(content, "<en-note") {
+		n := new(Note)
+		if err := decodeXML(content, n); err == nil {
+			return n.Body, nil
+		}
+	}
+	return markdown.HTMLToENML(content)
+}
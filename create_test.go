@@ -0,0 +1,74 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTitleFromContent(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("Report", TitleFromContent("Intro\n\n## Report ##\n# Other", false))
+	assert.Equal("", TitleFromContent("No heading\n#hashtag", false))
+	assert.Equal("Build & test", TitleFromContent("<p>x</p><h2 class=\"t\">Build <b>&amp;</b> test</h2>", true))
+	assert.Equal("", TitleFromContent("<p>No heading</p>", true))
+}
+
+func TestCreateNoteFromContent(t *testing.T) {
+	assert := assert.New(t)
+	var created *Note
+	ns := &mockNS{createNote: func(n *Note) error { created = n; return nil }}
+
+	t.Run("markdown with title from heading", func(t *testing.T) {
+		err := CreateNoteFromContent(ns, new(Note), "# Report\n\nAll good", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Report", created.Title, "Title should be taken from the heading")
+		assert.Equal(XMLHeader+"<en-note><h1>Report</h1>\n\n<p>All good</p>\n</en-note>", created.Body)
+	})
+	t.Run("given title is kept", func(t *testing.T) {
+		err := CreateNoteFromContent(ns, &Note{Title: "Given"}, "# Report", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Given", created.Title, "Wrong title")
+	})
+	t.Run("default title", func(t *testing.T) {
+		err := CreateNoteFromContent(ns, new(Note), "text", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(DefaultNoteTitle, created.Title, "Wrong title")
+	})
+	t.Run("raw ENML document", func(t *testing.T) {
+		doc := XMLHeader + "<en-note><div>Body</div></en-note>"
+		err := CreateNoteFromContent(ns, &Note{Title: "ENML"}, doc, RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(doc, created.Body, "ENML should not be wrapped twice")
+	})
+	t.Run("raw HTML document", func(t *testing.T) {
+		doc := "<html><head><title>x</title></head><body><h1>Page</h1><p>Body</p></body></html>"
+		err := CreateNoteFromContent(ns, new(Note), doc, RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Page", created.Title, "Wrong title")
+		assert.Equal(XMLHeader+"<en-note><h1>Page</h1><p>Body</p></en-note>", created.Body)
+	})
+	t.Run("raw HTML is made valid ENML", func(t *testing.T) {
+		doc := `<div id="main" class="post"><script>x()</script><p onclick="y()">Line<br>Next</p></div>`
+		err := CreateNoteFromContent(ns, &Note{Title: "HTML"}, doc, RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div><p>Line<br/>Next</p></div></en-note>", created.Body)
+	})
+}
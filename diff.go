@@ -0,0 +1,110 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const diffContextLines = 3
+
+// ANSI escape codes used to color the diff.
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// WriteNoteDiff writes a unified diff between the two notes using the writer.
+// The header and the content are compared, the raw content is used if the
+// RawNote option is set. Nothing is written if the notes are equal.
+func WriteNoteDiff(w io.Writer, from, to *Note, fromLabel, toLabel string, opts NoteOption) error {
+	diff, err := noteDiff(from, to, fromLabel, toLabel, opts)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, diff)
+	return err
+}
+
+// WriteColoredNoteDiff writes the same diff as WriteNoteDiff but with
+// ANSI colors for terminal output.
+func WriteColoredNoteDiff(w io.Writer, from, to *Note, fromLabel, toLabel string, opts NoteOption) error {
+	diff, err := noteDiff(from, to, fromLabel, toLabel, opts)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, colorDiff(diff))
+	return err
+}
+
+func noteDiff(from, to *Note, fromLabel, toLabel string, opts NoteOption) (string, error) {
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	if err := WriteNote(a, from, opts); err != nil {
+		return "", err
+	}
+	if err := WriteNote(b, to, opts); err != nil {
+		return "", err
+	}
+	return textDiff(a.String(), b.String(), fromLabel, toLabel)
+}
+
+func textDiff(a, b, fromLabel, toLabel string) (string, error) {
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: fromLabel,
+		ToFile:   toLabel,
+		Context:  diffContextLines,
+	}
+	return difflib.GetUnifiedDiffString(diff)
+}
+
+func colorDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	buf := new(bytes.Buffer)
+	for _, line := range lines {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		}
+		if color == "" {
+			buf.WriteString(line)
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		buf.WriteString(color + text + colorReset)
+		if len(text) != len(line) {
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String()
+}
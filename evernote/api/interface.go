@@ -31,16 +31,43 @@ type Notestore interface {
 	UpdateNotebook(apiKey string, notebook *types.Notebook) (r int32, err error)
 	// GetNotebook returns a notebook from the notestore.
 	GetNotebook(authenticationToken string, guid types.GUID) (r *types.Notebook, err error)
+	// ExpungeNotebook permanently removes the notebook and all the notes in it.
+	ExpungeNotebook(apiKey string, guid types.GUID) (int32, error)
+	// FindNoteCounts returns the number of notes in each notebook and tag
+	// matching the filter.
+	FindNoteCounts(apiKey string, filter *notestore.NoteFilter, withTrash bool) (*notestore.NoteCollectionCounts, error)
 	// CreateNote creates a new note on the server.
 	CreateNote(apiKey string, note *types.Note) (r *types.Note, err error)
+	// CopyNote copies the note to the notebook.
+	CopyNote(apiKey string, noteGUID types.GUID, toNotebookGUID types.GUID) (*types.Note, error)
+	// GetNoteTagNames returns the names of the tags assigned to the note.
+	GetNoteTagNames(apiKey string, guid types.GUID) ([]string, error)
+	// ListTags returns all the tags in the user's account.
+	ListTags(apiKey string) ([]*types.Tag, error)
+	// ListSearches returns the user's saved searches.
+	ListSearches(apiKey string) ([]*types.SavedSearch, error)
+	// CreateSearch creates a new saved search.
+	CreateSearch(apiKey string, search *types.SavedSearch) (*types.SavedSearch, error)
+	// ExpungeSearch permanently removes the saved search.
+	ExpungeSearch(apiKey string, guid types.GUID) (int32, error)
 	// DeleteNote moves a note to the trash can.
 	DeleteNote(apiKey string, guid types.GUID) (int32, error)
+	// ExpungeNote permanently removes a note from the user's account.
+	ExpungeNote(apiKey string, guid types.GUID) (int32, error)
+	// ExpungeInactiveNotes permanently removes all the notes in the trash can.
+	ExpungeInactiveNotes(apiKey string) (int32, error)
 	// UpdateNote submits a set of changes to a note to the service.  The provided data
 	// must include the note's guid field for identification. The note's title must also be set.
 	UpdateNote(authenticationToken string, note *types.Note) (r *types.Note, err error)
 	// FindNotes searches the server and returns notes matching the filter.
 	FindNotes(apiKey string, filter *notestore.NoteFilter, offset int32, maxNumNotes int32) (r *notestore.NoteList, err error)
+	// GetNote returns the note with the GUID.
+	GetNote(authenticationToken string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error)
 	// GetNoteContent returns XHTML contents of the note with the provided GUID.
 	// If the Note is found in a public notebook, the authenticationToken will be ignored (so it could be an empty string).
 	GetNoteContent(authenticationToken string, guid types.GUID) (r string, err error)
+	// ListNoteVersions returns a list of the prior versions of the note saved by the service.
+	ListNoteVersions(authenticationToken string, noteGuid types.GUID) (r []*notestore.NoteVersionId, err error)
+	// GetNoteVersion returns a past version of the note, as identified by its update sequence number.
+	GetNoteVersion(authenticationToken string, noteGuid types.GUID, updateSequenceNum int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error)
 }
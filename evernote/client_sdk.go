@@ -20,6 +20,8 @@ type Client struct {
 	ns         clinote.NotestoreClient
 	evernote   *ec.EvernoteClient
 	evernoteNS *notestore.NoteStoreClient
+	// noteStoreURL is the URL of the user's notestore.
+	noteStoreURL string
 }
 
 // Close shuts down the client.
@@ -45,7 +47,7 @@ func (c *Client) GetNoteStore() (clinote.NotestoreClient, error) {
 	if c.apiToken == "" {
 		return nil, ErrNotLoggedIn
 	}
-	ns, err := c.evernote.GetNoteStore(c.apiToken)
+	ns, err := c.newNoteStore()
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +57,32 @@ func (c *Client) GetNoteStore() (clinote.NotestoreClient, error) {
 	return store, nil
 }
 
+// NewNoteStore returns a new notestore client for the user. The clients
+// aren't safe for concurrent use so each goroutine needs its own client.
+func (c *Client) NewNoteStore() (clinote.NotestoreClient, error) {
+	if c.apiToken == "" {
+		return nil, ErrNotLoggedIn
+	}
+	ns, err := c.newNoteStore()
+	if err != nil {
+		return nil, err
+	}
+	return &Notestore{apiToken: c.apiToken, evernoteNS: ns}, nil
+}
+
+func (c *Client) newNoteStore() (*notestore.NoteStoreClient, error) {
+	if c.noteStoreURL == "" {
+		us, err := c.evernote.GetUserStore()
+		if err != nil {
+			return nil, err
+		}
+		if c.noteStoreURL, err = us.GetNoteStoreUrl(c.apiToken); err != nil {
+			return nil, err
+		}
+	}
+	return c.evernote.GetNoteStoreWithURL(c.noteStoreURL)
+}
+
 // GetAuthorizedToken gets the authorized token from the server.
 func (c *Client) GetAuthorizedToken(tmpToken *oauth.RequestToken, verifier string) (string, error) {
 	token, err := c.evernote.GetAuthorizedToken(tmpToken, verifier)
@@ -105,3 +133,17 @@ func NewClient(cfg clinote.Configuration) *Client {
 
 	return client
 }
+
+// NewClientWithCredential creates a new Evernote client that uses the
+// credential instead of the active credential in the settings.
+func NewClientWithCredential(cfg clinote.Configuration, cred *clinote.Credential) *Client {
+	env := ec.PRODUCTION
+	if cred.CredType == clinote.EvernoteSandboxCredential {
+		env = ec.SANDBOX
+	}
+	return &Client{
+		Config:   cfg,
+		apiToken: cred.Secret,
+		evernote: ec.NewClient(apiConsumer, apiSecret, env),
+	}
+}
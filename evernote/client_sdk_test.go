@@ -105,6 +105,22 @@ func (m *mockStore) GetNoteRecoveryPoint() (*clinote.Note, error) {
 	panic("not implemented")
 }
 
+func (m *mockStore) GetLinkIndex() (*clinote.LinkIndex, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) StoreLinkIndex(*clinote.LinkIndex) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) AddRecoveryEntry(*clinote.RecoveryEntry) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetRecoveryJournal() ([]*clinote.RecoveryEntry, error) {
+	panic("not implemented")
+}
+
 func (m *mockStore) SaveSearch([]*clinote.Note) error {
 	panic("not implemented")
 }
@@ -113,6 +129,14 @@ func (m *mockStore) GetSearch() ([]*clinote.Note, error) {
 	panic("not implemented")
 }
 
+func (m *mockStore) SaveTrashSearch([]*clinote.Note) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetTrashSearch() ([]*clinote.Note, error) {
+	panic("not implemented")
+}
+
 func (m *mockStore) GetNotebookCache() (*clinote.NotebookCacheList, error) {
 	panic("not implemented")
 }
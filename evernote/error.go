@@ -18,4 +18,7 @@ var (
 	ErrNoGUIDSet = errors.New("no GUID set.")
 	// ErrNoTitleSet is returned if the not does not have a title.
 	ErrNoTitleSet = errors.New("no title set")
+	// ErrNoLinkInfo is returned if the user ID and shard can't be read
+	// from the API token.
+	ErrNoLinkInfo = errors.New("no user ID or shard in the API token")
 )
@@ -18,6 +18,7 @@
 package evernote
 
 import (
+	"crypto/md5"
 	"errors"
 	"sync"
 
@@ -46,6 +47,12 @@ func convert(note *types.Note) *clinote.Note {
 	n.Notebook.GUID = notebookGUID
 	n.Created = int64(note.GetCreated())
 	n.Updated = int64(note.GetUpdated())
+	n.Deleted = note.IsSetActive() && !note.GetActive()
+	n.Tags = note.GetTagNames()
+	n.Attributes = convertAttributes(note.GetAttributes())
+	if len(note.GetResources()) > 0 {
+		n.Resources = convertResources(note.GetResources())
+	}
 	return n
 }
 
@@ -60,3 +67,102 @@ func convertNotes(notes []*types.Note) []*clinote.Note {
 	}
 	return a
 }
+
+func convertAttributes(a *types.NoteAttributes) *clinote.NoteAttributes {
+	if a == nil {
+		return nil
+	}
+	return &clinote.NoteAttributes{
+		Author:            a.GetAuthor(),
+		Source:            a.GetSource(),
+		SourceURL:         a.GetSourceURL(),
+		SourceApplication: a.GetSourceApplication(),
+		PlaceName:         a.GetPlaceName(),
+		SubjectDate:       int64(a.GetSubjectDate()),
+		ReminderOrder:     a.GetReminderOrder(),
+		ReminderTime:      int64(a.GetReminderTime()),
+		ReminderDoneTime:  int64(a.GetReminderDoneTime()),
+		Latitude:          a.Latitude,
+		Longitude:         a.Longitude,
+		Altitude:          a.Altitude,
+	}
+}
+
+func createAttributes(a *clinote.NoteAttributes) *types.NoteAttributes {
+	attrs := types.NewNoteAttributes()
+	attrs.Author = optionalString(a.Author)
+	attrs.Source = optionalString(a.Source)
+	attrs.SourceURL = optionalString(a.SourceURL)
+	attrs.SourceApplication = optionalString(a.SourceApplication)
+	attrs.PlaceName = optionalString(a.PlaceName)
+	attrs.SubjectDate = optionalTimestamp(a.SubjectDate)
+	attrs.ReminderTime = optionalTimestamp(a.ReminderTime)
+	attrs.ReminderDoneTime = optionalTimestamp(a.ReminderDoneTime)
+	if a.ReminderOrder != 0 {
+		order := a.ReminderOrder
+		attrs.ReminderOrder = &order
+	}
+	attrs.Latitude = a.Latitude
+	attrs.Longitude = a.Longitude
+	attrs.Altitude = a.Altitude
+	return attrs
+}
+
+func convertResources(rs []*types.Resource) []*clinote.Resource {
+	a := make([]*clinote.Resource, 0, len(rs))
+	for _, r := range rs {
+		res := &clinote.Resource{Mime: r.GetMime()}
+		if r.Data != nil {
+			res.Data = r.Data.Body
+		}
+		if attrs := r.GetAttributes(); attrs != nil {
+			res.FileName = attrs.GetFileName()
+			res.SourceURL = attrs.GetSourceURL()
+			res.Attachment = attrs.GetAttachment()
+		}
+		a = append(a, res)
+	}
+	return a
+}
+
+// createResources returns the resources to upload with a new note. The
+// hash of the data is used by en-media elements in the note's content
+// to reference the resource.
+func createResources(rs []*clinote.Resource) []*types.Resource {
+	a := make([]*types.Resource, len(rs))
+	for i, r := range rs {
+		hash := md5.Sum(r.Data)
+		size := int32(len(r.Data))
+		mime := r.Mime
+		res := types.NewResource()
+		res.Mime = &mime
+		res.Data = &types.Data{Body: r.Data, BodyHash: hash[:], Size: &size}
+		if r.FileName != "" || r.SourceURL != "" || r.Attachment {
+			attrs := types.NewResourceAttributes()
+			attrs.FileName = optionalString(r.FileName)
+			attrs.SourceURL = optionalString(r.SourceURL)
+			if r.Attachment {
+				attachment := true
+				attrs.Attachment = &attachment
+			}
+			res.Attributes = attrs
+		}
+		a[i] = res
+	}
+	return a
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalTimestamp(t int64) *types.Timestamp {
+	if t == 0 {
+		return nil
+	}
+	ts := types.Timestamp(t)
+	return &ts
+}
@@ -30,7 +30,12 @@ var cachedNotebooks map[types.GUID]*types.Notebook
 func convertNotebooks(bs []*types.Notebook) []*clinote.Notebook {
 	a := make([]*clinote.Notebook, len(bs), len(bs))
 	for i, b := range bs {
-		a[i] = &clinote.Notebook{GUID: string(b.GetGUID()), Name: b.GetName(), Stack: b.GetStack()}
+		a[i] = &clinote.Notebook{
+			GUID:    string(b.GetGUID()),
+			Name:    b.GetName(),
+			Stack:   b.GetStack(),
+			Default: b.GetDefaultNotebook(),
+		}
 	}
 	return a
 }
@@ -39,6 +44,13 @@ func transferNotebookData(src *clinote.Notebook, dst *types.Notebook) {
 	dst.Name = &(src.Name)
 	if src.Stack != "" {
 		dst.Stack = &(src.Stack)
+	} else {
+		dst.Stack = nil
+	}
+	// A notebook can't be unset as the default notebook, another
+	// notebook has to be made the default notebook instead.
+	if src.Default {
+		dst.DefaultNotebook = &(src.Default)
 	}
 }
 
@@ -1,6 +1,9 @@
 package evernote
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TcM1911/clinote"
@@ -21,9 +24,34 @@ func (s *Notestore) GetAllNotebooks() ([]*clinote.Notebook, error) {
 	if err != nil {
 		return nil, err
 	}
+	for _, b := range bs {
+		if b.IsSetGUID() {
+			cacheNotebook(b)
+		}
+	}
 	return convertNotebooks(bs), nil
 }
 
+// ExpungeNotebook permanently removes the notebook and the notes in it.
+func (s *Notestore) ExpungeNotebook(guid string) error {
+	_, err := s.evernoteNS.ExpungeNotebook(s.apiToken, types.GUID(guid))
+	return err
+}
+
+// GetNoteCounts returns the number of notes in each notebook. The map's
+// keys are the notebooks' GUIDs.
+func (s *Notestore) GetNoteCounts() (map[string]int, error) {
+	r, err := s.evernoteNS.FindNoteCounts(s.apiToken, notestore.NewNoteFilter(), false)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(r.GetNotebookCounts()))
+	for guid, count := range r.GetNotebookCounts() {
+		counts[string(guid)] = int(count)
+	}
+	return counts, nil
+}
+
 // UpdateNotebook updates the notebook on the server.
 func (s *Notestore) UpdateNotebook(b *clinote.Notebook) error {
 	nb, err := getCachedNotebook(types.GUID(b.GUID))
@@ -40,8 +68,14 @@ func (s *Notestore) CreateNotebook(b *clinote.Notebook, defaultNotebook bool) er
 	nb := types.NewNotebook()
 	nb.DefaultNotebook = &defaultNotebook
 	transferNotebookData(b, nb)
-	_, err := s.evernoteNS.CreateNotebook(s.apiToken, nb)
-	return err
+	r, err := s.evernoteNS.CreateNotebook(s.apiToken, nb)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		b.GUID = string(r.GetGUID())
+	}
+	return nil
 }
 
 // GetNotebook returns the notebook with the specific GUID.
@@ -50,6 +84,9 @@ func (s *Notestore) GetNotebook(guid string) (*clinote.Notebook, error) {
 	if err != nil {
 		return nil, err
 	}
+	if nb.IsSetGUID() {
+		cacheNotebook(nb)
+	}
 	return convertNotebooks([]*types.Notebook{nb})[0], nil
 }
 
@@ -66,16 +103,171 @@ func (s *Notestore) CreateNote(n *clinote.Note) error {
 		guid := string(n.Notebook.GUID)
 		note.NotebookGuid = &guid
 	}
-	_, err := s.evernoteNS.CreateNote(s.apiToken, note)
+	if len(n.Tags) > 0 {
+		note.TagNames = n.Tags
+	}
+	if n.Created != 0 {
+		created := types.Timestamp(n.Created)
+		note.Created = &created
+	}
+	if n.Attributes != nil {
+		note.Attributes = createAttributes(n.Attributes)
+	}
+	if len(n.Resources) > 0 {
+		note.Resources = createResources(n.Resources)
+	}
+	r, err := s.evernoteNS.CreateNote(s.apiToken, note)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		n.GUID = string(r.GetGUID())
+	}
+	return nil
+}
+
+// CopyNote copies the note to the notebook.
+func (s *Notestore) CopyNote(guid, notebookGUID string) error {
+	_, err := s.evernoteNS.CopyNote(s.apiToken, types.GUID(guid), types.GUID(notebookGUID))
 	return err
 }
 
+// GetNoteTags returns the names of the tags assigned to the note.
+func (s *Notestore) GetNoteTags(guid string) ([]string, error) {
+	return s.evernoteNS.GetNoteTagNames(s.apiToken, types.GUID(guid))
+}
+
+// UpdateNoteTags replaces the note's tags with the note's Tags.
+func (s *Notestore) UpdateNoteTags(note *clinote.Note) error {
+	if note.GUID == "" {
+		return ErrNoGUIDSet
+	}
+	if note.Title == "" {
+		return ErrNoTitleSet
+	}
+	n := types.NewNote()
+	n.Title = &note.Title
+	guid := types.GUID(note.GUID)
+	n.GUID = &guid
+	if len(note.Tags) > 0 {
+		n.TagNames = note.Tags
+	} else {
+		// An empty list of tag GUIDs removes all the tags.
+		n.TagGuids = []string{}
+	}
+	_, err := s.evernoteNS.UpdateNote(s.apiToken, n)
+	return err
+}
+
+// GetSavedSearches returns the user's saved searches.
+func (s *Notestore) GetSavedSearches() ([]*clinote.SavedSearch, error) {
+	searches, err := s.evernoteNS.ListSearches(s.apiToken)
+	if err != nil {
+		return nil, err
+	}
+	a := make([]*clinote.SavedSearch, len(searches))
+	for i, search := range searches {
+		a[i] = &clinote.SavedSearch{
+			GUID:  string(search.GetGUID()),
+			Name:  search.GetName(),
+			Query: search.GetQuery(),
+		}
+	}
+	return a, nil
+}
+
+// CreateSavedSearch creates a new saved search.
+func (s *Notestore) CreateSavedSearch(search *clinote.SavedSearch) error {
+	ss := types.NewSavedSearch()
+	ss.Name = &search.Name
+	ss.Query = &search.Query
+	created, err := s.evernoteNS.CreateSearch(s.apiToken, ss)
+	if err != nil {
+		return err
+	}
+	search.GUID = string(created.GetGUID())
+	return nil
+}
+
+// DeleteSavedSearch permanently removes the saved search.
+func (s *Notestore) DeleteSavedSearch(guid string) error {
+	_, err := s.evernoteNS.ExpungeSearch(s.apiToken, types.GUID(guid))
+	return err
+}
+
+// NoteLink returns the evernote:/// link to the note. The user ID and the
+// shard are read from the API token, e.g. "S=s1:U=8f219:E=...".
+func (s *Notestore) NoteLink(guid string) (string, error) {
+	var shard, user string
+	for _, field := range strings.Split(s.apiToken, ":") {
+		switch {
+		case strings.HasPrefix(field, "S="):
+			shard = field[2:]
+		case strings.HasPrefix(field, "U="):
+			user = field[2:]
+		}
+	}
+	id, err := strconv.ParseUint(user, 16, 64)
+	if shard == "" || err != nil {
+		return "", ErrNoLinkInfo
+	}
+	return fmt.Sprintf("evernote:///view/%d/%s/%s/%s/", id, shard, guid, guid), nil
+}
+
+// ExportNote returns the note with its raw content, attributes, tags and
+// resources.
+func (s *Notestore) ExportNote(guid string) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNote(s.apiToken, types.GUID(guid), true, true, false, false)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.evernoteNS.GetNoteTagNames(s.apiToken, types.GUID(guid))
+	if err != nil {
+		return nil, err
+	}
+	note := convert(n)
+	note.Body = n.GetContent()
+	note.Tags = tags
+	note.Resources = convertResources(n.GetResources())
+	return note, nil
+}
+
 // DeleteNote removes a note from the user's notebook.
 func (s *Notestore) DeleteNote(guid string) error {
 	_, err := s.evernoteNS.DeleteNote(s.apiToken, types.GUID(guid))
 	return err
 }
 
+// RestoreNote moves a note from the trash can back to its notebook.
+func (s *Notestore) RestoreNote(note *clinote.Note) error {
+	if note.GUID == "" {
+		return ErrNoGUIDSet
+	}
+	if note.Title == "" {
+		return ErrNoTitleSet
+	}
+	n := types.NewNote()
+	n.Title = &note.Title
+	guid := types.GUID(note.GUID)
+	n.GUID = &guid
+	active := true
+	n.Active = &active
+	_, err := s.evernoteNS.UpdateNote(s.apiToken, n)
+	return err
+}
+
+// ExpungeNote permanently removes a note.
+func (s *Notestore) ExpungeNote(guid string) error {
+	_, err := s.evernoteNS.ExpungeNote(s.apiToken, types.GUID(guid))
+	return err
+}
+
+// EmptyTrash permanently removes all the notes in the trash can.
+func (s *Notestore) EmptyTrash() error {
+	_, err := s.evernoteNS.ExpungeInactiveNotes(s.apiToken)
+	return err
+}
+
 // UpdateNote update's the note.
 func (s *Notestore) UpdateNote(note *clinote.Note) error {
 	if note.GUID == "" {
@@ -102,7 +294,52 @@ func (s *Notestore) FindNotes(filter *clinote.NoteFilter, offset, count int) ([]
 	if err != nil {
 		return nil, err
 	}
-	return convertNotes(r.GetNotes()), nil
+	notes := convertNotes(r.GetNotes())
+	if err = s.setTagNames(r.GetNotes(), notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// setTagNames sets the tag names of the converted notes. The search result
+// only has the tags' GUIDs so the names are looked up with one request for
+// all the notes.
+func (s *Notestore) setTagNames(found []*types.Note, notes []*clinote.Note) error {
+	tagged := false
+	for _, n := range found {
+		if len(n.GetTagGuids()) > 0 {
+			tagged = true
+			break
+		}
+	}
+	if !tagged {
+		return nil
+	}
+	tags, err := s.evernoteNS.ListTags(s.apiToken)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(tags))
+	for _, t := range tags {
+		names[string(t.GetGUID())] = t.GetName()
+	}
+	for i, n := range found {
+		for _, guid := range n.GetTagGuids() {
+			if name, ok := names[guid]; ok {
+				notes[i].Tags = append(notes[i].Tags, name)
+			}
+		}
+	}
+	return nil
+}
+
+// GetNote gets the note's metadata from the notestore.
+func (s *Notestore) GetNote(guid string) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNote(s.apiToken, types.GUID(guid), false, false, false, false)
+	if err != nil {
+		return nil, err
+	}
+	return convert(n), nil
 }
 
 // GetNoteContent gets the note's content from the notestore.
@@ -110,6 +347,36 @@ func (s *Notestore) GetNoteContent(guid string) (string, error) {
 	return s.evernoteNS.GetNoteContent(s.apiToken, types.GUID(guid))
 }
 
+// GetNoteVersions returns the versions of the note saved by the server.
+func (s *Notestore) GetNoteVersions(guid string) ([]*clinote.NoteVersion, error) {
+	vs, err := s.evernoteNS.ListNoteVersions(s.apiToken, types.GUID(guid))
+	if err != nil {
+		return nil, err
+	}
+	a := make([]*clinote.NoteVersion, len(vs))
+	for i, v := range vs {
+		a[i] = &clinote.NoteVersion{
+			USN:     int(v.GetUpdateSequenceNum()),
+			Title:   v.GetTitle(),
+			Updated: int64(v.GetUpdated()),
+			Saved:   int64(v.GetSaved()),
+		}
+	}
+	return a, nil
+}
+
+// GetNoteVersion returns the note as it was at the version. The note's
+// body holds the raw content of the version.
+func (s *Notestore) GetNoteVersion(guid string, usn int) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNoteVersion(s.apiToken, types.GUID(guid), int32(usn), false, false, false)
+	if err != nil {
+		return nil, err
+	}
+	note := convert(n)
+	note.Body = n.GetContent()
+	return note, nil
+}
+
 func createFilter(filter *clinote.NoteFilter) *notestore.NoteFilter {
 	searchFilter := notestore.NewNoteFilter()
 	if filter.NotebookGUID != "" {
@@ -119,5 +386,8 @@ func createFilter(filter *clinote.NoteFilter) *notestore.NoteFilter {
 	if filter.Words != "" {
 		searchFilter.Words = &(filter.Words)
 	}
+	if filter.Inactive {
+		searchFilter.Inactive = &(filter.Inactive)
+	}
 	return searchFilter
 }
@@ -1,6 +1,7 @@
 package evernote
 
 import (
+	"crypto/md5"
 	"errors"
 	"testing"
 
@@ -39,6 +40,7 @@ func TestUpdateNotebookSDK(t *testing.T) {
 	token := "token"
 	guid := "guid"
 	t.Run("Return ErrNoNotebookCached", func(t *testing.T) {
+		cachedNotebooks = nil
 		ns := &Notestore{apiToken: token, evernoteNS: nil}
 		err := ns.UpdateNotebook(&clinote.Notebook{})
 		assert.Equal(clinote.ErrNoNotebookCached, err, "No cached notebooks")
@@ -104,6 +106,7 @@ func TestCreateNoteSDK(t *testing.T) {
 		Notebook: &clinote.Notebook{GUID: notebookGUID, Name: "Name"},
 		Title:    "Note title",
 		Body:     "Note body",
+		Tags:     []string{"ci", "report"},
 	}
 	ns := &Notestore{
 		apiToken:   token,
@@ -114,6 +117,15 @@ func TestCreateNoteSDK(t *testing.T) {
 	assert.Equal(&note.Body, saved.Content, "Body not saved")
 	assert.Equal(&note.Title, saved.Title, "Title not saved")
 	assert.Equal(notebookGUID, *saved.NotebookGuid, "Notebook GUID doesn't match")
+	assert.Equal(note.Tags, saved.TagNames, "Tags not saved")
+
+	guid := types.GUID("New GUID")
+	ns.evernoteNS = &mockAPI{createNote: func(k string, n *types.Note) (*types.Note, error) {
+		n.GUID = &guid
+		return n, nil
+	}}
+	assert.NoError(ns.CreateNote(note), "Should not return an error")
+	assert.Equal(string(guid), note.GUID, "GUID of the new note should be set")
 }
 
 func TestDeleteNoteSDK(t *testing.T) {
@@ -212,6 +224,29 @@ func TestFindNotes(t *testing.T) {
 		assert.Equal(string(GUID), notes[0].GUID, "Wrong GUID")
 	})
 
+	t.Run("tag names", func(t *testing.T) {
+		tagGUID := types.GUID("Tag GUID")
+		tagName := "work"
+		tagged := types.NewNote()
+		tagged.GUID = &GUID
+		tagged.Title = &title
+		tagged.TagGuids = []string{string(tagGUID), "Unknown GUID"}
+		ns := &Notestore{
+			apiToken: token,
+			evernoteNS: &mockAPI{
+				findNote: func(string, *notestore.NoteFilter, int32, int32) (*notestore.NoteList, error) {
+					return &notestore.NoteList{Notes: []*types.Note{tagged}}, nil
+				},
+				listTags: func(string) ([]*types.Tag, error) {
+					return []*types.Tag{&types.Tag{GUID: &tagGUID, Name: &tagName}}, nil
+				},
+			},
+		}
+		notes, err := ns.FindNotes(&clinote.NoteFilter{}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{tagName}, notes[0].Tags, "Tag names should be set")
+	})
+
 	t.Run("return error", func(t *testing.T) {
 		filter := &clinote.NoteFilter{NotebookGUID: "Book GUID"}
 		expectedErr := errors.New("expected")
@@ -238,6 +273,328 @@ func TestGetNoteContentSDK(t *testing.T) {
 	assert.Equal(expectedContent, content, "Wrong content")
 }
 
+func TestNoteVersionsSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	t.Run("list versions", func(t *testing.T) {
+		vs := []*notestore.NoteVersionId{
+			&notestore.NoteVersionId{UpdateSequenceNum: 12, Title: "Old title", Updated: 1000, Saved: 2000},
+		}
+		var requested types.GUID
+		ns := &Notestore{
+			apiToken: token,
+			evernoteNS: &mockAPI{listVersions: func(k string, g types.GUID) ([]*notestore.NoteVersionId, error) {
+				requested = g
+				return vs, nil
+			}},
+		}
+		versions, err := ns.GetNoteVersions("GUID")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(types.GUID("GUID"), requested, "Wrong note requested")
+		assert.Equal([]*clinote.NoteVersion{&clinote.NoteVersion{USN: 12, Title: "Old title", Updated: 1000, Saved: 2000}}, versions)
+	})
+	t.Run("return error from list versions", func(t *testing.T) {
+		ns := &Notestore{
+			apiToken:   token,
+			evernoteNS: &mockAPI{listVersions: func(string, types.GUID) ([]*notestore.NoteVersionId, error) { return nil, errExpected }},
+		}
+		_, err := ns.GetNoteVersions("GUID")
+		assert.Equal(errExpected, err, "Wrong error returned")
+	})
+	t.Run("get version", func(t *testing.T) {
+		title := "Old title"
+		content := "<en-note>Old content</en-note>"
+		guid := types.GUID("GUID")
+		var usn int32
+		ns := &Notestore{
+			apiToken: token,
+			evernoteNS: &mockAPI{getNoteVersion: func(k string, g types.GUID, u int32) (*types.Note, error) {
+				usn = u
+				return &types.Note{GUID: &guid, Title: &title, Content: &content}, nil
+			}},
+		}
+		n, err := ns.GetNoteVersion("GUID", 12)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(int32(12), usn, "Wrong version requested")
+		assert.Equal(title, n.Title, "Wrong title")
+		assert.Equal(content, n.Body, "Content should be in the body")
+	})
+}
+
+func TestTrashSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	t.Run("restore note", func(t *testing.T) {
+		var updated *types.Note
+		ns := &Notestore{
+			apiToken: token,
+			evernoteNS: &mockAPI{updateNote: func(k string, n *types.Note) (*types.Note, error) {
+				updated = n
+				return n, nil
+			}},
+		}
+		err := ns.RestoreNote(&clinote.Note{Title: "Title", GUID: "GUID"})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(types.GUID("GUID"), updated.GetGUID(), "Wrong note restored")
+		assert.True(updated.GetActive(), "Note should be active")
+	})
+	t.Run("restore note without guid", func(t *testing.T) {
+		ns := &Notestore{apiToken: token, evernoteNS: &mockAPI{}}
+		err := ns.RestoreNote(&clinote.Note{Title: "Title"})
+		assert.Equal(ErrNoGUIDSet, err, "Wrong error returned")
+	})
+	t.Run("expunge note", func(t *testing.T) {
+		var expunged types.GUID
+		ns := &Notestore{
+			apiToken: token,
+			evernoteNS: &mockAPI{expungeNote: func(k string, g types.GUID) (int32, error) {
+				expunged = g
+				return 0, nil
+			}},
+		}
+		assert.NoError(ns.ExpungeNote("GUID"), "Should not return an error")
+		assert.Equal(types.GUID("GUID"), expunged, "Wrong note expunged")
+	})
+	t.Run("empty trash", func(t *testing.T) {
+		ns := &Notestore{
+			apiToken:   token,
+			evernoteNS: &mockAPI{expungeTrash: func(string) (int32, error) { return 0, errExpected }},
+		}
+		assert.Equal(errExpected, ns.EmptyTrash(), "Wrong error returned")
+	})
+	t.Run("filter for trash", func(t *testing.T) {
+		f := createFilter(&clinote.NoteFilter{Inactive: true})
+		assert.True(f.GetInactive(), "Filter should search the trash")
+	})
+	t.Run("convert deleted note", func(t *testing.T) {
+		active := false
+		n := convert(&types.Note{Active: &active})
+		assert.True(n.Deleted, "Note should be marked as deleted")
+	})
+}
+
+func TestNotebookOperationsSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	t.Run("cache listed notebooks", func(t *testing.T) {
+		cachedNotebooks = nil
+		guid := types.GUID("listed")
+		name := "Listed"
+		api := &mockAPI{listNotebooks: func(string) ([]*types.Notebook, error) {
+			return []*types.Notebook{&types.Notebook{GUID: &guid, Name: &name}}, nil
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		_, err := ns.GetAllNotebooks()
+		assert.NoError(err, "Should not return an error")
+		nb, err := getCachedNotebook(guid)
+		assert.NoError(err, "Notebook should be cached")
+		assert.Equal(name, nb.GetName(), "Wrong notebook cached")
+	})
+	t.Run("remove stack", func(t *testing.T) {
+		guid := types.GUID("stacked")
+		name := "Stacked"
+		stack := "Stack"
+		cacheNotebook(&types.Notebook{GUID: &guid, Name: &name, Stack: &stack})
+		var saved *types.Notebook
+		api := &mockAPI{updateNotebook: func(k string, nb *types.Notebook) (int32, error) { saved = nb; return 0, nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		err := ns.UpdateNotebook(&clinote.Notebook{GUID: string(guid), Name: name})
+		assert.NoError(err, "Should not return an error")
+		assert.False(saved.IsSetStack(), "Stack should be removed")
+	})
+	t.Run("set default notebook", func(t *testing.T) {
+		guid := types.GUID("default")
+		name := "Default"
+		isDefault := true
+		nbs := []*types.Notebook{&types.Notebook{GUID: &guid, Name: &name, DefaultNotebook: &isDefault}}
+		api := &mockAPI{listNotebooks: func(string) ([]*types.Notebook, error) { return nbs, nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		books, err := ns.GetAllNotebooks()
+		assert.NoError(err, "Should not return an error")
+		assert.True(books[0].Default, "Notebook should be the default")
+
+		var saved *types.Notebook
+		api.updateNotebook = func(k string, nb *types.Notebook) (int32, error) { saved = nb; return 0, nil }
+		other := types.GUID("other")
+		cacheNotebook(&types.Notebook{GUID: &other, Name: &name})
+		err = ns.UpdateNotebook(&clinote.Notebook{GUID: string(other), Name: name, Default: true})
+		assert.NoError(err, "Should not return an error")
+		assert.True(saved.GetDefaultNotebook(), "Notebook should be set as default")
+	})
+	t.Run("expunge notebook", func(t *testing.T) {
+		var expunged types.GUID
+		api := &mockAPI{expungeBook: func(k string, g types.GUID) (int32, error) { expunged = g; return 0, nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		assert.NoError(ns.ExpungeNotebook("GUID"), "Should not return an error")
+		assert.Equal(types.GUID("GUID"), expunged, "Wrong notebook expunged")
+	})
+	t.Run("note counts", func(t *testing.T) {
+		api := &mockAPI{noteCounts: func(string, *notestore.NoteFilter, bool) (*notestore.NoteCollectionCounts, error) {
+			return &notestore.NoteCollectionCounts{NotebookCounts: map[types.GUID]int32{"GUID": 4}}, nil
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		counts, err := ns.GetNoteCounts()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(map[string]int{"GUID": 4}, counts, "Wrong counts")
+	})
+	t.Run("note counts error", func(t *testing.T) {
+		api := &mockAPI{noteCounts: func(string, *notestore.NoteFilter, bool) (*notestore.NoteCollectionCounts, error) {
+			return nil, errExpected
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		_, err := ns.GetNoteCounts()
+		assert.Equal(errExpected, err, "Wrong error returned")
+	})
+}
+
+func TestGetNoteSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("GUID")
+	title := "Title"
+	var requested types.GUID
+	ns := &Notestore{
+		apiToken: "token",
+		evernoteNS: &mockAPI{getNote: func(k string, g types.GUID) (*types.Note, error) {
+			requested = g
+			return &types.Note{GUID: &guid, Title: &title}, nil
+		}},
+	}
+	n, err := ns.GetNote("GUID")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(guid, requested, "Wrong note requested")
+	assert.Equal(title, n.Title, "Wrong title")
+}
+
+func TestNoteTagsSDK(t *testing.T) {
+	assert := assert.New(t)
+	var saved *types.Note
+	api := &mockAPI{
+		getTagNames: func(k string, g types.GUID) ([]string, error) { return []string{"q3"}, nil },
+		updateNote:  func(k string, n *types.Note) (*types.Note, error) { saved = n; return n, nil },
+	}
+	ns := &Notestore{apiToken: "token", evernoteNS: api}
+	tags, err := ns.GetNoteTags("GUID")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]string{"q3"}, tags, "Wrong tags")
+
+	assert.NoError(ns.UpdateNoteTags(&clinote.Note{GUID: "GUID", Title: "Title", Tags: []string{"a", "b"}}))
+	assert.Equal([]string{"a", "b"}, saved.TagNames, "Tags not saved")
+	assert.Nil(saved.TagGuids, "Tag GUIDs should not be set")
+
+	assert.NoError(ns.UpdateNoteTags(&clinote.Note{GUID: "GUID", Title: "Title"}))
+	assert.Nil(saved.TagNames, "Tag names should not be set")
+	assert.Equal([]string{}, saved.TagGuids, "All tags should be removed")
+
+	assert.Equal(ErrNoGUIDSet, ns.UpdateNoteTags(&clinote.Note{Title: "Title"}))
+}
+
+func TestNoteLinkSDK(t *testing.T) {
+	assert := assert.New(t)
+	ns := &Notestore{apiToken: "S=s1:U=8f219:E=154308dc4e6:C=14dd9ab4c58:P=1cd:A=en-devtoken:V=2:H=abc"}
+	link, err := ns.NoteLink("GUID")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("evernote:///view/586265/s1/GUID/GUID/", link, "Wrong link")
+
+	ns = &Notestore{apiToken: "token"}
+	_, err = ns.NoteLink("GUID")
+	assert.Equal(ErrNoLinkInfo, err, "Wrong error")
+}
+
+func TestSavedSearchSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("GUID")
+	name, query := "Todos", "tag:todo"
+	var expunged types.GUID
+	api := &mockAPI{
+		listSearches: func(k string) ([]*types.SavedSearch, error) {
+			return []*types.SavedSearch{&types.SavedSearch{GUID: &guid, Name: &name, Query: &query}}, nil
+		},
+		createSearch: func(k string, s *types.SavedSearch) (*types.SavedSearch, error) {
+			s.GUID = &guid
+			return s, nil
+		},
+		expungeSearch: func(k string, g types.GUID) (int32, error) { expunged = g; return 0, nil },
+	}
+	ns := &Notestore{apiToken: "token", evernoteNS: api}
+
+	searches, err := ns.GetSavedSearches()
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*clinote.SavedSearch{&clinote.SavedSearch{GUID: "GUID", Name: name, Query: query}}, searches)
+
+	search := &clinote.SavedSearch{Name: name, Query: query}
+	assert.NoError(ns.CreateSavedSearch(search), "Should not return an error")
+	assert.Equal("GUID", search.GUID, "GUID should be set on the new search")
+
+	assert.NoError(ns.DeleteSavedSearch("GUID"), "Should not return an error")
+	assert.Equal(guid, expunged, "Wrong search removed")
+}
+
+func TestCopyNoteSDK(t *testing.T) {
+	assert := assert.New(t)
+	var note, notebook types.GUID
+	ns := &Notestore{
+		apiToken: "token",
+		evernoteNS: &mockAPI{copyNote: func(k string, n, nb types.GUID) (*types.Note, error) {
+			note, notebook = n, nb
+			return nil, nil
+		}},
+	}
+	assert.NoError(ns.CopyNote("GUID", "NB"), "Should not return an error")
+	assert.Equal(types.GUID("GUID"), note, "Wrong note copied")
+	assert.Equal(types.GUID("NB"), notebook, "Wrong notebook")
+}
+
+func TestExportNoteSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("GUID")
+	title := "Title"
+	content := "<en-note>img</en-note>"
+	author := "joakim"
+	mime := "image/png"
+	fileName := "img.png"
+	created := types.Timestamp(1525132800000)
+	var saved *types.Note
+	api := &mockAPI{
+		getNote: func(k string, g types.GUID) (*types.Note, error) {
+			return &types.Note{
+				GUID:       &guid,
+				Title:      &title,
+				Content:    &content,
+				Created:    &created,
+				Attributes: &types.NoteAttributes{Author: &author},
+				Resources: []*types.Resource{&types.Resource{
+					Mime:       &mime,
+					Data:       &types.Data{Body: []byte("img")},
+					Attributes: &types.ResourceAttributes{FileName: &fileName},
+				}},
+			}, nil
+		},
+		getTagNames: func(k string, g types.GUID) ([]string, error) { return []string{"q3"}, nil },
+		createNote:  func(k string, n *types.Note) (*types.Note, error) { saved = n; return n, nil },
+	}
+	ns := &Notestore{apiToken: "token", evernoteNS: api}
+
+	n, err := ns.ExportNote("GUID")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(content, n.Body, "Content should be exported")
+	assert.Equal([]string{"q3"}, n.Tags, "Tags should be exported")
+	assert.Equal(&clinote.NoteAttributes{Author: author}, n.Attributes, "Attributes should be exported")
+	assert.Equal([]*clinote.Resource{&clinote.Resource{Data: []byte("img"), Mime: mime, FileName: fileName}}, n.Resources)
+
+	n.Notebook = nil
+	assert.NoError(ns.CreateNote(n), "Should not return an error")
+	assert.Equal(created, *saved.Created, "Created time should be kept")
+	assert.Equal(author, saved.Attributes.GetAuthor(), "Attributes should be saved")
+	assert.Equal([]string{"q3"}, saved.TagNames, "Tags should be saved")
+	if assert.Len(saved.Resources, 1, "Resources should be saved") {
+		hash := md5.Sum([]byte("img"))
+		res := saved.Resources[0]
+		assert.Equal(hash[:], res.Data.BodyHash, "Wrong resource hash")
+		assert.Equal(int32(3), res.Data.GetSize(), "Wrong resource size")
+		assert.Equal(fileName, res.Attributes.GetFileName(), "Wrong file name")
+	}
+}
+
 type mockAPI struct {
 	listNotebooks  func(string) ([]*types.Notebook, error)
 	updateNotebook func(string, *types.Notebook) (int32, error)
@@ -247,6 +604,71 @@ type mockAPI struct {
 	updateNote     func(string, *types.Note) (*types.Note, error)
 	findNote       func(string, *notestore.NoteFilter, int32, int32) (*notestore.NoteList, error)
 	getNoteContent func(string, types.GUID) (string, error)
+	listVersions   func(string, types.GUID) ([]*notestore.NoteVersionId, error)
+	getNoteVersion func(string, types.GUID, int32) (*types.Note, error)
+	expungeNote    func(string, types.GUID) (int32, error)
+	expungeTrash   func(string) (int32, error)
+	expungeBook    func(string, types.GUID) (int32, error)
+	noteCounts     func(string, *notestore.NoteFilter, bool) (*notestore.NoteCollectionCounts, error)
+	getNote        func(string, types.GUID) (*types.Note, error)
+	copyNote       func(string, types.GUID, types.GUID) (*types.Note, error)
+	getTagNames    func(string, types.GUID) ([]string, error)
+	listSearches   func(string) ([]*types.SavedSearch, error)
+	listTags       func(string) ([]*types.Tag, error)
+	createSearch   func(string, *types.SavedSearch) (*types.SavedSearch, error)
+	expungeSearch  func(string, types.GUID) (int32, error)
+}
+
+func (a *mockAPI) ListTags(apiKey string) ([]*types.Tag, error) {
+	return a.listTags(apiKey)
+}
+
+func (a *mockAPI) ListSearches(apiKey string) ([]*types.SavedSearch, error) {
+	return a.listSearches(apiKey)
+}
+
+func (a *mockAPI) CreateSearch(apiKey string, search *types.SavedSearch) (*types.SavedSearch, error) {
+	return a.createSearch(apiKey, search)
+}
+
+func (a *mockAPI) ExpungeSearch(apiKey string, guid types.GUID) (int32, error) {
+	return a.expungeSearch(apiKey, guid)
+}
+
+func (a *mockAPI) CopyNote(apiKey string, noteGUID types.GUID, toNotebookGUID types.GUID) (*types.Note, error) {
+	return a.copyNote(apiKey, noteGUID, toNotebookGUID)
+}
+
+func (a *mockAPI) GetNoteTagNames(apiKey string, guid types.GUID) ([]string, error) {
+	return a.getTagNames(apiKey, guid)
+}
+
+func (a *mockAPI) GetNote(authenticationToken string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (*types.Note, error) {
+	return a.getNote(authenticationToken, guid)
+}
+
+func (a *mockAPI) ExpungeNotebook(apiKey string, guid types.GUID) (int32, error) {
+	return a.expungeBook(apiKey, guid)
+}
+
+func (a *mockAPI) FindNoteCounts(apiKey string, filter *notestore.NoteFilter, withTrash bool) (*notestore.NoteCollectionCounts, error) {
+	return a.noteCounts(apiKey, filter, withTrash)
+}
+
+func (a *mockAPI) ExpungeNote(apiKey string, guid types.GUID) (int32, error) {
+	return a.expungeNote(apiKey, guid)
+}
+
+func (a *mockAPI) ExpungeInactiveNotes(apiKey string) (int32, error) {
+	return a.expungeTrash(apiKey)
+}
+
+func (a *mockAPI) ListNoteVersions(apiKey string, guid types.GUID) ([]*notestore.NoteVersionId, error) {
+	return a.listVersions(apiKey, guid)
+}
+
+func (a *mockAPI) GetNoteVersion(apiKey string, guid types.GUID, usn int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (*types.Note, error) {
+	return a.getNoteVersion(apiKey, guid, usn)
 }
 
 func (a *mockAPI) ListNotebooks(apiKey string) (r []*types.Notebook, err error) {
@@ -0,0 +1,435 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// ExportFormatHTML is a self-contained HTML document for the screen.
+	// It can also be printed.
+	ExportFormatHTML = "html"
+	// ExportFormatPrint is a self-contained HTML document styled for
+	// printing or saving as a PDF from a browser.
+	ExportFormatPrint = "print"
+	// exportIndexFile is the name of the index page of a notebook export.
+	exportIndexFile = "index.html"
+	// exportFileMode is the file mode of exported files.
+	exportFileMode = 0644
+)
+
+// ErrUnknownExportFormat is returned if the export format isn't supported.
+var ErrUnknownExportFormat = errors.New("unknown export format, use html or print")
+
+// htmlVoidElements are written without an end tag.
+var htmlVoidElements = map[string]bool{
+	"area": true, "br": true, "col": true, "hr": true, "img": true,
+	"input": true, "wbr": true,
+}
+
+// ENMLToHTML converts the note's ENML content to HTML5. Images are
+// inlined as data URIs and other resources become download links, checkboxes
+// replace en-todo and encrypted text is left out.
+func ENMLToHTML(n *Note) (string, error) {
+	c := &htmlConverter{mediaURL: dataURI}
+	return c.convert(n)
+}
+
+func dataURI(r *Resource) string {
+	return "data:" + r.Mime + ";base64," + base64.StdEncoding.EncodeToString(r.Data)
+}
+
+// htmlConverter converts ENML to HTML5.
+type htmlConverter struct {
+	// mediaURL returns the URL of the resource.
+	mediaURL func(r *Resource) string
+	// linkURL returns the URL to use for a link. Links are kept as they
+	// are if it's nil.
+	linkURL func(href string) string
+}
+
+func (c *htmlConverter) convert(n *Note) (string, error) {
+	resources := make(map[string]*Resource, len(n.Resources))
+	for _, r := range n.Resources {
+		resources[resourceHash(r)] = r
+	}
+	d := xml.NewDecoder(strings.NewReader(n.Body))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.AutoClose = xml.HTMLAutoClose
+	buf := new(bytes.Buffer)
+	// skip is the depth of the element being skipped.
+	skip := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			c.writeStart(buf, t, resources)
+			if t.Name.Local == "en-crypt" {
+				skip = 1
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			switch name := t.Name.Local; {
+			case name == "en-note":
+				buf.WriteString("</div>")
+			case name == "en-media", name == "en-todo", htmlVoidElements[name]:
+			default:
+				buf.WriteString("</" + name + ">")
+			}
+		case xml.CharData:
+			if skip == 0 {
+				buf.WriteString(html.EscapeString(string(t)))
+			}
+		}
+	}
+	return buf.String(), nil
+}
+
+// resourceHash returns the hash used by en-media elements to reference
+// the resource.
+func resourceHash(r *Resource) string {
+	hash := md5.Sum(r.Data)
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *htmlConverter) writeStart(buf *bytes.Buffer, t xml.StartElement, resources map[string]*Resource) {
+	switch t.Name.Local {
+	case "en-note":
+		buf.WriteString(`<div class="note-content">`)
+	case "en-todo":
+		if xmlAttr(t, "checked") == "true" {
+			buf.WriteString(`<input type="checkbox" disabled checked>`)
+		} else {
+			buf.WriteString(`<input type="checkbox" disabled>`)
+		}
+	case "en-crypt":
+		buf.WriteString(`<span class="encrypted">[encrypted content]</span>`)
+	case "en-media":
+		c.writeMedia(buf, t, resources)
+	default:
+		buf.WriteString("<" + t.Name.Local)
+		for _, a := range t.Attr {
+			value := a.Value
+			if t.Name.Local == "a" && a.Name.Local == "href" && c.linkURL != nil {
+				value = c.linkURL(value)
+			}
+			fmt.Fprintf(buf, ` %s="%s"`, a.Name.Local, html.EscapeString(value))
+		}
+		buf.WriteString(">")
+	}
+}
+
+func (c *htmlConverter) writeMedia(buf *bytes.Buffer, t xml.StartElement, resources map[string]*Resource) {
+	r, ok := resources[strings.ToLower(xmlAttr(t, "hash"))]
+	if !ok {
+		buf.WriteString(`<span class="missing-media">[missing attachment]</span>`)
+		return
+	}
+	if r.Mime == "" {
+		res := *r
+		res.Mime = xmlAttr(t, "type")
+		r = &res
+	}
+	src := html.EscapeString(c.mediaURL(r))
+	name := html.EscapeString(resourceFileName(r))
+	if strings.HasPrefix(r.Mime, "image/") {
+		fmt.Fprintf(buf, `<img src="%s" alt="%s">`, src, name)
+		return
+	}
+	fmt.Fprintf(buf, `<a class="attachment" download="%s" href="%s">%s</a>`, name, src, name)
+}
+
+// resourceFileName returns a file name for the resource that is safe to
+// use in a folder.
+func resourceFileName(r *Resource) string {
+	name := strings.TrimLeft(filepath.Base(strings.Replace(r.FileName, "\\", "/", -1)), ".")
+	if name == "" || name == "/" {
+		return "attachment"
+	}
+	return name
+}
+
+func xmlAttr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// exportStylesheet is used by all exported pages. The print rules make
+// the screen format printable too.
+const exportStylesheet = `
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; color: #222; max-width: 48em; margin: 2em auto; padding: 0 1em; }
+header { border-bottom: 1px solid #ddd; margin-bottom: 1.5em; }
+header .meta { color: #666; font-size: 0.9em; }
+img { max-width: 100%; height: auto; }
+pre, code { font-family: Menlo, Consolas, monospace; background: #f5f5f5; }
+pre { padding: 0.75em; overflow-x: auto; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 0.3em 0.6em; }
+blockquote { border-left: 3px solid #ccc; margin-left: 0; padding-left: 1em; color: #555; }
+.attachment, .encrypted, .missing-media { font-style: italic; }
+.notes { list-style: none; padding: 0; }
+.notes li { padding: 0.3em 0; border-bottom: 1px solid #eee; }
+.notes .meta { color: #666; font-size: 0.9em; margin-left: 0.5em; }
+@media print {
+  body { max-width: none; margin: 0; color: #000; }
+  a { color: #000; }
+  pre, blockquote, img, table { page-break-inside: avoid; }
+}
+`
+
+// exportPrintStylesheet is added to the print format.
+const exportPrintStylesheet = `
+@page { size: A4; margin: 2cm; }
+body { font-family: Georgia, "Times New Roman", serif; font-size: 11pt; max-width: none; margin: 0; }
+h1, h2, h3 { page-break-after: avoid; }
+a[href^="http"]::after { content: " (" attr(href) ")"; font-size: 0.85em; color: #555; }
+`
+
+var exportPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Meta}}<p class="meta">{{.Meta}}</p>{{end}}
+</header>
+{{.Content}}
+</body>
+</html>
+`))
+
+var exportIndexContent = template.Must(template.New("index").Parse(`<ul class="notes">
+{{range .}}<li><a href="{{.File}}">{{.Title}}</a><span class="meta">{{.Updated}}</span></li>
+{{end}}</ul>`))
+
+type exportPageData struct {
+	Title   string
+	Meta    string
+	Style   template.CSS
+	Content template.HTML
+}
+
+func exportStyle(format string) (template.CSS, error) {
+	switch format {
+	case ExportFormatHTML, "":
+		return template.CSS(exportStylesheet), nil
+	case ExportFormatPrint:
+		return template.CSS(exportStylesheet + exportPrintStylesheet), nil
+	}
+	return "", ErrUnknownExportFormat
+}
+
+// WriteNoteHTML writes the note as a self-contained HTML document in the
+// format. The note must have its raw content and resources, see
+// NotestoreClient.ExportNote.
+func WriteNoteHTML(w io.Writer, n *Note, format string) error {
+	style, err := exportStyle(format)
+	if err != nil {
+		return err
+	}
+	content, err := ENMLToHTML(n)
+	if err != nil {
+		return err
+	}
+	return exportPage.Execute(w, &exportPageData{
+		Title:   n.Title,
+		Meta:    noteMeta(n),
+		Style:   style,
+		Content: template.HTML(content),
+	})
+}
+
+func noteMeta(n *Note) string {
+	var a []string
+	if n.Notebook != nil && n.Notebook.Name != "" {
+		a = append(a, n.Notebook.Name)
+	}
+	if n.Updated != 0 {
+		a = append(a, "Updated "+time.Unix(n.Updated/1000, 0).Format(timeFormat))
+	}
+	if len(n.Tags) > 0 {
+		a = append(a, "Tags: "+strings.Join(n.Tags, ", "))
+	}
+	return strings.Join(a, " · ")
+}
+
+// ExportNoteHTML writes the note the reference points to as an HTML
+// document.
+func ExportNoteHTML(db Storager, ns NotestoreClient, ref string, w io.Writer, format string) error {
+	if _, err := exportStyle(format); err != nil {
+		return err
+	}
+	n, err := GetNote(db, ns, ref, "")
+	if err != nil {
+		return err
+	}
+	note, err := ns.ExportNote(n.GUID)
+	if err != nil {
+		return err
+	}
+	note.Notebook = n.Notebook
+	return WriteNoteHTML(w, note, format)
+}
+
+// ExportedNote is a note written by ExportNotebookHTML.
+type ExportedNote struct {
+	// Title is the note's title.
+	Title string
+	// File is the name of the note's file in the export folder.
+	File string
+	// Updated is the date the note was last updated.
+	Updated string
+}
+
+// ExportNotebookHTML writes every note in the notebook as an HTML document
+// to the folder, together with an index page linking every note. The
+// folder is created if it doesn't exist. The exported notes are returned
+// sorted by title.
+func ExportNotebookHTML(db Storager, ns NotestoreClient, notebook, dir, format string) ([]*ExportedNote, error) {
+	style, err := exportStyle(format)
+	if err != nil {
+		return nil, err
+	}
+	nb, err := FindNotebook(db, ns, notebook)
+	if err != nil {
+		return nil, err
+	}
+	// An empty notebook is exported as an index page without notes.
+	notes, err := SelectNotes(db, ns, &BulkSelection{Notebook: notebook})
+	if err != nil && err != ErrNoNotesSelected {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	exported := make([]*ExportedNote, 0, len(notes))
+	files := map[string]bool{exportIndexFile: true}
+	for _, n := range notes {
+		note, err := ns.ExportNote(n.GUID)
+		if err != nil {
+			return nil, err
+		}
+		note.Notebook = nb
+		buf := new(bytes.Buffer)
+		if err = WriteNoteHTML(buf, note, format); err != nil {
+			return nil, err
+		}
+		file := uniqueFileName(files, noteSlug(note.Title), ".html")
+		if err = ioutil.WriteFile(filepath.Join(dir, file), buf.Bytes(), exportFileMode); err != nil {
+			return nil, err
+		}
+		exported = append(exported, &ExportedNote{
+			Title:   note.Title,
+			File:    file,
+			Updated: time.Unix(note.Updated/1000, 0).Format(timeFormat),
+		})
+	}
+	sort.SliceStable(exported, func(i, j int) bool {
+		return strings.ToLower(exported[i].Title) < strings.ToLower(exported[j].Title)
+	})
+	list := new(bytes.Buffer)
+	if err = exportIndexContent.Execute(list, exported); err != nil {
+		return nil, err
+	}
+	index := new(bytes.Buffer)
+	err = exportPage.Execute(index, &exportPageData{
+		Title:   nb.Name,
+		Meta:    fmt.Sprintf("%d notes", len(exported)),
+		Style:   style,
+		Content: template.HTML(list.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, exportIndexFile), index.Bytes(), exportFileMode)
+	if err != nil {
+		return nil, err
+	}
+	return exported, nil
+}
+
+// noteSlug returns a file name friendly version of the title.
+func noteSlug(title string) string {
+	buf := new(bytes.Buffer)
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			buf.WriteRune(r)
+			dash = false
+		} else if !dash && buf.Len() > 0 {
+			buf.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(buf.String(), "-")
+	if slug == "" {
+		slug = "note"
+	}
+	return slug
+}
+
+// uniqueFileName returns name+ext, with a number added to the name if the
+// file is already used. The returned name is marked as used.
+func uniqueFileName(used map[string]bool, name, ext string) string {
+	file := name + ext
+	for i := 2; used[file]; i++ {
+		file = fmt.Sprintf("%s-%d%s", name, i, ext)
+	}
+	used[file] = true
+	return file
+}
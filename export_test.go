@@ -0,0 +1,160 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestENMLToHTML(t *testing.T) {
+	assert := assert.New(t)
+	img := &Resource{Data: []byte("png data"), Mime: "image/png", FileName: "pic.png"}
+	pdf := &Resource{Data: []byte("pdf data"), Mime: "application/pdf", FileName: "doc.pdf"}
+	hash := func(r *Resource) string {
+		h := md5.Sum(r.Data)
+		return hex.EncodeToString(h[:])
+	}
+	n := &Note{
+		Body: XMLHeader + `<en-note><div>A &amp; B<br/></div>` +
+			`<div><en-todo checked="true"/>done <en-todo/>open</div>` +
+			`<en-media type="image/png" hash="` + hash(img) + `"/>` +
+			`<en-media type="application/pdf" hash="` + hash(pdf) + `"/>` +
+			`<en-media type="image/png" hash="00"/>` +
+			`<en-crypt cipher="AES">c2VjcmV0</en-crypt></en-note>`,
+		Resources: []*Resource{img, pdf},
+	}
+	expected := `<div class="note-content"><div>A &amp; B<br></div>` +
+		`<div><input type="checkbox" disabled checked>done <input type="checkbox" disabled>open</div>` +
+		`<img src="data:image/png;base64,` + base64.StdEncoding.EncodeToString(img.Data) + `" alt="pic.png">` +
+		`<a class="attachment" download="doc.pdf" href="data:application/pdf;base64,` + base64.StdEncoding.EncodeToString(pdf.Data) + `">doc.pdf</a>` +
+		`<span class="missing-media">[missing attachment]</span>` +
+		`<span class="encrypted">[encrypted content]</span></div>`
+	html, err := ENMLToHTML(n)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(expected, html, "Wrong HTML")
+}
+
+func TestWriteNoteHTML(t *testing.T) {
+	assert := assert.New(t)
+	n := &Note{
+		Title:    "<Title>",
+		Body:     XMLHeader + "<en-note><p>text</p></en-note>",
+		Notebook: &Notebook{Name: "Work"},
+		Tags:     []string{"a", "b"},
+	}
+	buf := new(bytes.Buffer)
+	assert.NoError(WriteNoteHTML(buf, n, ExportFormatHTML), "Should not return an error")
+	doc := buf.String()
+	assert.Contains(doc, "<!DOCTYPE html>", "Should be an HTML5 document")
+	assert.Contains(doc, "<title>&lt;Title&gt;</title>", "Title should be escaped")
+	assert.Contains(doc, "Work · Tags: a, b", "Meta data should be included")
+	assert.Contains(doc, `<div class="note-content"><p>text</p></div>`, "Content should be included")
+	assert.NotContains(doc, "@page", "Print styles should not be included")
+
+	buf.Reset()
+	assert.NoError(WriteNoteHTML(buf, n, ExportFormatPrint), "Should not return an error")
+	assert.Contains(buf.String(), "@page", "Print styles should be included")
+
+	assert.Equal(ErrUnknownExportFormat, WriteNoteHTML(buf, n, "pdf"), "Wrong error for unknown format")
+}
+
+func TestExportNotebookHTML(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-export")
+	assert.NoError(err, "Should create a temp folder")
+	defer os.RemoveAll(dir)
+
+	nb := &Notebook{Name: "Work", GUID: "NB"}
+	notes := []*Note{
+		{Title: "Plan", GUID: "1", Notebook: nb},
+		{Title: "plan", GUID: "2", Notebook: nb},
+		{Title: "Index", GUID: "3", Notebook: nb},
+	}
+	ns := &mockNS{
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			assert.Equal("NB", f.NotebookGUID, "Wrong notebook searched")
+			return notes, nil
+		},
+		exportNote: func(guid string) (*Note, error) {
+			for _, n := range notes {
+				if n.GUID == guid {
+					return &Note{Title: n.Title, GUID: guid, Body: XMLHeader + "<en-note>" + guid + "</en-note>"}, nil
+				}
+			}
+			return nil, ErrNoNoteFound
+		},
+	}
+	store := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return NewNotebookCacheList([]*Notebook{nb}), nil
+		},
+	}
+
+	exported, err := ExportNotebookHTML(store, ns, "Work", dir, ExportFormatHTML)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*ExportedNote{
+		{Title: "Index", File: "index-2.html", Updated: exported[0].Updated},
+		{Title: "Plan", File: "plan.html", Updated: exported[1].Updated},
+		{Title: "plan", File: "plan-2.html", Updated: exported[2].Updated},
+	}, exported, "Wrong exported notes")
+	for _, name := range []string{"index.html", "index-2.html", "plan.html", "plan-2.html"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.NoError(err, name+" should be written")
+	}
+	index, err := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	assert.NoError(err, "Should read the index")
+	assert.Contains(string(index), `<a href="plan-2.html">plan</a>`, "Index should link the notes")
+}
+
+func TestExportEmptyNotebookHTML(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-export")
+	assert.NoError(err, "Should create a temp folder")
+	defer os.RemoveAll(dir)
+
+	nb := &Notebook{Name: "Empty", GUID: "NB"}
+	ns := &mockNS{findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) { return []*Note{}, nil }}
+	store := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return NewNotebookCacheList([]*Notebook{nb}), nil
+		},
+	}
+
+	exported, err := ExportNotebookHTML(store, ns, "Empty", dir, ExportFormatHTML)
+	assert.NoError(err, "Should not return an error")
+	assert.Empty(exported, "No notes should be exported")
+	index, err := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	assert.NoError(err, "Should write the index")
+	assert.Contains(string(index), "0 notes", "Index should show the note count")
+}
+
+func TestNoteSlug(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("meeting-notes-2018", noteSlug("Meeting notes: 2018!"), "Wrong slug")
+	assert.Equal("åäö-日本", noteSlug("ÅÄÖ 日本"), "Letters should be kept")
+	assert.Equal("note", noteSlug("!!!"), "Empty slug should be replaced")
+}
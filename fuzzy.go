@@ -0,0 +1,169 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// suggestionThreshold is the minimum similarity for a note to be
+	// suggested.
+	suggestionThreshold = 0.5
+	// recentNoteCount is the number of recently updated notes used as
+	// candidates for suggestions and the picker.
+	recentNoteCount = 100
+)
+
+// SuggestNotes returns up to max notes with a title similar to the query,
+// ranked by similarity. The notes in the last saved search and the most
+// recently updated notes are used as candidates.
+func SuggestNotes(db Storager, ns NotestoreClient, query string, max int) ([]*Note, error) {
+	notes, err := recentNotes(db, ns, "")
+	if err != nil {
+		return nil, err
+	}
+	type match struct {
+		note  *Note
+		score float64
+	}
+	var matches []match
+	for _, n := range notes {
+		if s := similarity(query, n.Title); s >= suggestionThreshold {
+			matches = append(matches, match{note: n, score: s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	if len(matches) > max {
+		matches = matches[:max]
+	}
+	suggestions := make([]*Note, len(matches))
+	for i, m := range matches {
+		suggestions[i] = m.note
+	}
+	return suggestions, nil
+}
+
+// recentNotes returns the notes in the last saved search followed by
+// the most recently updated notes. If notebook is not an empty string,
+// the recent notes are restricted to the notebook.
+func recentNotes(db Storager, ns NotestoreClient, notebook string) ([]*Note, error) {
+	filter := &NoteFilter{Order: NoteFilterOrderUpdated}
+	if notebook != "" {
+		nb, err := findNotebook(db, ns, notebook)
+		if err != nil {
+			return nil, err
+		}
+		filter.NotebookGUID = nb.GUID
+	}
+	saved, err := db.GetSearch()
+	if err != nil {
+		return nil, err
+	}
+	recent, err := ns.FindNotes(filter, 0, recentNoteCount)
+	if err != nil {
+		return nil, err
+	}
+	if notebook != "" {
+		saved = nil
+	}
+	seen := make(map[string]bool)
+	var notes []*Note
+	for _, n := range append(saved, recent...) {
+		if n.GUID != "" && seen[n.GUID] {
+			continue
+		}
+		seen[n.GUID] = true
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+// similarity returns a case insensitive similarity score between 0 and 1
+// for how well the query matches the text.
+func similarity(query, text string) float64 {
+	query, text = strings.ToLower(query), strings.ToLower(text)
+	if query == "" || text == "" {
+		return 0
+	}
+	if query == text {
+		return 1
+	}
+	if strings.Contains(text, query) {
+		return 0.9
+	}
+	if isSubsequence(query, text) {
+		return 0.7
+	}
+	l := utf8.RuneCountInString(query)
+	if n := utf8.RuneCountInString(text); n > l {
+		l = n
+	}
+	return 1 - float64(levenshtein(query, text))/float64(l)
+}
+
+// isSubsequence returns true if all the runes in a are found in b in the
+// same order.
+func isSubsequence(a, b string) bool {
+	rb := []rune(b)
+	i := 0
+	for _, r := range a {
+		for i < len(rb) && rb[i] != r {
+			i++
+		}
+		if i == len(rb) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(vals ...int) int {
+	m := vals[0]
+	for _, v := range vals[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,70 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarity(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		query, text string
+		min, max    float64
+	}{
+		{"Shopping list", "shopping list", 1, 1},
+		{"meeting", "Weekly meeting notes", 0.9, 0.9},
+		{"wkly mtng", "Weekly meeting", 0.7, 0.7},
+		{"Shoping lsit", "Shopping list", 0.7, 0.9},
+		{"Recipes", "Tax return", 0, 0.5},
+		{"", "Title", 0, 0},
+	}
+	for _, test := range tests {
+		s := similarity(test.query, test.text)
+		assert.True(s >= test.min && s <= test.max, "Similarity between %q and %q is %f", test.query, test.text, s)
+	}
+	assert.Equal(3, levenshtein("kitten", "sitting"), "Wrong edit distance")
+}
+
+func TestSuggestNotes(t *testing.T) {
+	assert := assert.New(t)
+	shopping := &Note{Title: "Shopping list", GUID: "1"}
+	meeting := &Note{Title: "Weekly meeting", GUID: "2"}
+	tax := &Note{Title: "Tax return", GUID: "3"}
+	store := &mockStore{getSearch: func() ([]*Note, error) { return []*Note{shopping}, nil }}
+	ns := &mockNS{findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+		return []*Note{tax, meeting, shopping}, nil
+	}}
+	t.Run("rank by similarity", func(t *testing.T) {
+		notes, err := SuggestNotes(store, ns, "shoping list", 5)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Note{shopping}, notes, "Wrong suggestions")
+	})
+	t.Run("limit suggestions", func(t *testing.T) {
+		notes, err := SuggestNotes(store, ns, "e", 2)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(notes, 2, "Wrong number of suggestions")
+	})
+	t.Run("no suggestions", func(t *testing.T) {
+		notes, err := SuggestNotes(store, ns, "Recipes", 5)
+		assert.NoError(err, "Should not return an error")
+		assert.Empty(notes, "No notes should be suggested")
+	})
+}
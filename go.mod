@@ -8,19 +8,19 @@ require (
 	github.com/boltdb/bolt v1.3.2-0.20180302180052-fd01fc79c553
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
-	github.com/mattn/go-runewidth v0.0.3 // indirect
+	github.com/mattn/go-runewidth v0.0.3
 	github.com/mattn/godown v0.0.0-20180312012330-2e9e17e0ea51
 	github.com/mrjones/oauth v0.0.0-20161024000904-88427e754deb
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
 	github.com/olekukonko/tablewriter v0.0.0-20180506121414-d4647c9c7a84
-	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/russross/blackfriday v0.0.0-20160124111256-006144af03ee
 	github.com/satori/go.uuid v1.2.1-0.20181028125025-b2ce2384e17b
 	github.com/shurcooL/sanitized_anchor_name v0.0.0-20151028001915-10ef21a441db // indirect
 	github.com/spf13/cobra v0.0.0-20161116132053-9495bc009a56
-	github.com/spf13/pflag v0.0.0-20161024131444-5ccb023bc27d // indirect
+	github.com/spf13/pflag v0.0.0-20161024131444-5ccb023bc27d
 	github.com/stretchr/testify v1.1.4-0.20160305165446-6fe211e49392
-	golang.org/x/net v0.0.0-20180511174649-2491c5de3490 // indirect
+	golang.org/x/net v0.0.0-20180511174649-2491c5de3490
 	golang.org/x/sys v0.0.0-20200321134203-328b4cd54aae // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 )
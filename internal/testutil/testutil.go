@@ -0,0 +1,202 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+// Package testutil has the in-memory notestore and the database setup
+// shared by the tests of the server, rpc and tui packages.
+package testutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/storage"
+)
+
+// NewNoteGUID is the GUID given to notes created in the Notestore.
+const NewNoteGUID = "ffffffff-ffff-ffff-ffff-ffffffffffff"
+
+// Notestore is an in-memory notestore. Methods not implemented panic
+// through the embedded nil interface.
+type Notestore struct {
+	clinote.NotestoreClient
+	// Notes are returned by FindNotes in order.
+	Notes     []*clinote.Note
+	Content   map[string]string
+	Notebooks []*clinote.Notebook
+	// Filters are the filters FindNotes has been called with.
+	Filters []*clinote.NoteFilter
+	// Deleted are the GUIDs of the notes moved to the trash.
+	Deleted []string
+}
+
+// NewNotestore returns a notestore with the notebooks and no notes.
+func NewNotestore(notebooks ...*clinote.Notebook) *Notestore {
+	return &Notestore{Notebooks: notebooks, Content: make(map[string]string)}
+}
+
+// AddNote adds a note with the ENML content.
+func (f *Notestore) AddNote(n *clinote.Note, content string) {
+	f.Notes = append(f.Notes, n)
+	f.Content[n.GUID] = clinote.XMLHeader + "<en-note>" + content + "</en-note>"
+}
+
+// Note returns the stored note with the GUID or nil.
+func (f *Notestore) Note(guid string) *clinote.Note {
+	for _, n := range f.Notes {
+		if n.GUID == guid {
+			return n
+		}
+	}
+	return nil
+}
+
+// LastFilter returns the filter of the last FindNotes call.
+func (f *Notestore) LastFilter() *clinote.NoteFilter {
+	if len(f.Filters) == 0 {
+		return nil
+	}
+	return f.Filters[len(f.Filters)-1]
+}
+
+// FindNotes returns copies of the notes in the filter's notebook. The
+// search words are ignored.
+func (f *Notestore) FindNotes(filter *clinote.NoteFilter, offset, count int) ([]*clinote.Note, error) {
+	f.Filters = append(f.Filters, filter)
+	var a []*clinote.Note
+	for _, n := range f.Notes {
+		if filter.NotebookGUID != "" && n.Notebook.GUID != filter.NotebookGUID {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if len(a) == count {
+			break
+		}
+		c := *n
+		a = append(a, &c)
+	}
+	return a, nil
+}
+
+// GetAllNotebooks returns the notebooks.
+func (f *Notestore) GetAllNotebooks() ([]*clinote.Notebook, error) {
+	return f.Notebooks, nil
+}
+
+// GetNotebook returns the notebook with the GUID.
+func (f *Notestore) GetNotebook(guid string) (*clinote.Notebook, error) {
+	for _, b := range f.Notebooks {
+		if b.GUID == guid {
+			return b, nil
+		}
+	}
+	return nil, clinote.ErrNoNotebookFound
+}
+
+// GetNote returns a copy of the note with the GUID.
+func (f *Notestore) GetNote(guid string) (*clinote.Note, error) {
+	n := f.Note(guid)
+	if n == nil {
+		return nil, clinote.ErrNoNoteFound
+	}
+	c := *n
+	return &c, nil
+}
+
+// GetNoteContent returns the note's content.
+func (f *Notestore) GetNoteContent(guid string) (string, error) {
+	return f.Content[guid], nil
+}
+
+// UpdateNote replaces the stored note. The content is only replaced if
+// the note has a body.
+func (f *Notestore) UpdateNote(n *clinote.Note) error {
+	for i, old := range f.Notes {
+		if old.GUID == n.GUID {
+			c := *n
+			f.Notes[i] = &c
+		}
+	}
+	if n.Body != "" {
+		f.Content[n.GUID] = n.Body
+	}
+	return nil
+}
+
+// CreateNote stores the note with NewNoteGUID. Notes without a notebook
+// are put in the default notebook.
+func (f *Notestore) CreateNote(n *clinote.Note) error {
+	n.GUID = NewNoteGUID
+	if n.Notebook == nil {
+		for _, b := range f.Notebooks {
+			if b.Default {
+				n.Notebook = &clinote.Notebook{GUID: b.GUID}
+			}
+		}
+	}
+	c := *n
+	f.Notes = append(f.Notes, &c)
+	f.Content[n.GUID] = n.Body
+	return nil
+}
+
+// DeleteNote removes the note and records its GUID in Deleted.
+func (f *Notestore) DeleteNote(guid string) error {
+	f.Deleted = append(f.Deleted, guid)
+	for i, n := range f.Notes {
+		if n.GUID == guid {
+			f.Notes = append(f.Notes[:i], f.Notes[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// CreateNotebook adds the notebook with a new GUID.
+func (f *Notestore) CreateNotebook(b *clinote.Notebook, defaultNotebook bool) error {
+	b.GUID = fmt.Sprintf("%08d-0000-0000-0000-eeeeeeeeeeee", len(f.Notebooks))
+	f.Notebooks = append(f.Notebooks, b)
+	return nil
+}
+
+// NoteLink returns an evernote:// link to the note.
+func (f *Notestore) NoteLink(guid string) (string, error) {
+	return "evernote:///view/1/s1/" + guid + "/" + guid + "/", nil
+}
+
+// OpenDB opens a database in a new temporary folder. The returned
+// function closes the database and removes the folder.
+func OpenDB(t *testing.T) (*storage.Database, string, func()) {
+	dir, err := ioutil.TempDir("", "clinote-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := storage.Open(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, dir, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+// DefaultJournalTitle is the title pattern used for journal notes if
+// none is configured.
+const DefaultJournalTitle = "{{date}} Journal"
+
+var (
+	// ErrInvalidJournalDate is returned if the journal date can't be parsed.
+	ErrInvalidJournalDate = errors.New("invalid date, use today, yesterday, tomorrow, -N or YYYY-MM-DD")
+)
+
+// Journal is the configuration for daily journal notes.
+type Journal struct {
+	// Notebook is the notebook holding the journal notes. If empty, the
+	// default notebook is used.
+	Notebook string
+	// TitlePattern is the title of a journal note. The placeholders
+	// {{date}}, {{weekday}}, {{year}}, {{month}} and {{day}} are replaced
+	// with the journal date.
+	TitlePattern string
+	// Template is the template used for new journal notes.
+	Template string
+}
+
+// JournalFromSettings returns the journal configured in the settings.
+func JournalFromSettings(s *Settings) *Journal {
+	j := &Journal{
+		Notebook:     s.JournalNotebook,
+		TitlePattern: s.JournalTitle,
+		Template:     s.JournalTemplate,
+	}
+	if j.TitlePattern == "" {
+		j.TitlePattern = DefaultJournalTitle
+	}
+	return j
+}
+
+// Title returns the title of the journal note for the date.
+func (j *Journal) Title(date time.Time) string {
+	r := strings.NewReplacer(
+		"{{date}}", date.Format(timeFormat),
+		"{{weekday}}", date.Weekday().String(),
+		"{{year}}", date.Format("2006"),
+		"{{month}}", date.Format("01"),
+		"{{day}}", date.Format("02"),
+	)
+	return r.Replace(j.TitlePattern)
+}
+
+// ParseJournalDate parses a journal date relative to the current day.
+// The date can be today, yesterday, tomorrow, -N for N days ago or a
+// date formatted as YYYY-MM-DD. An empty string is today.
+func ParseJournalDate(s string) (time.Time, error) {
+	today := now()
+	switch s {
+	case "", "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if strings.HasPrefix(s, "-") {
+		days, err := strconv.Atoi(s[1:])
+		if err != nil || days < 0 {
+			return time.Time{}, ErrInvalidJournalDate
+		}
+		return today.AddDate(0, 0, -days), nil
+	}
+	date, err := time.ParseInLocation(timeFormat, s, today.Location())
+	if err != nil {
+		return time.Time{}, ErrInvalidJournalDate
+	}
+	return date, nil
+}
+
+// FindJournalNote returns the journal note for the date.
+func FindJournalNote(client *Client, j *Journal, date time.Time) (*Note, error) {
+	title := j.Title(date)
+	return findNoteByTitle(client.Store, client.NoteStore, title, title, j.Notebook, false)
+}
+
+// OpenJournal opens the journal note for the date in the editor. If the
+// note doesn't exist, it's created from the journal template.
+func OpenJournal(client *Client, j *Journal, date time.Time, opts NoteOption) error {
+	n, err := FindJournalNote(client, j, date)
+	if err == nil {
+		return EditNote(client, GUIDPrefix+n.GUID, opts)
+	}
+	if err != ErrNoNoteFound {
+		return err
+	}
+	note, err := newJournalNote(client, j, date)
+	if err != nil {
+		return err
+	}
+	if j.Template != "" {
+		return CreateNoteFromTemplate(client, note, j.Template, opts)
+	}
+	return CreateAndEditNewNote(client, note, opts&^RawNote)
+}
+
+// AppendToJournal adds the text as a line prefixed with the current time
+// to the journal note for the date. If the note doesn't exist, it's created.
+func AppendToJournal(client *Client, j *Journal, date time.Time, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return ErrNoContent
+	}
+	line := "<div>" + html.EscapeString(now().Format("15:04")+" "+strings.TrimSpace(text)) + "</div>"
+	n, err := FindJournalNote(client, j, date)
+	if err == nil {
+		return AppendToNote(client.Store, client.NoteStore, GUIDPrefix+n.GUID, line, RawNote)
+	}
+	if err != ErrNoNoteFound {
+		return err
+	}
+	note, err := newJournalNote(client, j, date)
+	if err != nil {
+		return err
+	}
+	if j.Template != "" {
+		t, err := GetTemplate(client, j.Template)
+		if err != nil {
+			return err
+		}
+		md := RenderTemplate(t, templateVars(client, note))
+		note.Body = string(markdown.ToXML(md))
+	}
+	// The line is added to the new note directly since the new note
+	// may not be searchable right after it has been created.
+	note.Body += line
+	return SaveNewNote(client.NoteStore, note, true)
+}
+
+func newJournalNote(client *Client, j *Journal, date time.Time) (*Note, error) {
+	note := &Note{Title: j.Title(date)}
+	if j.Notebook != "" {
+		nb, err := FindNotebook(client.Store, client.NoteStore, j.Notebook)
+		if err != nil {
+			return nil, err
+		}
+		note.Notebook = nb
+	}
+	return note, nil
+}
@@ -0,0 +1,100 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJournalDate(t *testing.T) {
+	assert := assert.New(t)
+	now = func() time.Time { return time.Date(2018, 5, 10, 9, 0, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "2018-05-10"},
+		{"today", "2018-05-10"},
+		{"yesterday", "2018-05-09"},
+		{"tomorrow", "2018-05-11"},
+		{"-7", "2018-05-03"},
+		{"2018-01-31", "2018-01-31"},
+	}
+	for _, test := range tests {
+		date, err := ParseJournalDate(test.input)
+		assert.NoError(err, "Should not return an error for "+test.input)
+		assert.Equal(test.expected, date.Format(timeFormat), "Wrong date for "+test.input)
+	}
+	for _, input := range []string{"last week", "-x", "2018/01/31"} {
+		_, err := ParseJournalDate(input)
+		assert.Equal(ErrInvalidJournalDate, err, "Wrong error for "+input)
+	}
+}
+
+func TestJournalTitle(t *testing.T) {
+	assert := assert.New(t)
+	date := time.Date(2018, 5, 10, 0, 0, 0, 0, time.Local)
+	j := JournalFromSettings(&Settings{})
+	assert.Equal("2018-05-10 Journal", j.Title(date), "Wrong default title")
+	j = JournalFromSettings(&Settings{JournalTitle: "{{weekday}} {{day}}/{{month}} {{year}}"})
+	assert.Equal("Thursday 10/05 2018", j.Title(date), "Wrong title")
+}
+
+func TestAppendToJournal(t *testing.T) {
+	assert := assert.New(t)
+	now = func() time.Time { return time.Date(2018, 5, 10, 14, 15, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+	guid := "12345678-1234-1234-1234-123456789012"
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	j := JournalFromSettings(&Settings{})
+	date, _ := ParseJournalDate("today")
+
+	t.Run("existing note", func(t *testing.T) {
+		note := &Note{Title: "2018-05-10 Journal", GUID: guid, Notebook: &Notebook{GUID: "NB"}}
+		ns := nsWithNote(note)
+		ns.getNote = func(string) (*Note, error) { return note, nil }
+		ns.getNoteContent = func(string) (string, error) {
+			return XMLHeader + "<en-note><div>09:00 start</div></en-note>", nil
+		}
+		var saved string
+		ns.updateNote = func(n *Note) error { saved = n.Body; return nil }
+		client := &Client{Store: store, NoteStore: ns}
+		assert.NoError(AppendToJournal(client, j, date, "fix <bug>"), "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div>09:00 start</div><div>14:15 fix &lt;bug&gt;</div></en-note>", saved)
+	})
+	t.Run("new note", func(t *testing.T) {
+		ns := nsWithNote(&Note{Title: "2018-05-09 Journal"})
+		var created *Note
+		ns.createNote = func(n *Note) error { created = n; return nil }
+		client := &Client{Store: store, NoteStore: ns}
+		assert.NoError(AppendToJournal(client, j, date, "start"), "Should not return an error")
+		assert.Equal("2018-05-10 Journal", created.Title, "Wrong title")
+		assert.Equal(XMLHeader+"<en-note><div>14:15 start</div></en-note>", created.Body)
+	})
+	t.Run("no content", func(t *testing.T) {
+		client := &Client{Store: store, NoteStore: new(mockNS)}
+		assert.Equal(ErrNoContent, AppendToJournal(client, j, date, " "))
+	})
+}
@@ -0,0 +1,86 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+// Package jsonapi has the JSON representations of notes and notebooks
+// used by the HTTP and JSON-RPC servers.
+package jsonapi
+
+import "github.com/TcM1911/clinote"
+
+// Note is the JSON representation of a note. The content is only set
+// when a single note is requested.
+type Note struct {
+	GUID         string   `json:"guid"`
+	Title        string   `json:"title"`
+	Notebook     string   `json:"notebook,omitempty"`
+	NotebookGUID string   `json:"notebookGuid,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	// Created and Updated are in milliseconds since the epoch.
+	Created  int64  `json:"created,omitempty"`
+	Updated  int64  `json:"updated,omitempty"`
+	Markdown string `json:"markdown,omitempty"`
+	// ENML is the content inside the en-note element.
+	ENML string `json:"enml,omitempty"`
+}
+
+// Notebook is the JSON representation of a notebook.
+type Notebook struct {
+	GUID    string `json:"guid"`
+	Name    string `json:"name"`
+	Stack   string `json:"stack,omitempty"`
+	Default bool   `json:"default,omitempty"`
+}
+
+// NewNote returns the JSON representation of the note without content.
+// Notes from searches only have the notebook's GUID so the notebook's
+// name is looked up in notebooks, keyed by GUID.
+func NewNote(n *clinote.Note, notebooks map[string]string) *Note {
+	note := &Note{
+		GUID:    n.GUID,
+		Title:   n.Title,
+		Tags:    n.Tags,
+		Created: n.Created,
+		Updated: n.Updated,
+	}
+	if n.Notebook != nil {
+		note.NotebookGUID = n.Notebook.GUID
+		note.Notebook = n.Notebook.Name
+		if note.Notebook == "" {
+			note.Notebook = notebooks[n.Notebook.GUID]
+		}
+	}
+	return note
+}
+
+// NewNotebook returns the JSON representation of the notebook.
+func NewNotebook(b *clinote.Notebook) *Notebook {
+	return &Notebook{GUID: b.GUID, Name: b.Name, Stack: b.Stack, Default: b.Default}
+}
+
+// NotebookNames returns the names of the user's notebooks by GUID. The
+// map is empty if the notebooks can't be fetched.
+func NotebookNames(db clinote.Storager, ns clinote.NotestoreClient) map[string]string {
+	names := make(map[string]string)
+	bs, err := clinote.GetNotebooks(db, ns, false)
+	if err != nil {
+		return names
+	}
+	for _, b := range bs {
+		names[b.GUID] = b.Name
+	}
+	return names
+}
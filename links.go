@@ -0,0 +1,348 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// wikiLinkRegexp matches [[Note Title]] and [[Note Title|link text]].
+	wikiLinkRegexp = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]+))?\]\]`)
+	// mdNoteLinkRegexp matches markdown links to notes.
+	mdNoteLinkRegexp = regexp.MustCompile(`\[((?:[^\[\]]|\[[^\[\]]*\])*)\]\(((?:evernote:///view/|https://www\.evernote\.com/shard/)[^)\s]+)\)`)
+	// noteURLRegexp matches note links in the raw content.
+	noteURLRegexp = regexp.MustCompile(`(?:evernote:///view/|https://www\.evernote\.com/shard/)[^"'\s<>]+`)
+	guidRegexp    = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	// fenceRegexp matches the start of a fenced code block.
+	fenceRegexp = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	// listItemRegexp matches the first line of a list item.
+	listItemRegexp = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s`)
+)
+
+// LinkIndex is an index of the links between notes. It's updated when a
+// note is viewed or edited and can be rebuilt with RebuildLinkIndex.
+type LinkIndex struct {
+	// Notes are the indexed notes, keyed by the note's GUID.
+	Notes map[string]*LinkIndexEntry
+}
+
+// LinkIndexEntry is a note in the link index.
+type LinkIndexEntry struct {
+	// Title is the note's title.
+	Title string
+	// Links are the GUIDs of the notes linked to by the note.
+	Links []string
+}
+
+// noteLinkGUID returns the GUID of the note the URL links to. An empty
+// string is returned if the URL isn't a note link.
+func noteLinkGUID(url string) string {
+	if strings.HasPrefix(url, "https://") && !strings.Contains(url, "/nl/") {
+		return ""
+	}
+	return guidRegexp.FindString(url)
+}
+
+// noteLinkGUIDs returns the GUIDs of the notes linked to in the content.
+func noteLinkGUIDs(content string) []string {
+	var guids []string
+	for _, url := range noteURLRegexp.FindAllString(content, -1) {
+		guid := noteLinkGUID(url)
+		if guid != "" && !containsString(guids, guid) {
+			guids = append(guids, guid)
+		}
+	}
+	return guids
+}
+
+// toWikiLinks converts the markdown links to notes into [[Note Title]]
+// links. If the link text isn't the title of the note, the link is
+// converted into [[Note Title|link text]]. Links to notes that can't be
+// found are left as they are. The titles are taken from the link index and
+// only notes missing from it are fetched. The returned map has the GUIDs
+// of the linked notes keyed by the title used in the links, so the links
+// can be resolved even if the indexed title is outdated.
+func toWikiLinks(db Storager, ns NotestoreClient, md string) (string, map[string]string) {
+	titles := make(map[string]string)
+	if db != nil {
+		if index, err := db.GetLinkIndex(); err == nil {
+			for guid, e := range index.Notes {
+				titles[guid] = e.Title
+			}
+		}
+	}
+	guids := make(map[string]string)
+	md = replaceOutsideCode(md, func(text string) string {
+		return mdNoteLinkRegexp.ReplaceAllStringFunc(text, func(link string) string {
+			m := mdNoteLinkRegexp.FindStringSubmatch(link)
+			guid := noteLinkGUID(m[2])
+			if guid == "" {
+				return link
+			}
+			title, ok := titles[guid]
+			if !ok {
+				n, err := ns.GetNote(guid)
+				if err == nil {
+					title = n.Title
+				}
+				titles[guid] = title
+			}
+			if title == "" || strings.ContainsAny(title, "[]|") {
+				return link
+			}
+			guids[title] = guid
+			if m[1] == title {
+				return "[[" + title + "]]"
+			}
+			return "[[" + title + "|" + m[1] + "]]"
+		})
+	})
+	return md, guids
+}
+
+// resolveWikiLinks converts the [[Note Title]] links into markdown links
+// to the notes. The GUIDs of known titles are taken from guids and the
+// other titles are searched for. Links to notes that can't be found, or
+// where more than one note has the title, are left as they are. Links in
+// code are not converted.
+func resolveWikiLinks(ns NotestoreClient, md string, guids map[string]string) string {
+	urls := make(map[string]string)
+	return replaceOutsideCode(md, func(text string) string {
+		return wikiLinkRegexp.ReplaceAllStringFunc(text, func(link string) string {
+			m := wikiLinkRegexp.FindStringSubmatch(link)
+			title, text := strings.TrimSpace(m[1]), m[2]
+			if text == "" {
+				text = title
+			}
+			url, ok := urls[title]
+			if !ok {
+				guid := guids[title]
+				if guid == "" {
+					// The db is only used to look up a notebook so it isn't needed.
+					if n, err := findNoteByTitle(nil, ns, title, title, "", false); err == nil {
+						guid = n.GUID
+					}
+				}
+				if guid != "" {
+					url, _ = ns.NoteLink(guid)
+				}
+				urls[title] = url
+			}
+			if url == "" {
+				return link
+			}
+			return "[" + text + "](" + url + ")"
+		})
+	})
+}
+
+// replaceOutsideCode applies replace to the parts of the markdown that
+// are not in code blocks or code spans.
+func replaceOutsideCode(md string, replace func(string) string) string {
+	out, text := new(bytes.Buffer), new(bytes.Buffer)
+	flush := func() {
+		out.WriteString(replaceOutsideCodeSpans(text.String(), replace))
+		text.Reset()
+	}
+	fence := ""
+	prevBlank, inList, inIndented := true, false, false
+	for _, line := range strings.SplitAfter(md, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if fence != "" {
+			out.WriteString(line)
+			if isClosingFence(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fenceRegexp.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			out.WriteString(line)
+			fence = m[1]
+			continue
+		}
+		blank := strings.TrimSpace(trimmed) == ""
+		indented := strings.HasPrefix(trimmed, "    ") || strings.HasPrefix(trimmed, "\t")
+		// An indented line after a blank line is code, unless it
+		// continues a list item.
+		if indented && !blank && (inIndented || (prevBlank && !inList)) {
+			flush()
+			out.WriteString(line)
+			inIndented = true
+			continue
+		}
+		if !blank {
+			inIndented = false
+			if listItemRegexp.MatchString(trimmed) {
+				inList = true
+			} else if !indented {
+				inList = false
+			}
+		}
+		text.WriteString(line)
+		prevBlank = blank
+	}
+	flush()
+	return out.String()
+}
+
+// isClosingFence returns true if the line closes the code block opened
+// with the fence.
+func isClosingFence(line, fence string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// replaceOutsideCodeSpans applies replace to the text outside the code
+// spans. A code span starts with a run of backticks and ends with a run
+// of the same length.
+func replaceOutsideCodeSpans(s string, replace func(string) string) string {
+	out := new(bytes.Buffer)
+	start := 0
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		n := backtickRun(s, i)
+		end := -1
+		for j := i + n; j < len(s); {
+			if s[j] != '`' {
+				j++
+				continue
+			}
+			if m := backtickRun(s, j); m == n {
+				end = j + m
+				break
+			} else {
+				j += m
+			}
+		}
+		if end < 0 {
+			i += n
+			continue
+		}
+		out.WriteString(replace(s[start:i]))
+		out.WriteString(s[i:end])
+		i, start = end, end
+	}
+	out.WriteString(replace(s[start:]))
+	return out.String()
+}
+
+func backtickRun(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	return n
+}
+
+// updateLinkIndex saves the note's links in the link index. The index is
+// only written if the note's entry has changed.
+func updateLinkIndex(db Storager, n *Note, content string) error {
+	index, err := db.GetLinkIndex()
+	if err != nil {
+		return err
+	}
+	if index.Notes == nil {
+		index.Notes = make(map[string]*LinkIndexEntry)
+	}
+	entry := &LinkIndexEntry{Title: n.Title, Links: noteLinkGUIDs(content)}
+	if old, ok := index.Notes[n.GUID]; ok && old.Title == entry.Title && equalStrings(old.Links, entry.Links) {
+		return nil
+	}
+	index.Notes[n.GUID] = entry
+	return db.StoreLinkIndex(index)
+}
+
+// NoteLinks returns the notes linked to by the note. Linked notes that
+// can't be found only have the GUID set.
+func NoteLinks(db Storager, ns NotestoreClient, title string) ([]*Note, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	content, err := ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return nil, err
+	}
+	if err = updateLinkIndex(db, n, content); err != nil {
+		return nil, err
+	}
+	guids := noteLinkGUIDs(content)
+	notes := make([]*Note, len(guids))
+	for i, guid := range guids {
+		if notes[i], err = ns.GetNote(guid); err != nil {
+			notes[i] = &Note{GUID: guid, Notebook: new(Notebook)}
+		}
+	}
+	return notes, nil
+}
+
+// Backlinks returns the notes in the link index that link to the note,
+// sorted by title. Notes that no longer exist are left out.
+func Backlinks(db Storager, ns NotestoreClient, title string) ([]*Note, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	index, err := db.GetLinkIndex()
+	if err != nil {
+		return nil, err
+	}
+	var notes []*Note
+	for guid, e := range index.Notes {
+		if !containsString(e.Links, n.GUID) {
+			continue
+		}
+		if linked, err := ns.GetNote(guid); err == nil {
+			notes = append(notes, linked)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Title < notes[j].Title })
+	return notes, nil
+}
+
+// RebuildLinkIndex indexes the links in all the user's notes. The number
+// of indexed notes is returned.
+func RebuildLinkIndex(db Storager, ns NotestoreClient) (int, error) {
+	index := &LinkIndex{Notes: make(map[string]*LinkIndexEntry)}
+	filter := &NoteFilter{Order: NoteFilterOrderUpdated}
+	for offset := 0; ; offset += noteBatchSize {
+		notes, err := ns.FindNotes(filter, offset, noteBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, n := range notes {
+			content, err := ns.GetNoteContent(n.GUID)
+			if err != nil {
+				return 0, err
+			}
+			index.Notes[n.GUID] = &LinkIndexEntry{Title: n.Title, Links: noteLinkGUIDs(content)}
+		}
+		if len(notes) < noteBatchSize {
+			break
+		}
+	}
+	return len(index.Notes), db.StoreLinkIndex(index)
+}
@@ -0,0 +1,196 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	linkGUID1 = "11111111-1111-1111-1111-111111111111"
+	linkGUID2 = "22222222-2222-2222-2222-222222222222"
+	linkURL2  = "evernote:///view/123/s1/" + linkGUID2 + "/" + linkGUID2 + "/"
+)
+
+func linkedNotestore() *mockNS {
+	notes := map[string]*Note{
+		linkGUID1: &Note{Title: "Plan", GUID: linkGUID1, Notebook: &Notebook{GUID: "NB"}},
+		linkGUID2: &Note{Title: "Budget", GUID: linkGUID2, Notebook: &Notebook{GUID: "NB"}},
+	}
+	contents := map[string]string{
+		linkGUID1: XMLHeader + `<en-note><div>See <a href="` + linkURL2 + `">Budget</a> and <a href="https://www.evernote.com/shard/s1/nl/123/` + linkGUID2 + `/">costs</a></div></en-note>`,
+		linkGUID2: XMLHeader + `<en-note><div>No links, <a href="https://example.com/` + linkGUID1 + `">web</a></div></en-note>`,
+	}
+	return &mockNS{
+		getNote: func(guid string) (*Note, error) {
+			if n, ok := notes[guid]; ok {
+				return n, nil
+			}
+			return nil, ErrNoNoteFound
+		},
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			if f.Words == "" {
+				return []*Note{notes[linkGUID1], notes[linkGUID2]}, nil
+			}
+			for _, n := range notes {
+				if n.Title == f.Words {
+					return []*Note{n}, nil
+				}
+			}
+			return []*Note{}, nil
+		},
+		getNoteContent: func(guid string) (string, error) { return contents[guid], nil },
+		noteLink: func(guid string) (string, error) {
+			return "evernote:///view/123/s1/" + guid + "/" + guid + "/", nil
+		},
+	}
+}
+
+func TestNoteLinkGUIDs(t *testing.T) {
+	ns := linkedNotestore()
+	content, _ := ns.GetNoteContent(linkGUID1)
+	assert.Equal(t, []string{linkGUID2}, noteLinkGUIDs(content), "Links should be deduplicated")
+	content, _ = ns.GetNoteContent(linkGUID2)
+	assert.Empty(t, noteLinkGUIDs(content), "Web links should be ignored")
+}
+
+func TestWikiLinks(t *testing.T) {
+	assert := assert.New(t)
+	ns := linkedNotestore()
+
+	t.Run("to wiki links", func(t *testing.T) {
+		md := "See [Budget](" + linkURL2 + "), [costs](" + linkURL2 + ") and [gone](evernote:///view/1/s1/" + linkGUID1[:35] + "0/x/)"
+		expected := "See [[Budget]], [[Budget|costs]] and [gone](evernote:///view/1/s1/" + linkGUID1[:35] + "0/x/)"
+		actual, guids := toWikiLinks(nil, ns, md)
+		assert.Equal(expected, actual)
+		assert.Equal(map[string]string{"Budget": linkGUID2}, guids, "Linked GUIDs should be returned")
+	})
+	t.Run("resolve wiki links", func(t *testing.T) {
+		md := "See [[Budget]], [[Budget|costs]] and [[Missing]]"
+		expected := "See [Budget](" + linkURL2 + "), [costs](" + linkURL2 + ") and [[Missing]]"
+		assert.Equal(expected, resolveWikiLinks(ns, md, nil))
+	})
+	t.Run("links in code are not resolved", func(t *testing.T) {
+		ns := linkedNotestore()
+		searches := 0
+		findNotes := ns.findNotes
+		ns.findNotes = func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			searches++
+			return findNotes(f, offset, count)
+		}
+		md := "Run `[[ -f x ]]` or ``[[Budget]]``\n\n```sh\nif [[ -f x ]]; then\n```\n\n    [[ -d y ]]\n\n~~~\n[[Budget]]\n~~~\n"
+		assert.Equal(md, resolveWikiLinks(ns, md, nil), "Code should be left as it is")
+		assert.Equal(0, searches, "Links in code should not be searched for")
+		md = "- item\n\n    [[Budget]] in the list\n\n`code` and [[Budget]]"
+		expected := "- item\n\n    [Budget](" + linkURL2 + ") in the list\n\n`code` and [Budget](" + linkURL2 + ")"
+		assert.Equal(expected, resolveWikiLinks(ns, md, nil), "Links outside code should be resolved")
+	})
+	t.Run("known links are not searched for", func(t *testing.T) {
+		ns := linkedNotestore()
+		ns.findNotes = func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			t.Error("Known link should not be searched for")
+			return nil, nil
+		}
+		md := resolveWikiLinks(ns, "[[Old title]]", map[string]string{"Old title": linkGUID2})
+		assert.Equal("[Old title]("+linkURL2+")", md)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		store := &mockStore{}
+		n, err := GetNoteWithContent(store, ns, "guid:"+linkGUID1)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("See [[Budget]] and [[Budget|costs]]", n.MD, "Links should be converted on load")
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		assert.NoError(SaveChanges(ns, n, DefaultNoteOption))
+		assert.Contains(saved.Body, `<a href="`+linkURL2+`">costs</a>`, "Links should be resolved on save")
+	})
+	t.Run("titles are taken from the link index", func(t *testing.T) {
+		ns := linkedNotestore()
+		ns.getNote = func(guid string) (*Note, error) {
+			if guid == linkGUID1 {
+				return &Note{Title: "Plan", GUID: linkGUID1}, nil
+			}
+			t.Error("Indexed note should not be fetched")
+			return nil, ErrNoNoteFound
+		}
+		index := &LinkIndex{Notes: map[string]*LinkIndexEntry{linkGUID2: &LinkIndexEntry{Title: "Old budget"}}}
+		store := &mockStore{getLinkIndex: func() (*LinkIndex, error) { return index, nil }}
+		stores := 0
+		store.storeLinkIndex = func(i *LinkIndex) error {
+			stores++
+			return nil
+		}
+		n, err := GetNoteWithContent(store, ns, "guid:"+linkGUID1)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("See [[Old budget|Budget]] and [[Old budget|costs]]", n.MD)
+		assert.Equal(1, stores, "New entry should be stored")
+		_, err = GetNoteWithContent(store, ns, "guid:"+linkGUID1)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(1, stores, "Unchanged entry should not be stored")
+
+		// The outdated title can't be found but the link is kept.
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		assert.NoError(SaveChanges(ns, n, DefaultNoteOption))
+		assert.Contains(saved.Body, `<a href="`+linkURL2+`">costs</a>`, "Links should be resolved on save")
+	})
+	t.Run("raw save doesn't resolve links", func(t *testing.T) {
+		ns := linkedNotestore()
+		ns.findNotes = func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			t.Error("Raw content should not be searched for links")
+			return nil, nil
+		}
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		n := &Note{GUID: linkGUID1, Body: "<div>[[Budget]]</div>", MD: "[[Budget]]"}
+		assert.NoError(SaveChanges(ns, n, RawNote))
+		assert.Equal(XMLHeader+"<en-note><div>[[Budget]]</div></en-note>", saved.Body)
+	})
+}
+
+func TestLinksAndBacklinks(t *testing.T) {
+	assert := assert.New(t)
+	ns := linkedNotestore()
+	index := new(LinkIndex)
+	store := &mockStore{
+		getLinkIndex:   func() (*LinkIndex, error) { return index, nil },
+		storeLinkIndex: func(i *LinkIndex) error { index = i; return nil },
+	}
+
+	backlinks, err := Backlinks(store, ns, "Budget")
+	assert.NoError(err, "Should not return an error")
+	assert.Empty(backlinks, "Index should be empty")
+
+	links, err := NoteLinks(store, ns, "Plan")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Note{&Note{Title: "Budget", GUID: linkGUID2, Notebook: &Notebook{GUID: "NB"}}}, links)
+
+	backlinks, err = Backlinks(store, ns, "Budget")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Note{&Note{Title: "Plan", GUID: linkGUID1, Notebook: &Notebook{GUID: "NB"}}}, backlinks, "Viewed links should be indexed")
+
+	index = new(LinkIndex)
+	count, err := RebuildLinkIndex(store, ns)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(2, count, "All notes should be indexed")
+	backlinks, err = Backlinks(store, ns, "Budget")
+	assert.NoError(err, "Should not return an error")
+	assert.Len(backlinks, 1, "Rebuilt index should have the backlink")
+}
@@ -0,0 +1,156 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2016 - 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// enmlElements are the HTML elements allowed in ENML.
+var enmlElements = makeSet("a", "abbr", "acronym", "address", "area", "b", "bdo",
+	"big", "blockquote", "br", "caption", "center", "cite", "code", "col",
+	"colgroup", "dd", "del", "dfn", "div", "dl", "dt", "em", "font", "h1", "h2",
+	"h3", "h4", "h5", "h6", "hr", "i", "img", "ins", "kbd", "li", "map", "ol",
+	"p", "pre", "q", "s", "samp", "small", "span", "strike", "strong", "sub",
+	"sup", "table", "tbody", "td", "tfoot", "th", "thead", "tr", "tt", "u",
+	"ul", "var")
+
+// droppedElements are removed together with their content. Other
+// elements not allowed in ENML are replaced by their content.
+var droppedElements = makeSet("applet", "audio", "base", "basefont", "bgsound",
+	"button", "canvas", "embed", "frame", "frameset", "head", "iframe",
+	"input", "isindex", "link", "meta", "noframes", "noscript", "object",
+	"option", "param", "script", "select", "style", "svg", "math",
+	"template", "textarea", "title", "video", "xml")
+
+// blockElements are HTML5 elements that are replaced by a div to keep the
+// layout of the content.
+var blockElements = makeSet("article", "aside", "details", "fieldset",
+	"figcaption", "figure", "footer", "form", "header", "main", "nav",
+	"section", "summary")
+
+// enmlAttributes are the attributes kept on the allowed elements.
+var enmlAttributes = makeSet("abbr", "align", "alt", "axis", "bgcolor",
+	"border", "cellpadding", "cellspacing", "char", "charoff", "cite",
+	"clear", "color", "cols", "colspan", "compact", "coords", "datetime",
+	"dir", "face", "frame", "headers", "height", "href", "hspace", "lang",
+	"name", "nowrap", "rel", "rev", "rows", "rowspan", "rules", "scope",
+	"shape", "size", "span", "src", "start", "style", "summary", "target",
+	"title", "type", "usemap", "valign", "value", "vspace", "width")
+
+// voidElements have no content and are written as empty XML elements.
+var voidElements = makeSet("area", "br", "col", "hr", "img")
+
+// HTMLToENML converts an HTML document or fragment to the content of an
+// en-note element. Elements and attributes that are not allowed in ENML
+// are removed and the result is well-formed XML.
+func HTMLToENML(body string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if b := findBody(doc); b != nil {
+		writeENMLChildren(buf, b)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func findBody(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && n.Data == "body" {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if b := findBody(c); b != nil {
+			return b
+		}
+	}
+	return nil
+}
+
+func writeENMLChildren(buf *bytes.Buffer, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeENML(buf, c)
+	}
+}
+
+func writeENML(buf *bytes.Buffer, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(xmlText(n.Data)))
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes are not kept.
+		return
+	}
+	name := n.Data
+	switch {
+	case droppedElements[name]:
+		return
+	case blockElements[name]:
+		name = "div"
+	case !enmlElements[name]:
+		writeENMLChildren(buf, n)
+		return
+	}
+	buf.WriteString("<" + name)
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !enmlAttributes[a.Key] || !safeAttribute(a) {
+			continue
+		}
+		buf.WriteString(" " + a.Key + `="` + html.EscapeString(xmlText(a.Val)) + `"`)
+	}
+	if voidElements[name] {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteString(">")
+	writeENMLChildren(buf, n)
+	buf.WriteString("</" + name + ">")
+}
+
+// safeAttribute returns false for links that run scripts.
+func safeAttribute(a html.Attribute) bool {
+	if a.Key != "href" && a.Key != "src" {
+		return true
+	}
+	v := strings.ToLower(strings.TrimSpace(a.Val))
+	return !strings.HasPrefix(v, "javascript:") && !strings.HasPrefix(v, "vbscript:")
+}
+
+// xmlText removes the characters that are not allowed in XML.
+func xmlText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func makeSet(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
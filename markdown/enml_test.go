@@ -0,0 +1,72 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLToENML(t *testing.T) {
+	assert := assert.New(t)
+	page := `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Release notes</title>
+  <link rel="stylesheet" href="site.css">
+  <style>body { color: red }</style>
+  <script>track()</script>
+</head>
+<body class="docs" onload="init()">
+  <!-- navigation -->
+  <nav id="top"><a href="/" class="home">Home</a></nav>
+  <article data-id="42">
+    <h1 id="title">Release&nbsp;1.2</h1>
+    <p class="lead">Fixes &amp; <b>improvements</b><br>for everyone.</p>
+    <img src="logo.png" alt="Logo" loading="lazy">
+    <form action="/subscribe"><label>Email <input type="email" name="email"></label><button>Go</button></form>
+    <iframe src="https://example.com/video"></iframe>
+    <p><a href="javascript:alert(1)" onclick="x()">Click</a><hr></p>
+    <table><tr><td colspan="2" tabindex="1">Cell</td></tr></table>
+  </article>
+</body>
+</html>`
+	actual, err := HTMLToENML(page)
+	assert.NoError(err, "Should not return an error")
+	assert.NoError(xml.Unmarshal([]byte("<en-note>"+actual+"</en-note>"), new(struct{})), "Should be well-formed XML")
+	for _, s := range []string{"<script", "<style", "<iframe", "<form", "<input", "<button", "<label", "<nav",
+		"<article", "<link", "<meta", "<title", "id=", "class=", "onload", "onclick", "data-id", "tabindex",
+		"loading=", "javascript:", "<!--", "track()"} {
+		assert.False(strings.Contains(actual, s), "%s should be removed: %s", s, actual)
+	}
+	for _, s := range []string{`<div><a href="/">Home</a></div>`, "<h1>Release\u00a01.2</h1>",
+		"<p>Fixes &amp; <b>improvements</b><br/>for everyone.</p>", `<img src="logo.png" alt="Logo"/>`,
+		"Email ", "<a>Click</a>", "<hr/>", `<td colspan="2">Cell</td>`} {
+		assert.True(strings.Contains(actual, s), "%s should be kept: %s", s, actual)
+	}
+}
+
+func TestHTMLToENMLFragment(t *testing.T) {
+	actual, err := HTMLToENML("<p>One<br>Two</p>")
+	assert.NoError(t, err, "Should not return an error")
+	assert.Equal(t, "<p>One<br/>Two</p>", actual)
+}
@@ -0,0 +1,142 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2016 - 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// Colors used to highlight code blocks.
+const (
+	keywordColor = ansiBlue
+	stringColor  = ansiGreen
+	numberColor  = ansiMagenta
+	commentColor = ansiGray
+)
+
+// hashComments are the languages using # for line comments.
+var hashComments = map[string]bool{
+	"sh": true, "bash": true, "shell": true, "zsh": true, "fish": true,
+	"python": true, "py": true, "ruby": true, "rb": true, "perl": true,
+	"yaml": true, "yml": true, "toml": true, "make": true, "makefile": true,
+	"dockerfile": true, "r": true, "conf": true, "ini": true,
+}
+
+// keywords are highlighted in code blocks with a language. The set is
+// shared by all languages, it covers the common C-like, scripting and
+// shell languages.
+var keywords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "def": true, "default": true, "defer": true, "do": true,
+	"elif": true, "else": true, "enum": true, "esac": true, "export": true,
+	"extends": true, "false": true, "fi": true, "finally": true, "fn": true,
+	"for": true, "from": true, "func": true, "function": true, "go": true,
+	"if": true, "impl": true, "import": true, "in": true, "interface": true,
+	"let": true, "map": true, "match": true, "mut": true, "new": true,
+	"nil": true, "None": true, "null": true, "package": true, "private": true,
+	"pub": true, "public": true, "range": true, "return": true, "select": true,
+	"self": true, "static": true, "struct": true, "switch": true, "then": true,
+	"this": true, "throw": true, "True": true, "False": true, "true": true,
+	"try": true, "type": true, "use": true, "var": true, "void": true,
+	"while": true, "with": true, "yield": true, "done": true, "local": true,
+}
+
+// highlight colors the keywords, strings, numbers and comments in the
+// code. Code without a language is returned as it is.
+func highlight(code, lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return code
+	}
+	hash := hashComments[lang]
+	src := []rune(code)
+	buf := new(bytes.Buffer)
+	colored := func(color string, s []rune) {
+		buf.WriteString(color + string(s) + ansiDefaultColor)
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case hash && c == '#', !hash && c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := indexRune(src, i, '\n')
+			colored(commentColor, src[i:end])
+			i = end
+		case !hash && c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := i + 2
+			for end < len(src) && !(src[end-1] == '*' && src[end] == '/' && end > i+2) {
+				end++
+			}
+			if end < len(src) {
+				end++
+			}
+			colored(commentColor, src[i:end])
+			i = end
+		case c == '"' || c == '\'' || c == '`':
+			end := i + 1
+			for end < len(src) && src[end] != c && (c == '`' || src[end] != '\n') {
+				if src[end] == '\\' && c != '`' {
+					end++
+				}
+				end++
+			}
+			if end < len(src) && src[end] == c {
+				end++
+			}
+			if end > len(src) {
+				end = len(src)
+			}
+			colored(stringColor, src[i:end])
+			i = end
+		case unicode.IsDigit(c):
+			end := i
+			for end < len(src) && (unicode.IsDigit(src[end]) || unicode.IsLetter(src[end]) || src[end] == '.' || src[end] == '_') {
+				end++
+			}
+			colored(numberColor, src[i:end])
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i
+			for end < len(src) && (unicode.IsLetter(src[end]) || unicode.IsDigit(src[end]) || src[end] == '_') {
+				end++
+			}
+			if keywords[string(src[i:end])] {
+				colored(keywordColor, src[i:end])
+			} else {
+				buf.WriteString(string(src[i:end]))
+			}
+			i = end
+		default:
+			buf.WriteRune(c)
+			i++
+		}
+	}
+	return buf.String()
+}
+
+// indexRune returns the index of the first r at or after start, or the
+// length of the slice if there is none.
+func indexRune(src []rune, start int, r rune) int {
+	for i := start; i < len(src); i++ {
+		if src[i] == r {
+			return i
+		}
+	}
+	return len(src)
+}
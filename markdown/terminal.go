@@ -0,0 +1,481 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2016 - 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/russross/blackfriday"
+)
+
+// DefaultTerminalWidth is the width used when the terminal's width is
+// unknown.
+const DefaultTerminalWidth = 80
+
+// ANSI escape codes used by the terminal renderer. Styles are turned off
+// with their own codes instead of a full reset so they can be nested.
+const (
+	ansiBold          = "\x1b[1m"
+	ansiBoldOff       = "\x1b[22m"
+	ansiDim           = "\x1b[2m"
+	ansiItalic        = "\x1b[3m"
+	ansiItalicOff     = "\x1b[23m"
+	ansiUnderline     = "\x1b[4m"
+	ansiUnderlineOff  = "\x1b[24m"
+	ansiStrike        = "\x1b[9m"
+	ansiStrikeOff     = "\x1b[29m"
+	ansiGreen         = "\x1b[32m"
+	ansiYellow        = "\x1b[33m"
+	ansiBlue          = "\x1b[34m"
+	ansiMagenta       = "\x1b[35m"
+	ansiCyan          = "\x1b[36m"
+	ansiGray          = "\x1b[90m"
+	ansiDefaultColor  = "\x1b[39m"
+	ansiHyperlinkOpen = "\x1b]8;;"
+	ansiST            = "\x1b\\"
+)
+
+// Separators used to pass the table cells and rows from the cell and row
+// callbacks to the table callback.
+const (
+	cellSep = '\x1f'
+	rowSep  = '\x1e'
+)
+
+const (
+	codeIndent  = "    "
+	quotePrefix = "│ "
+)
+
+// escapeSequence matches the ANSI escape sequences written by the
+// renderer. They are removed when the visible width of a text is needed.
+var escapeSequence = regexp.MustCompile("\x1b\\[[0-9;]*m|\x1b\\]8;;[^\x1b]*\x1b\\\\")
+
+// TerminalOptions controls how markdown is rendered for the terminal.
+type TerminalOptions struct {
+	// Color enables text styles and colors.
+	Color bool
+	// Hyperlinks renders links as OSC 8 hyperlinks. If false, the link's
+	// URL is written after the link text.
+	Hyperlinks bool
+	// Width is the width of the terminal. DefaultTerminalWidth is used
+	// if it's not set.
+	Width int
+}
+
+// ToTerminal renders the markdown body for display in a terminal.
+func ToTerminal(mdBody string, opts TerminalOptions) string {
+	if opts.Width <= 0 {
+		opts.Width = DefaultTerminalWidth
+	}
+	r := &terminalRenderer{opts: opts}
+	extensions := blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
+		blackfriday.EXTENSION_TABLES |
+		blackfriday.EXTENSION_FENCED_CODE |
+		blackfriday.EXTENSION_AUTOLINK |
+		blackfriday.EXTENSION_STRIKETHROUGH |
+		blackfriday.EXTENSION_SPACE_HEADERS |
+		blackfriday.EXTENSION_FOOTNOTES
+	out := blackfriday.Markdown([]byte(mdBody), r, extensions)
+	return strings.TrimRight(string(out), "\n") + "\n"
+}
+
+// VisibleWidth returns the number of columns the text uses in a terminal.
+// Escape sequences written by ToTerminal are ignored.
+func VisibleWidth(s string) int {
+	return runewidth.StringWidth(escapeSequence.ReplaceAllString(s, ""))
+}
+
+// terminalRenderer is a blackfriday.Renderer writing text with ANSI
+// escape sequences.
+type terminalRenderer struct {
+	opts TerminalOptions
+	// lists holds the next item number of each open list. It's 0 for
+	// unordered lists.
+	lists []int
+}
+
+func (r *terminalRenderer) style(out *bytes.Buffer, on, off string, text []byte) {
+	if !r.opts.Color {
+		out.Write(text)
+		return
+	}
+	out.WriteString(on)
+	out.Write(text)
+	out.WriteString(off)
+}
+
+// blockStart separates a block from the previous one with an empty line.
+func (r *terminalRenderer) blockStart(out *bytes.Buffer) {
+	if out.Len() == 0 {
+		return
+	}
+	b := out.Bytes()
+	switch {
+	case bytes.HasSuffix(b, []byte("\n\n")):
+	case bytes.HasSuffix(b, []byte("\n")):
+		out.WriteByte('\n')
+	default:
+		out.WriteString("\n\n")
+	}
+}
+
+func (r *terminalRenderer) BlockCode(out *bytes.Buffer, text []byte, lang string) {
+	r.blockStart(out)
+	code := strings.TrimRight(string(bytes.Map(dropControl, text)), "\n")
+	if r.opts.Color {
+		code = highlight(code, lang)
+	}
+	for _, line := range strings.Split(code, "\n") {
+		out.WriteString(codeIndent + line + "\n")
+	}
+}
+
+func (r *terminalRenderer) BlockQuote(out *bytes.Buffer, text []byte) {
+	r.blockStart(out)
+	prefix := quotePrefix
+	if r.opts.Color {
+		prefix = ansiGray + quotePrefix + ansiDefaultColor
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(text), "\n"), "\n") {
+		out.WriteString(prefix + line + "\n")
+	}
+}
+
+func (r *terminalRenderer) BlockHtml(out *bytes.Buffer, text []byte) {
+	r.blockStart(out)
+	out.Write(bytes.TrimRight(bytes.Map(dropControl, text), "\n"))
+	out.WriteByte('\n')
+}
+
+func (r *terminalRenderer) Header(out *bytes.Buffer, text func() bool, level int, id string) {
+	r.blockStart(out)
+	marker := out.Len()
+	color := ansiBlue
+	switch level {
+	case 1:
+		color = ansiMagenta
+	case 2:
+		color = ansiCyan
+	}
+	if r.opts.Color {
+		out.WriteString(ansiBold + color)
+	}
+	out.WriteString(strings.Repeat("#", level) + " ")
+	if !text() {
+		out.Truncate(marker)
+		return
+	}
+	if r.opts.Color {
+		out.WriteString(ansiDefaultColor + ansiBoldOff)
+	}
+	out.WriteByte('\n')
+}
+
+func (r *terminalRenderer) HRule(out *bytes.Buffer) {
+	r.blockStart(out)
+	width := r.opts.Width
+	if width > DefaultTerminalWidth {
+		width = DefaultTerminalWidth
+	}
+	r.style(out, ansiGray, ansiDefaultColor, []byte(strings.Repeat("─", width)))
+	out.WriteByte('\n')
+}
+
+func (r *terminalRenderer) List(out *bytes.Buffer, text func() bool, flags int) {
+	if len(r.lists) == 0 {
+		r.blockStart(out)
+	} else if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	marker := out.Len()
+	next := 0
+	if flags&blackfriday.LIST_TYPE_ORDERED != 0 {
+		next = 1
+	}
+	r.lists = append(r.lists, next)
+	ok := text()
+	r.lists = r.lists[:len(r.lists)-1]
+	if !ok {
+		out.Truncate(marker)
+	}
+}
+
+func (r *terminalRenderer) ListItem(out *bytes.Buffer, text []byte, flags int) {
+	bullet := "• "
+	if i := len(r.lists) - 1; i >= 0 && r.lists[i] > 0 {
+		bullet = fmt.Sprintf("%d. ", r.lists[i])
+		r.lists[i]++
+	}
+	item := strings.Trim(string(text), "\n")
+	switch {
+	case strings.HasPrefix(item, "[ ] "):
+		bullet, item = "☐ ", item[4:]
+	case strings.HasPrefix(item, "[x] "), strings.HasPrefix(item, "[X] "):
+		bullet, item = "☑ ", item[4:]
+		if r.opts.Color {
+			bullet = ansiGreen + bullet + ansiDefaultColor
+		}
+	}
+	indent := strings.Repeat(" ", VisibleWidth(bullet))
+	lines := strings.Split(item, "\n")
+	for i, line := range lines {
+		if i == 0 {
+			out.WriteString(bullet + line + "\n")
+		} else if line == "" {
+			out.WriteByte('\n')
+		} else {
+			out.WriteString(indent + line + "\n")
+		}
+	}
+	if flags&blackfriday.LIST_ITEM_CONTAINS_BLOCK != 0 && flags&blackfriday.LIST_ITEM_END_OF_LIST == 0 {
+		out.WriteByte('\n')
+	}
+}
+
+func (r *terminalRenderer) Paragraph(out *bytes.Buffer, text func() bool) {
+	r.blockStart(out)
+	marker := out.Len()
+	if !text() {
+		out.Truncate(marker)
+		return
+	}
+	out.WriteByte('\n')
+}
+
+func (r *terminalRenderer) Table(out *bytes.Buffer, header []byte, body []byte, columnData []int) {
+	r.blockStart(out)
+	rows := append(splitTableRows(header), splitTableRows(body)...)
+	widths := make([]int, len(columnData))
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && VisibleWidth(cell) > widths[i] {
+				widths[i] = VisibleWidth(cell)
+			}
+		}
+	}
+	sep := " │ "
+	if r.opts.Color {
+		sep = ansiGray + sep + ansiDefaultColor
+	}
+	headerRows := len(splitTableRows(header))
+	for n, row := range rows {
+		cells := make([]string, len(widths))
+		for i := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			if n < headerRows && r.opts.Color {
+				cell = ansiBold + cell + ansiBoldOff
+			}
+			cells[i] = alignCell(cell, widths[i], columnData[i])
+		}
+		out.WriteString(strings.TrimRight(strings.Join(cells, sep), " ") + "\n")
+		if n == headerRows-1 {
+			rules := make([]string, len(widths))
+			for i, w := range widths {
+				rules[i] = strings.Repeat("─", w)
+			}
+			rule := strings.Join(rules, "─┼─")
+			r.style(out, ansiGray, ansiDefaultColor, []byte(rule))
+			out.WriteByte('\n')
+		}
+	}
+}
+
+func splitTableRows(b []byte) [][]string {
+	var rows [][]string
+	for _, row := range strings.Split(string(b), string(rowSep)) {
+		if row == "" {
+			continue
+		}
+		cells := strings.Split(row, string(cellSep))
+		rows = append(rows, cells[:len(cells)-1])
+	}
+	return rows
+}
+
+func alignCell(cell string, width, align int) string {
+	pad := width - VisibleWidth(cell)
+	if pad <= 0 {
+		return cell
+	}
+	switch align {
+	case blackfriday.TABLE_ALIGNMENT_RIGHT:
+		return strings.Repeat(" ", pad) + cell
+	case blackfriday.TABLE_ALIGNMENT_CENTER:
+		left := pad / 2
+		return strings.Repeat(" ", left) + cell + strings.Repeat(" ", pad-left)
+	default:
+		return cell + strings.Repeat(" ", pad)
+	}
+}
+
+func (r *terminalRenderer) TableRow(out *bytes.Buffer, text []byte) {
+	out.Write(text)
+	out.WriteByte(rowSep)
+}
+
+func (r *terminalRenderer) TableHeaderCell(out *bytes.Buffer, text []byte, flags int) {
+	r.TableCell(out, text, flags)
+}
+
+func (r *terminalRenderer) TableCell(out *bytes.Buffer, text []byte, flags int) {
+	out.Write(bytes.TrimSpace(text))
+	out.WriteByte(cellSep)
+}
+
+func (r *terminalRenderer) Footnotes(out *bytes.Buffer, text func() bool) {
+	r.blockStart(out)
+	r.HRule(out)
+	marker := out.Len()
+	r.lists = append(r.lists, 1)
+	ok := text()
+	r.lists = r.lists[:len(r.lists)-1]
+	if !ok {
+		out.Truncate(marker)
+	}
+}
+
+func (r *terminalRenderer) FootnoteItem(out *bytes.Buffer, name, text []byte, flags int) {
+	r.ListItem(out, text, flags)
+}
+
+func (r *terminalRenderer) TitleBlock(out *bytes.Buffer, text []byte) {
+	r.blockStart(out)
+	r.style(out, ansiBold, ansiBoldOff, bytes.Map(dropControl, text))
+	out.WriteByte('\n')
+}
+
+func (r *terminalRenderer) AutoLink(out *bytes.Buffer, link []byte, kind int) {
+	url := link
+	if kind == blackfriday.LINK_TYPE_EMAIL && !bytes.HasPrefix(link, []byte("mailto:")) {
+		url = append([]byte("mailto:"), link...)
+	}
+	r.link(out, url, link)
+}
+
+func (r *terminalRenderer) link(out *bytes.Buffer, url, content []byte) {
+	url = bytes.Map(dropControl, url)
+	content = bytes.Map(dropControl, content)
+	if !r.opts.Hyperlinks {
+		r.style(out, ansiUnderline+ansiBlue, ansiDefaultColor+ansiUnderlineOff, content)
+		if !bytes.Equal(url, content) {
+			out.WriteString(" (" + string(url) + ")")
+		}
+		return
+	}
+	out.WriteString(ansiHyperlinkOpen)
+	out.Write(url)
+	out.WriteString(ansiST)
+	r.style(out, ansiUnderline+ansiBlue, ansiDefaultColor+ansiUnderlineOff, content)
+	out.WriteString(ansiHyperlinkOpen + ansiST)
+}
+
+// span styles the text if colors are enabled. Without colors, the text is
+// surrounded by the markdown marker instead.
+func (r *terminalRenderer) span(out *bytes.Buffer, on, off, marker string, text []byte) {
+	if !r.opts.Color {
+		out.WriteString(marker)
+		out.Write(text)
+		out.WriteString(marker)
+		return
+	}
+	r.style(out, on, off, text)
+}
+
+func (r *terminalRenderer) CodeSpan(out *bytes.Buffer, text []byte) {
+	r.span(out, ansiYellow, ansiDefaultColor, "`", bytes.Map(dropControl, text))
+}
+
+func (r *terminalRenderer) DoubleEmphasis(out *bytes.Buffer, text []byte) {
+	r.span(out, ansiBold, ansiBoldOff, "**", text)
+}
+
+func (r *terminalRenderer) Emphasis(out *bytes.Buffer, text []byte) {
+	r.span(out, ansiItalic, ansiItalicOff, "*", text)
+}
+
+func (r *terminalRenderer) Image(out *bytes.Buffer, link []byte, title []byte, alt []byte) {
+	content := []byte("[image]")
+	if len(alt) > 0 {
+		content = []byte("[image: " + string(alt) + "]")
+	}
+	r.link(out, link, content)
+}
+
+func (r *terminalRenderer) LineBreak(out *bytes.Buffer) {
+	out.WriteByte('\n')
+}
+
+func (r *terminalRenderer) Link(out *bytes.Buffer, link []byte, title []byte, content []byte) {
+	r.link(out, link, content)
+}
+
+func (r *terminalRenderer) RawHtmlTag(out *bytes.Buffer, tag []byte) {
+	out.Write(bytes.Map(dropControl, tag))
+}
+
+func (r *terminalRenderer) TripleEmphasis(out *bytes.Buffer, text []byte) {
+	r.span(out, ansiBold+ansiItalic, ansiItalicOff+ansiBoldOff, "***", text)
+}
+
+func (r *terminalRenderer) StrikeThrough(out *bytes.Buffer, text []byte) {
+	r.span(out, ansiStrike, ansiStrikeOff, "~~", text)
+}
+
+func (r *terminalRenderer) FootnoteRef(out *bytes.Buffer, ref []byte, id int) {
+	fmt.Fprintf(out, "[%d]", id)
+}
+
+func (r *terminalRenderer) Entity(out *bytes.Buffer, entity []byte) {
+	out.WriteString(StripControl(html.UnescapeString(string(entity))))
+}
+
+func (r *terminalRenderer) NormalText(out *bytes.Buffer, text []byte) {
+	out.Write(bytes.Map(dropControl, text))
+}
+
+func (r *terminalRenderer) DocumentHeader(out *bytes.Buffer) {}
+
+func (r *terminalRenderer) DocumentFooter(out *bytes.Buffer) {}
+
+func (r *terminalRenderer) GetFlags() int {
+	return 0
+}
+
+// StripControl removes control characters, except new lines and tabs,
+// from text that is written to a terminal.
+func StripControl(s string) string {
+	return strings.Map(dropControl, s)
+}
+
+// dropControl removes control characters, except new lines and tabs, so
+// the note's content can't write its own escape sequences.
+func dropControl(r rune) rune {
+	if r < 0x20 && r != '\n' && r != '\t' || r == 0x7f {
+		return -1
+	}
+	return r
+}
@@ -0,0 +1,90 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2016 - 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToTerminalPlain(t *testing.T) {
+	assert := assert.New(t)
+	md := "# Title\n\nSome **bold** text with `code` and a [link](http://example.com).\n\n" +
+		"* one\n    * nested\n* [ ] todo\n* [x] done\n\n" +
+		"| Name | Value |\n|------|------:|\n| a | 1 |\n| long name | 22 |\n\n" +
+		"```\ncode block\n```\n\n> quote\n"
+	expected := "# Title\n\n" +
+		"Some **bold** text with `code` and a link (http://example.com).\n\n" +
+		"• one\n  • nested\n☐ todo\n☑ done\n\n" +
+		"Name      │ Value\n──────────┼──────\na         │     1\nlong name │    22\n\n" +
+		"    code block\n\n│ quote\n"
+	assert.Equal(expected, ToTerminal(md, TerminalOptions{}), "Wrong rendering")
+}
+
+func TestToTerminalOrderedList(t *testing.T) {
+	assert := assert.New(t)
+	md := "1. first\n2. second\n    1. sub\n3. third\n"
+	expected := "1. first\n2. second\n   1. sub\n3. third\n"
+	assert.Equal(expected, ToTerminal(md, TerminalOptions{}), "Wrong list numbering")
+}
+
+func TestToTerminalStyles(t *testing.T) {
+	assert := assert.New(t)
+	out := ToTerminal("**bold** [link](http://example.com)", TerminalOptions{Color: true, Hyperlinks: true})
+	assert.Contains(out, ansiBold+"bold"+ansiBoldOff, "Bold should be styled")
+	assert.Contains(out, ansiHyperlinkOpen+"http://example.com"+ansiST, "Link should be an OSC 8 hyperlink")
+	assert.Equal("bold link\n", escapeSequence.ReplaceAllString(out, ""), "Escape sequences should be removable")
+}
+
+func TestToTerminalDropsControlCharacters(t *testing.T) {
+	out := ToTerminal("text \x1b[31mred", TerminalOptions{})
+	assert.Equal(t, "text [31mred\n", out, "Escape characters in the note should be removed")
+}
+
+func TestToTerminalDropsControlCharactersInCode(t *testing.T) {
+	assert := assert.New(t)
+	md := "a `x\x1b]0;pwn\x07y`\n\n```\n\x1b[2Jcode\n```\n\n<div>\x1b[2J</div>\n\nb <span \x1b[2J> &#x1b;"
+	for _, opts := range []TerminalOptions{{}, {Color: true}} {
+		out := ToTerminal(md, opts)
+		assert.NotContains(out, "\x1b]0;", "OSC sequence from the note should be removed")
+		assert.NotContains(out, "\x1b[2J", "Escape sequence from the note should be removed")
+		assert.NotContains(out, "\x07", "Bell character from the note should be removed")
+	}
+	assert.Equal("a `x]0;pwny`\n\n    [2Jcode\n", ToTerminal("a `x\x1b]0;pwn\x07y`\n\n```\n\x1b[2Jcode\n```", TerminalOptions{}), "Wrong rendering")
+}
+
+func TestVisibleWidth(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(4, VisibleWidth(ansiBold+"bold"+ansiBoldOff), "Styles should not count")
+	assert.Equal(4, VisibleWidth(ansiHyperlinkOpen+"http://x"+ansiST+"link"+ansiHyperlinkOpen+ansiST), "Hyperlinks should not count")
+	assert.Equal(4, VisibleWidth("日本"), "Wide runes should count as two")
+}
+
+func TestHighlight(t *testing.T) {
+	assert := assert.New(t)
+	code := "func main() { return \"s\" } // done"
+	assert.Equal(code, highlight(code, ""), "Code without a language should not be highlighted")
+	expected := keywordColor + "func" + ansiDefaultColor + " main() { " +
+		keywordColor + "return" + ansiDefaultColor + " " +
+		stringColor + "\"s\"" + ansiDefaultColor + " } " +
+		commentColor + "// done" + ansiDefaultColor
+	assert.Equal(expected, highlight(code, "go"), "Wrong highlighting")
+	assert.Equal(commentColor+"# comment"+ansiDefaultColor, highlight("# comment", "sh"), "Wrong shell comment")
+	assert.Equal("a "+commentColor+"/* b */"+ansiDefaultColor+" c", highlight("a /* b */ c", "c"), "Wrong block comment")
+}
@@ -25,7 +25,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 
 	"github.com/TcM1911/clinote/markdown"
@@ -72,6 +71,12 @@ const (
 	// UseRecoveryPointNote should be used to signal that the user wants to
 	// reopen the note that the note store failed to save.
 	UseRecoveryPointNote
+	// ConfirmChanges shows the changes made in the editor and asks
+	// the user to confirm them before the note is saved.
+	ConfirmChanges
+	// AddTimestamp adds a heading with the current time before content
+	// that is appended or prepended to a note.
+	AddTimestamp
 )
 
 // Note is the structure of an Evernote note.
@@ -88,10 +93,53 @@ type Note struct {
 	Deleted bool
 	// Notebook the note belongs to.
 	Notebook *Notebook
+	// Tags are the names of the tags assigned to the note.
+	Tags []string
 	// Created
 	Created int64
 	// Updated
 	Updated int64
+	// Attributes are the note's metadata attributes. Only set for
+	// exported notes.
+	Attributes *NoteAttributes
+	// Resources are the files attached to the note. Only set for
+	// exported notes.
+	Resources []*Resource
+	// linkGUIDs are the GUIDs of the notes linked to in MD, keyed by the
+	// title used in the [[Note Title]] links.
+	linkGUIDs map[string]string
+}
+
+// NoteAttributes holds the metadata attributes of a note. Timestamps
+// are in milliseconds and zero if not set.
+type NoteAttributes struct {
+	Author            string
+	Source            string
+	SourceURL         string
+	SourceApplication string
+	PlaceName         string
+	SubjectDate       int64
+	ReminderOrder     int64
+	ReminderTime      int64
+	ReminderDoneTime  int64
+	Latitude          *float64
+	Longitude         *float64
+	Altitude          *float64
+}
+
+// Resource is a file attached to a note.
+type Resource struct {
+	// Data is the content of the file.
+	Data []byte
+	// Mime is the file's mime type.
+	Mime string
+	// FileName is the name of the file.
+	FileName string
+	// SourceURL is the URL the file was downloaded from.
+	SourceURL string
+	// Attachment is true if the file is displayed as an attachment
+	// rather than inline.
+	Attachment bool
 }
 
 // Hash returns the hash for the note. If raw equals true, the raw
@@ -115,6 +163,8 @@ type NoteFilter struct {
 	Words string
 	// Order
 	Order int32
+	// Inactive restricts the search to notes in the trash can.
+	Inactive bool
 }
 
 // FindNotes searches for notes.
@@ -122,67 +172,45 @@ func FindNotes(ns NotestoreClient, filter *NoteFilter, offset int, count int) ([
 	return ns.FindNotes(filter, offset, count)
 }
 
-// GetNote gets the note metadata in the notebook from the server.
-// If the notebook is an empty string, the first matching note will
-// be returned.
+// GetNote gets the note metadata from the server. The title can be
+// any note reference accepted by ResolveNote. If the notebook is not
+// an empty string, the search is restricted to the notebook.
 func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, error) {
-	// Check if the title is a number. If it is
-	// assume that the user wants to get the note
-	// from a saved search.
-	index, err := strconv.Atoi(title)
-	if err == nil && index > 0 {
-		// Get note from saved search
-		notes, err := db.GetSearch()
-		if err != nil {
-			return nil, err
-		}
-		if index <= len(notes) {
-			return notes[index-1], nil
-		}
-	}
+	return ResolveNote(db, ns, title, notebook)
+}
 
-	filter := new(NoteFilter)
-	if notebook != "" {
-		nb, err := findNotebook(db, ns, notebook)
-		if err != nil {
-			return nil, err
-		}
-		filter.NotebookGUID = nb.GUID
-	}
-	filter.Words = title
-	notes, err := ns.FindNotes(filter, 0, 20)
+// GetNoteWithContent returns the note with content from the user's notestore.
+func GetNoteWithContent(db Storager, ns NotestoreClient, title string) (*Note, error) {
+	n, err := GetNote(db, ns, title, "")
 	if err != nil {
 		return nil, err
 	}
-	var note *Note
-	for _, n := range notes {
-		if n.Title == title {
-			note = n
-			break
-		}
-	}
-	if note == nil {
-		return nil, ErrNoNoteFound
+	if err = loadNoteContent(db, ns, n); err != nil {
+		return nil, err
 	}
-	return note, nil
+	// The link index is only a cache so failing to update it isn't an error.
+	updateLinkIndex(db, n, n.Body)
+	return n, nil
 }
 
-// GetNoteWithContent returns the note with content from the user's notestore.
-func GetNoteWithContent(db Storager, ns NotestoreClient, title string) (*Note, error) {
-	n, err := GetNote(db, ns, title, "")
+// loadNoteContent gets the note's content from the notestore and sets
+// both the body and the markdown version of it. Links to other notes are
+// converted into [[Note Title]] links in the markdown version.
+func loadNoteContent(db Storager, ns NotestoreClient, n *Note) error {
 	content, err := ns.GetNoteContent(n.GUID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	err = decodeXML(content, n)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	n.MD, err = markdown.FromHTML(n.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return n, nil
+	n.MD, n.linkGUIDs = toWikiLinks(db, ns, n.MD)
+	return nil
 }
 
 // SaveChanges updates the changes to the note on the server.
@@ -228,12 +256,10 @@ func DeleteNote(db Storager, ns NotestoreClient, title, notebook string) error {
 }
 
 func saveChanges(ns NotestoreClient, n *Note, updateContent, useRawContent bool) error {
-	if updateContent {
-		body := toXML(n.MD)
-		if useRawContent {
-			body = fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, n.Body)
-		}
-		n.Body = body
+	if updateContent && useRawContent {
+		n.Body = fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, n.Body)
+	} else if updateContent {
+		n.Body = toXML(resolveWikiLinks(ns, n.MD, n.linkGUIDs))
 	}
 	err := ns.UpdateNote(n)
 	if err != nil {
@@ -246,7 +272,7 @@ func saveChanges(ns NotestoreClient, n *Note, updateContent, useRawContent bool)
 func SaveNewNote(ns NotestoreClient, n *Note, raw bool) error {
 	var body string
 	if !raw && n.MD != "" {
-		body = toXML(n.MD)
+		body = toXML(resolveWikiLinks(ns, n.MD, n.linkGUIDs))
 	} else if raw {
 		body = fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, n.Body)
 	} else {
@@ -260,7 +286,8 @@ func SaveNewNote(ns NotestoreClient, n *Note, raw bool) error {
 }
 
 // EditNote opens the editor so the user can edit the note. Once the user closes the
-// editor, the note is saved to the notestore.
+// editor, the note is saved to the notestore. If the ConfirmChanges option is set,
+// the client's Confirmer is asked before the changes are saved.
 func EditNote(client *Client, title string, opts NoteOption) error {
 	db, ns := client.Store, client.NoteStore
 	var note *Note
@@ -283,21 +310,43 @@ func EditNote(client *Client, title string, opts NoteOption) error {
 	}
 	note.Notebook = nb
 	initialNotebook := getNotebookName(note)
-	cacheFile, err := editNote(client, note, opts)
-	if err != nil {
-		return err
-	}
-	defer cacheFile.CloseAndRemove()
-	err = parseNote(cacheFile, note, opts)
-	if err != nil {
-		return err
-	}
-	err = checkForNotebookAndUpdate(client, note, initialNotebook)
-	if err != nil {
-		return err
+	var original *Note
+	if opts&ConfirmChanges != 0 {
+		if client.Confirmer == nil {
+			return ErrNoConfirmer
+		}
+		original = copyNote(note)
 	}
-	if bytes.Equal(oldHash, note.Hash(opts&RawNote != 0)) && initialNotebook == note.Notebook.Name {
-		return nil
+	for {
+		cacheFile, err := editNote(client, note, opts)
+		if err != nil {
+			return err
+		}
+		err = parseNote(cacheFile, note, opts)
+		cacheFile.CloseAndRemove()
+		if err != nil {
+			return err
+		}
+		err = checkForNotebookAndUpdate(client, note, initialNotebook)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(oldHash, note.Hash(opts&RawNote != 0)) && initialNotebook == note.Notebook.Name {
+			return nil
+		}
+		if original == nil {
+			break
+		}
+		action, err := client.Confirmer.Confirm(original, note, opts)
+		if err != nil {
+			return err
+		}
+		if action == DiscardEdit {
+			return nil
+		}
+		if action == SaveEdit {
+			break
+		}
 	}
 	err = SaveChanges(ns, note, opts)
 	if err != nil {
@@ -305,8 +354,10 @@ func EditNote(client *Client, title string, opts NoteOption) error {
 		if saveErr != nil {
 			err = errors.New("Error when saving note: " + err.Error() + "\nFailed to create recovery point: " + saveErr.Error())
 		}
+		return err
 	}
-	return err
+	updateLinkIndex(db, note, note.Body)
+	return nil
 }
 
 // CreateAndEditNewNote creates a new note and opens it in the client's editor.
@@ -341,6 +392,16 @@ func checkForNotebookAndUpdate(client *Client, note *Note, initialNotebook strin
 	return nil
 }
 
+// copyNote returns a copy of the note that doesn't share the notebook.
+func copyNote(note *Note) *Note {
+	c := *note
+	if note.Notebook != nil {
+		nb := *note.Notebook
+		c.Notebook = &nb
+	}
+	return &c
+}
+
 // getNotebookName returns the Notebook name or an empty string.
 func getNotebookName(note *Note) string {
 	if note.Notebook == nil {
@@ -501,6 +562,24 @@ func WriteNote(w io.Writer, n *Note, opts NoteOption) error {
 	return err
 }
 
+// WriteRenderedNote writes the note rendered for display in a terminal.
+func WriteRenderedNote(w io.Writer, n *Note, opts markdown.TerminalOptions) error {
+	buf := new(bytes.Buffer)
+	title := markdown.StripControl(n.Title)
+	if opts.Color {
+		buf.WriteString(colorBold + title + colorReset + "\n")
+	} else {
+		buf.WriteString(title + "\n")
+	}
+	if n.Notebook != nil && n.Notebook.Name != "" {
+		buf.WriteString("Notebook: " + markdown.StripControl(n.Notebook.Name) + "\n")
+	}
+	buf.WriteString("\n")
+	buf.WriteString(markdown.ToTerminal(n.MD, opts))
+	_, err := buf.WriteTo(w)
+	return err
+}
+
 func toXML(mdBody string) string {
 	b := []byte("")
 	content := bytes.NewBuffer(b)
@@ -20,9 +20,11 @@ package clinote
 import (
 	"bytes"
 	"errors"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/TcM1911/clinote/markdown"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -648,6 +650,62 @@ func TestEditNote(t *testing.T) {
 		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
 	})
 
+	t.Run("confirm_save_changes", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("added text")
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+		var original, edited *Note
+		c.Confirmer = &mockConfirmer{confirm: func(o, e *Note, _ NoteOption) (EditAction, error) {
+			original, edited = o, e
+			return SaveEdit, nil
+		}}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption|ConfirmChanges)
+		assert.NoError(err, "Should not return an error")
+		assert.NotNil(savedNote, "Note should be saved")
+		assert.NotContains(original.MD, "added text", "Original should not include the changes")
+		assert.Contains(edited.MD, "added text", "Edited should include the changes")
+	})
+
+	t.Run("confirm_discard_changes", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("added text")
+		saveNoteCalled := false
+		ns.updateNote = func(*Note) error { saveNoteCalled = true; return nil }
+		c.Confirmer = &mockConfirmer{confirm: func(*Note, *Note, NoteOption) (EditAction, error) { return DiscardEdit, nil }}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption|ConfirmChanges)
+		assert.NoError(err, "Should not return an error")
+		assert.False(saveNoteCalled, "Should not save discarded changes")
+	})
+
+	t.Run("confirm_reedit_changes", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("added text")
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+		editCount := 0
+		c.Editor = &mockEditor{edit: func(file CacheFile) error {
+			editCount++
+			cache := file.(*mockCacheFile)
+			_, err := cache.buffer.WriteString("\nedit " + strconv.Itoa(editCount) + "\n")
+			return err
+		}}
+		actions := []EditAction{ReEdit, SaveEdit}
+		c.Confirmer = &mockConfirmer{confirm: func(*Note, *Note, NoteOption) (EditAction, error) {
+			a := actions[0]
+			actions = actions[1:]
+			return a, nil
+		}}
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption|ConfirmChanges)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(2, editCount, "Should open the editor twice")
+		assert.Contains(savedNote.Body, "edit 1", "Should keep the first edit")
+		assert.Contains(savedNote.Body, "edit 2", "Should include the second edit")
+	})
+
+	t.Run("confirm_without_confirmer", func(t *testing.T) {
+		c, _, _, expectedNote, _ := setupClient("added text")
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption|ConfirmChanges)
+		assert.Equal(ErrNoConfirmer, err, "Wrong error returned")
+	})
+
 	t.Run("handle_error_from_GetNotebook", func(t *testing.T) {
 		c, ns, _, expectedNote, _, _ := setupClientAndStore("added text")
 		ns.getNotebook = func(guid string) (*Notebook, error) { return nil, expectedError }
@@ -774,6 +832,14 @@ notebook: Name of the notebook
 	})
 }
 
+func TestWriteRenderedNoteDropsControlCharacters(t *testing.T) {
+	buf := new(bytes.Buffer)
+	n := &Note{Title: "Title\x1b]0;pwn\x07", Notebook: &Notebook{Name: "Book\x1b[2J"}, MD: "Body"}
+	err := WriteRenderedNote(buf, n, markdown.TerminalOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Title]0;pwn\nNotebook: Book[2J\n\nBody\n", buf.String(), "Escape characters should be removed")
+}
+
 func nsWithNote(note *Note) *mockNS {
 	notes := []*Note{&Note{Title: "Other note"}, note}
 	ns := new(mockNS)
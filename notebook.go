@@ -25,8 +25,15 @@ var (
 	// ErrNoNotebookCached is returned when trying to update a notebook
 	// that hasn't been pulled from the server.
 	ErrNoNotebookCached = errors.New("no notebook found")
+	// ErrSameNotebook is returned if the notes should be moved to the
+	// notebook that is being deleted.
+	ErrSameNotebook = errors.New("can't move notes to the notebook being deleted")
 )
 
+// noteBatchSize is the number of notes requested per search when moving
+// notes between notebooks.
+const noteBatchSize = 50
+
 // Notebook is a struct for the notebook.
 type Notebook struct {
 	// Name is the notebook's name
@@ -35,6 +42,9 @@ type Notebook struct {
 	GUID string
 	// Stack is the stack that the notebook belongs too.
 	Stack string
+	// Default is true if new notes are created in the notebook when
+	// no notebook is given.
+	Default bool
 }
 
 // UpdateNotebook updates the notebook.
@@ -63,6 +73,10 @@ func findNotebook(db Storager, ns NotestoreClient, name string) (*Notebook, erro
 	if err != nil {
 		return nil, err
 	}
+	return notebookByName(bs, name)
+}
+
+func notebookByName(bs []*Notebook, name string) (*Notebook, error) {
 	for _, b := range bs {
 		if b.Name == name {
 			return b, nil
@@ -97,7 +111,86 @@ func GetNotebook(ns NotestoreClient, guid string) (*Notebook, error) {
 	return ns.GetNotebook(guid)
 }
 
+// SetDefaultNotebook makes the notebook the user's default notebook.
+func SetDefaultNotebook(db Storager, ns NotestoreClient, name string) error {
+	// The notebooks are fetched from the notestore since it can only
+	// update the notebooks it has returned.
+	bs, err := GetNotebooks(db, ns, true)
+	if err != nil {
+		return err
+	}
+	b, err := notebookByName(bs, name)
+	if err != nil {
+		return err
+	}
+	b.Default = true
+	if err = ns.UpdateNotebook(b); err != nil {
+		return err
+	}
+	_, err = GetNotebooks(db, ns, true)
+	return err
+}
+
 // CreateNotebook creates a new notebook.
 func CreateNotebook(ns NotestoreClient, notebook *Notebook, defaultNotebook bool) error {
 	return ns.CreateNotebook(notebook, defaultNotebook)
 }
+
+// DeleteNotebook permanently removes the notebook. If moveTo is not an
+// empty string, the notes in the notebook are moved to that notebook
+// first. Otherwise the notes are removed together with the notebook.
+func DeleteNotebook(db Storager, ns NotestoreClient, name, moveTo string) error {
+	b, err := findNotebook(db, ns, name)
+	if err != nil {
+		return err
+	}
+	if moveTo != "" {
+		target, err := findNotebook(db, ns, moveTo)
+		if err != nil {
+			return err
+		}
+		if target.GUID == b.GUID {
+			return ErrSameNotebook
+		}
+		if err = moveNotes(ns, b, target); err != nil {
+			return err
+		}
+	}
+	if err = ns.ExpungeNotebook(b.GUID); err != nil {
+		return err
+	}
+	_, err = GetNotebooks(db, ns, true)
+	return err
+}
+
+// moveNotes moves the notes in the from notebook to the to notebook. All
+// notes are found before any is moved so the pages don't change while
+// they are fetched. Searching again after moving could return the moved
+// notes while the search index is behind.
+func moveNotes(ns NotestoreClient, from, to *Notebook) error {
+	filter := &NoteFilter{NotebookGUID: from.GUID}
+	var notes []*Note
+	for {
+		batch, err := ns.FindNotes(filter, len(notes), noteBatchSize)
+		if err != nil {
+			return err
+		}
+		notes = append(notes, batch...)
+		if len(batch) < noteBatchSize {
+			break
+		}
+	}
+	for _, n := range notes {
+		n.Notebook = to
+		if err := ns.UpdateNote(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNoteCounts returns the number of notes in each notebook, keyed by
+// the notebook's GUID.
+func GetNoteCounts(ns NotestoreClient) (map[string]int, error) {
+	return ns.GetNoteCounts()
+}
@@ -19,6 +19,7 @@ package clinote
 
 import (
 	"errors"
+	"strconv"
 	"testing"
 	"time"
 
@@ -138,3 +139,128 @@ func TestUpdateNotebook(t *testing.T) {
 		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
 	})
 }
+
+func TestDeleteNotebook(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	setupNS := func() *mockNS {
+		return &mockNS{getAllNotebooks: func() ([]*Notebook, error) {
+			return []*Notebook{&Notebook{Name: "Old", GUID: "OLD"}, &Notebook{Name: "New", GUID: "NEW"}}, nil
+		}}
+	}
+	t.Run("expunge notebook", func(t *testing.T) {
+		ns := setupNS()
+		var expunged string
+		ns.expungeNotebook = func(guid string) error { expunged = guid; return nil }
+		assert.NoError(DeleteNotebook(store, ns, "Old", ""), "Should not return an error")
+		assert.Equal("OLD", expunged, "Wrong notebook expunged")
+	})
+	t.Run("move notes before expunging", func(t *testing.T) {
+		ns := setupNS()
+		notes := []*Note{&Note{Title: "Note1", GUID: "1"}, &Note{Title: "Note2", GUID: "2"}}
+		ns.findNotes = func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			assert.Equal("OLD", f.NotebookGUID, "Wrong notebook searched")
+			found := []*Note{}
+			for _, n := range notes {
+				if n.Notebook == nil {
+					found = append(found, n)
+				}
+			}
+			return found, nil
+		}
+		var moved []string
+		ns.updateNote = func(n *Note) error {
+			assert.Equal("NEW", n.Notebook.GUID, "Note moved to wrong notebook")
+			moved = append(moved, n.GUID)
+			return nil
+		}
+		expunged := false
+		ns.expungeNotebook = func(guid string) error {
+			assert.Len(moved, 2, "Notes should be moved before expunging")
+			expunged = true
+			return nil
+		}
+		assert.NoError(DeleteNotebook(store, ns, "Old", "New"), "Should not return an error")
+		assert.True(expunged, "Notebook should be expunged")
+	})
+	t.Run("move notes while the search index is behind", func(t *testing.T) {
+		ns := setupNS()
+		var notes []*Note
+		for i := 0; i < 2*noteBatchSize+1; i++ {
+			notes = append(notes, &Note{GUID: strconv.Itoa(i)})
+		}
+		// The moved notes are still returned by the search.
+		ns.findNotes = func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			if offset >= len(notes) {
+				return nil, nil
+			}
+			end := offset + count
+			if end > len(notes) {
+				end = len(notes)
+			}
+			return notes[offset:end], nil
+		}
+		moved := make(map[string]int)
+		ns.updateNote = func(n *Note) error { moved[n.GUID]++; return nil }
+		ns.expungeNotebook = func(guid string) error { return nil }
+		assert.NoError(DeleteNotebook(store, ns, "Old", "New"), "Should not return an error")
+		assert.Len(moved, len(notes), "All notes should be moved")
+		for guid, count := range moved {
+			assert.Equal(1, count, "Note "+guid+" should be moved once")
+		}
+	})
+	t.Run("move notes to same notebook", func(t *testing.T) {
+		err := DeleteNotebook(store, setupNS(), "Old", "Old")
+		assert.Equal(ErrSameNotebook, err, "Wrong error returned")
+	})
+	t.Run("notebook not found", func(t *testing.T) {
+		err := DeleteNotebook(store, setupNS(), "Missing", "")
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
+	})
+}
+
+func TestSetDefaultNotebook(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	ns := &mockNS{getAllNotebooks: func() ([]*Notebook, error) {
+		return []*Notebook{&Notebook{Name: "Inbox", GUID: "INBOX", Default: true}, &Notebook{Name: "Work", GUID: "WORK"}}, nil
+	}}
+	t.Run("set default", func(t *testing.T) {
+		var updated *Notebook
+		ns.updateNotebook = func(b *Notebook) error { updated = b; return nil }
+		assert.NoError(SetDefaultNotebook(store, ns, "Work"), "Should not return an error")
+		assert.Equal("WORK", updated.GUID, "Wrong notebook updated")
+		assert.True(updated.Default, "Notebook should be the default")
+	})
+	t.Run("notebook not found", func(t *testing.T) {
+		assert.Equal(ErrNoNotebookFound, SetDefaultNotebook(store, ns, "Missing"), "Wrong error returned")
+	})
+	t.Run("notestore cache starts empty", func(t *testing.T) {
+		// The stored list is up to date but the notestore can only update
+		// the notebooks it has returned itself.
+		store := &mockStore{
+			getNotebookCache: func() (*NotebookCacheList, error) {
+				return NewNotebookCacheList([]*Notebook{&Notebook{Name: "Work", GUID: "WORK"}}), nil
+			},
+			storeNotebookList: func(list *NotebookCacheList) error { return nil },
+		}
+		cached := make(map[string]bool)
+		ns := &mockNS{getAllNotebooks: func() ([]*Notebook, error) {
+			cached["WORK"] = true
+			return []*Notebook{&Notebook{Name: "Work", GUID: "WORK"}}, nil
+		}}
+		ns.updateNotebook = func(b *Notebook) error {
+			if !cached[b.GUID] {
+				return ErrNoNotebookCached
+			}
+			return nil
+		}
+		assert.NoError(SetDefaultNotebook(store, ns, "Work"), "Should not return an error")
+	})
+}
@@ -6,6 +6,8 @@ type NotestoreClient interface {
 	FindNotes(filter *NoteFilter, offset, count int) ([]*Note, error)
 	// GetAllNotebooks returns all the of users notebooks.
 	GetAllNotebooks() ([]*Notebook, error)
+	// GetNote returns the note's metadata.
+	GetNote(guid string) (*Note, error)
 	// GetNotebook
 	GetNotebook(guid string) (*Notebook, error)
 	// CreateNotebook
@@ -16,8 +18,42 @@ type NotestoreClient interface {
 	UpdateNote(note *Note) error
 	// DeleteNote removes a note from the user's notebook.
 	DeleteNote(guid string) error
+	// RestoreNote moves a note from the trash can back to its notebook.
+	RestoreNote(note *Note) error
+	// ExpungeNote permanently removes a note.
+	ExpungeNote(guid string) error
+	// EmptyTrash permanently removes all the notes in the trash can.
+	EmptyTrash() error
+	// ExpungeNotebook permanently removes the notebook and the notes in it.
+	ExpungeNotebook(guid string) error
+	// GetNoteCounts returns the number of notes in each notebook, keyed
+	// by the notebook's GUID.
+	GetNoteCounts() (map[string]int, error)
 	// CreateNote creates a new note on the server.
 	CreateNote(note *Note) error
+	// CopyNote copies the note to the notebook.
+	CopyNote(guid, notebookGUID string) error
+	// GetNoteTags returns the names of the tags assigned to the note.
+	GetNoteTags(guid string) ([]string, error)
+	// UpdateNoteTags replaces the note's tags with the note's Tags.
+	// Tags that don't exist are created.
+	UpdateNoteTags(note *Note) error
+	// GetSavedSearches returns the user's saved searches.
+	GetSavedSearches() ([]*SavedSearch, error)
+	// CreateSavedSearch creates a new saved search.
+	CreateSavedSearch(search *SavedSearch) error
+	// DeleteSavedSearch permanently removes the saved search.
+	DeleteSavedSearch(guid string) error
+	// NoteLink returns the link to the note used in other notes.
+	NoteLink(guid string) (string, error)
+	// ExportNote returns the note with its raw content, attributes, tags
+	// and resources.
+	ExportNote(guid string) (*Note, error)
 	// UpdateNotebook updates the notebook on the server.
 	UpdateNotebook(book *Notebook) error
+	// GetNoteVersions returns the versions of the note saved by the server.
+	GetNoteVersions(guid string) ([]*NoteVersion, error)
+	// GetNoteVersion returns the note as it was at the version. The note's
+	// body holds the raw content of the version.
+	GetNoteVersion(guid string, usn int) (*Note, error)
 }
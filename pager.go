@@ -0,0 +1,63 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultPager is the pager used if $PAGER isn't set.
+const DefaultPager = "less"
+
+// PageText writes the text to w through the pager defined in $PAGER.
+// The pager is only used if the text has more lines than the screen's
+// height. If the height is unknown, 0, less is told to quit if the text
+// fits on one screen. The text is written directly to w if no pager is
+// found.
+func PageText(w io.Writer, text string, height int) error {
+	if height > 0 && strings.Count(text, "\n") < height {
+		_, err := io.WriteString(w, text)
+		return err
+	}
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{DefaultPager}
+	}
+	path, err := exec.LookPath(pager[0])
+	if err != nil {
+		_, err = io.WriteString(w, text)
+		return err
+	}
+	cmd := exec.Command(path, pager[1:]...)
+	cmd.Env = os.Environ()
+	// Same defaults as git: quit if one screen, keep colors and don't
+	// clear the screen on exit.
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if os.Getenv("LV") == "" {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
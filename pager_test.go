@@ -0,0 +1,53 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageText(t *testing.T) {
+	assert := assert.New(t)
+	pager := os.Getenv("PAGER")
+	defer os.Setenv("PAGER", pager)
+
+	t.Run("fits on screen", func(t *testing.T) {
+		os.Setenv("PAGER", "clinote-missing-pager")
+		buf := new(bytes.Buffer)
+		assert.NoError(PageText(buf, "one\ntwo\n", 10), "Should not return an error")
+		assert.Equal("one\ntwo\n", buf.String(), "Text should be written directly")
+	})
+
+	t.Run("no pager found", func(t *testing.T) {
+		os.Setenv("PAGER", "clinote-missing-pager")
+		buf := new(bytes.Buffer)
+		assert.NoError(PageText(buf, "one\ntwo\n", 1), "Should not return an error")
+		assert.Equal("one\ntwo\n", buf.String(), "Text should be written directly")
+	})
+
+	t.Run("through pager", func(t *testing.T) {
+		os.Setenv("PAGER", "cat -n")
+		buf := new(bytes.Buffer)
+		assert.NoError(PageText(buf, "one\ntwo\n", 1), "Should not return an error")
+		assert.Contains(buf.String(), "2\ttwo", "Text should be written by the pager")
+	})
+}
@@ -0,0 +1,187 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	// BuiltinPicker is the name of the built in line picker.
+	BuiltinPicker = "builtin"
+	// FzfPicker is the name of the picker using fzf.
+	FzfPicker = "fzf"
+	// pickerPageSize is the maximum number of items shown by the line picker.
+	pickerPageSize = 20
+)
+
+var (
+	// ErrNoSelection is returned if the user didn't pick anything.
+	ErrNoSelection = errors.New("nothing selected")
+)
+
+// Picker lets the user pick one item from a list.
+type Picker interface {
+	// Pick returns the index of the picked item.
+	Pick(prompt string, items []string) (int, error)
+}
+
+// LinePicker is a picker that works in any terminal. The items are
+// listed with a number and the user can either enter the number of an
+// item or text to filter the list with.
+type LinePicker struct {
+	In  io.Reader
+	Out io.Writer
+	r   *bufio.Reader
+}
+
+// Pick lists the items and reads the user's choice.
+func (p *LinePicker) Pick(prompt string, items []string) (int, error) {
+	if p.r == nil {
+		p.r = bufio.NewReader(p.In)
+	}
+	if len(items) == 0 {
+		return -1, ErrNoSelection
+	}
+	filtered := filterItems("", items)
+	for {
+		for i, idx := range filtered {
+			if i == pickerPageSize {
+				fmt.Fprintf(p.Out, "   ... %d more, type to filter\n", len(filtered)-pickerPageSize)
+				break
+			}
+			fmt.Fprintf(p.Out, "%3d) %s\n", i+1, items[idx])
+		}
+		fmt.Fprintf(p.Out, "%s (number, text to filter, empty to cancel): ", prompt)
+		line, err := p.r.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return -1, ErrNoSelection
+			}
+			return -1, err
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			return -1, ErrNoSelection
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n > 0 && n <= len(filtered) && n <= pickerPageSize {
+			return filtered[n-1], nil
+		}
+		f := filterItems(answer, items)
+		if len(f) == 0 {
+			fmt.Fprintf(p.Out, "No match for %q\n", answer)
+			continue
+		}
+		if len(f) == 1 {
+			return f[0], nil
+		}
+		filtered = f
+	}
+}
+
+// filterItems returns the indexes of the items matching the filter.
+func filterItems(filter string, items []string) []int {
+	var idx []int
+	for i, item := range items {
+		if filter == "" || similarity(filter, item) >= suggestionThreshold {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// ExternalPicker uses fzf to pick the item.
+type ExternalPicker struct {
+	// Path is the path to the fzf binary.
+	Path string
+}
+
+// Pick pipes the items to fzf and returns the index of the picked item.
+func (p *ExternalPicker) Pick(prompt string, items []string) (int, error) {
+	buf := new(bytes.Buffer)
+	for i, item := range items {
+		fmt.Fprintf(buf, "%d\t%s\n", i, item)
+	}
+	cmd := exec.Command(p.Path, "--prompt", prompt+"> ", "--delimiter", "\t", "--with-nth", "2..")
+	cmd.Stdin = buf
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// fzf exits with a non-zero code when nothing is picked.
+			return -1, ErrNoSelection
+		}
+		return -1, err
+	}
+	fields := strings.SplitN(string(out), "\t", 2)
+	i, err := strconv.Atoi(fields[0])
+	if err != nil || i < 0 || i >= len(items) {
+		return -1, ErrNoSelection
+	}
+	return i, nil
+}
+
+// NewPicker returns the picker with the name. If fzf is requested but
+// can't be found, the built in picker is returned.
+func NewPicker(name string, in io.Reader, out io.Writer) Picker {
+	if name == FzfPicker {
+		if path, err := exec.LookPath("fzf"); err == nil {
+			return &ExternalPicker{Path: path}
+		}
+	}
+	return &LinePicker{In: in, Out: out}
+}
+
+// PickNote lets the user pick a note from the last saved search and the
+// most recently updated notes. The notebook's name is shown next to the
+// title so the list can be filtered by notebook. If notebook is not an
+// empty string, only notes in the notebook are shown.
+func PickNote(db Storager, ns NotestoreClient, picker Picker, notebook string) (*Note, error) {
+	notes, err := recentNotes(db, ns, notebook)
+	if err != nil {
+		return nil, err
+	}
+	nbs, err := GetNotebooks(db, ns, false)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(nbs))
+	for _, nb := range nbs {
+		names[nb.GUID] = nb.Name
+	}
+	items := make([]string, len(notes))
+	for i, n := range notes {
+		items[i] = n.Title
+		if n.Notebook != nil && names[n.Notebook.GUID] != "" {
+			items[i] = fmt.Sprintf("%s [%s]", n.Title, names[n.Notebook.GUID])
+		}
+	}
+	i, err := picker.Pick("Note", items)
+	if err != nil {
+		return nil, err
+	}
+	return notes[i], nil
+}
@@ -0,0 +1,89 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinePicker(t *testing.T) {
+	assert := assert.New(t)
+	items := []string{"Shopping list [Home]", "Weekly meeting [Work]", "Todo [Work]", "Todo [Home]"}
+	pick := func(input string) (int, error) {
+		p := &LinePicker{In: strings.NewReader(input), Out: new(bytes.Buffer)}
+		return p.Pick("Note", items)
+	}
+	t.Run("pick by number", func(t *testing.T) {
+		i, err := pick("2\n")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(1, i, "Wrong item picked")
+	})
+	t.Run("filter then pick", func(t *testing.T) {
+		i, err := pick("work\n2\n")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(2, i, "Number should index the filtered list")
+	})
+	t.Run("single match is picked", func(t *testing.T) {
+		i, err := pick("shopping\n")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(0, i, "Wrong item picked")
+	})
+	t.Run("cancel", func(t *testing.T) {
+		_, err := pick("\n")
+		assert.Equal(ErrNoSelection, err, "Wrong error returned")
+	})
+	t.Run("end of input", func(t *testing.T) {
+		_, err := pick("")
+		assert.Equal(ErrNoSelection, err, "Wrong error returned")
+	})
+}
+
+type mockPicker struct {
+	items []string
+	pick  int
+}
+
+func (p *mockPicker) Pick(prompt string, items []string) (int, error) {
+	p.items = items
+	return p.pick, nil
+}
+
+func TestPickNote(t *testing.T) {
+	assert := assert.New(t)
+	work := &Notebook{Name: "Work", GUID: "WORK"}
+	todo := &Note{Title: "Todo", GUID: "1", Notebook: &Notebook{GUID: "WORK"}}
+	other := &Note{Title: "Other", GUID: "2", Notebook: &Notebook{GUID: "WORK"}}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+		getSearch:         func() ([]*Note, error) { return []*Note{todo}, nil },
+	}
+	ns := &mockNS{
+		getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{work}, nil },
+		findNotes:       func(f *NoteFilter, offset, count int) ([]*Note, error) { return []*Note{todo, other}, nil },
+	}
+	p := &mockPicker{pick: 1}
+	n, err := PickNote(store, ns, p, "")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(other, n, "Wrong note picked")
+	assert.Equal([]string{"Todo [Work]", "Other [Work]"}, p.items, "Notes should be listed once with notebook")
+}
@@ -0,0 +1,622 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"html/template"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultSiteTitle is the title of a published site if none is given.
+	DefaultSiteTitle = "Notes"
+	siteNotesDir     = "notes"
+	siteNotebooksDir = "notebooks"
+	siteTagsDir      = "tags"
+	siteFilesDir     = "attachments"
+	siteSearchIndex  = "search.json"
+	siteStylesheet   = "style.css"
+	siteScript       = "search.js"
+	// siteRecentNotes is the number of recently updated notes listed on
+	// the start page.
+	siteRecentNotes = 20
+	// searchTextLength is the maximum number of characters of a note's
+	// text in the search index.
+	searchTextLength = 5000
+)
+
+// ErrNothingToPublish is returned if no notebook or stack is given.
+var ErrNothingToPublish = errors.New("a notebook or a stack is required")
+
+// PublishOptions selects the notebooks published as a site.
+type PublishOptions struct {
+	// Notebooks are the names of the notebooks to publish.
+	Notebooks []string
+	// Stacks are the names of stacks whose notebooks are published.
+	Stacks []string
+	// Dir is the folder the site is written to.
+	Dir string
+	// Title is the site's title. DefaultSiteTitle is used if empty.
+	Title string
+}
+
+// PublishResult summarises a published site.
+type PublishResult struct {
+	// Notes is the number of published notes.
+	Notes int
+	// Notebooks is the number of published notebooks.
+	Notebooks int
+	// Tags is the number of tag pages.
+	Tags int
+	// Attachments is the number of copied attachments.
+	Attachments int
+}
+
+// SearchIndexEntry is a note in the site's search index.
+type SearchIndexEntry struct {
+	Title    string   `json:"title"`
+	URL      string   `json:"url"`
+	Notebook string   `json:"notebook"`
+	Stack    string   `json:"stack,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Text     string   `json:"text"`
+}
+
+// PublishSite writes the notes in the selected notebooks as a static web
+// site. Every note gets a page, and there are pages listing the notes in
+// each notebook and with each tag. Links between published notes point to
+// the note's page, attachments are copied to the site and a search index
+// is written for the start page's search. Existing files in the folder
+// are overwritten.
+func PublishSite(db Storager, ns NotestoreClient, opts *PublishOptions) (*PublishResult, error) {
+	books, err := publishNotebooks(db, ns, opts)
+	if err != nil {
+		return nil, err
+	}
+	s := newSite(opts)
+	for _, nb := range books {
+		notes, err := SelectNotes(db, ns, &BulkSelection{Notebook: nb.Name})
+		if err == ErrNoNotesSelected {
+			s.addNotebook(nb)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.addNotebook(nb)
+		for _, n := range notes {
+			note, err := ns.ExportNote(n.GUID)
+			if err != nil {
+				return nil, err
+			}
+			note.Notebook = nb
+			s.addNote(note)
+		}
+	}
+	if err = s.write(); err != nil {
+		return nil, err
+	}
+	return &PublishResult{
+		Notes:       len(s.notes),
+		Notebooks:   len(s.notebooks),
+		Tags:        len(s.tagPages),
+		Attachments: len(s.files),
+	}, nil
+}
+
+// publishNotebooks returns the notebooks selected by the options.
+func publishNotebooks(db Storager, ns NotestoreClient, opts *PublishOptions) ([]*Notebook, error) {
+	if len(opts.Notebooks) == 0 && len(opts.Stacks) == 0 {
+		return nil, ErrNothingToPublish
+	}
+	bs, err := GetNotebooks(db, ns, false)
+	if err != nil {
+		return nil, err
+	}
+	var selected []*Notebook
+	add := func(b *Notebook) {
+		for _, s := range selected {
+			if s.GUID == b.GUID {
+				return
+			}
+		}
+		selected = append(selected, b)
+	}
+	for _, name := range opts.Notebooks {
+		found := false
+		for _, b := range bs {
+			if b.Name == name {
+				add(b)
+				found = true
+			}
+		}
+		if !found {
+			return nil, ErrNoNotebookFound
+		}
+	}
+	for _, stack := range opts.Stacks {
+		found := false
+		for _, b := range bs {
+			if b.Stack == stack {
+				add(b)
+				found = true
+			}
+		}
+		if !found {
+			return nil, ErrNoStackFound
+		}
+	}
+	return selected, nil
+}
+
+// site holds the pages of a site being published. The page paths are
+// relative to the site's root folder.
+type site struct {
+	dir   string
+	title string
+	notes []*Note
+	// notebooks are the published notebooks in the order they were added.
+	notebooks []*Notebook
+	// notePages maps note GUIDs to pages.
+	notePages map[string]string
+	// notebookPages maps notebook GUIDs to pages.
+	notebookPages map[string]string
+	// tagPages maps tag names to pages.
+	tagPages map[string]string
+	// files maps resource hashes to copied attachments.
+	files map[string]string
+	// used are the used file names.
+	used map[string]bool
+}
+
+func newSite(opts *PublishOptions) *site {
+	title := opts.Title
+	if title == "" {
+		title = DefaultSiteTitle
+	}
+	return &site{
+		dir:           opts.Dir,
+		title:         title,
+		notePages:     make(map[string]string),
+		notebookPages: make(map[string]string),
+		tagPages:      make(map[string]string),
+		files:         make(map[string]string),
+		used:          map[string]bool{"index.html": true},
+	}
+}
+
+func (s *site) page(dir, title string) string {
+	return uniqueFileName(s.used, path.Join(dir, noteSlug(title)), ".html")
+}
+
+func (s *site) addNotebook(nb *Notebook) {
+	s.notebooks = append(s.notebooks, nb)
+	s.notebookPages[nb.GUID] = s.page(siteNotebooksDir, nb.Name)
+}
+
+func (s *site) addNote(n *Note) {
+	s.notes = append(s.notes, n)
+	s.notePages[n.GUID] = s.page(siteNotesDir, n.Title)
+	for _, t := range n.Tags {
+		if _, ok := s.tagPages[t]; !ok {
+			s.tagPages[t] = s.page(siteTagsDir, t)
+		}
+	}
+}
+
+// siteLink is a link in the navigation or in a note list.
+type siteLink struct {
+	Title string
+	URL   string
+	Meta  string
+}
+
+type siteNavGroup struct {
+	Name  string
+	Links []*siteLink
+}
+
+type siteNav struct {
+	Stacks []*siteNavGroup
+	Tags   []*siteLink
+}
+
+type sitePageData struct {
+	Site    string
+	Root    string
+	Title   string
+	Meta    string
+	Nav     *siteNav
+	Content template.HTML
+	Search  bool
+}
+
+type siteNoteData struct {
+	Root        string
+	Notebook    string
+	NotebookURL string
+	Updated     string
+	Body        template.HTML
+	Tags        []*siteLink
+}
+
+type siteListData struct {
+	Root  string
+	Notes []*siteLink
+}
+
+func (s *site) write() error {
+	for _, dir := range []string{siteNotesDir, siteNotebooksDir, siteTagsDir, siteFilesDir} {
+		if err := os.MkdirAll(filepath.Join(s.dir, dir), 0755); err != nil {
+			return err
+		}
+	}
+	if err := s.writeFile(siteStylesheet, []byte(exportStylesheet+siteLayoutStylesheet)); err != nil {
+		return err
+	}
+	if err := s.writeFile(siteScript, []byte(siteSearchScript)); err != nil {
+		return err
+	}
+	nav := s.nav()
+	index := make([]*SearchIndexEntry, 0, len(s.notes))
+	for _, n := range s.notes {
+		entry, err := s.writeNote(n, nav)
+		if err != nil {
+			return err
+		}
+		index = append(index, entry)
+	}
+	for _, nb := range s.notebooks {
+		var notes []*Note
+		for _, n := range s.notes {
+			if n.Notebook.GUID == nb.GUID {
+				notes = append(notes, n)
+			}
+		}
+		if err := s.writeList(s.notebookPages[nb.GUID], nb.Name, notes, nav); err != nil {
+			return err
+		}
+	}
+	for tag, page := range s.tagPages {
+		var notes []*Note
+		for _, n := range s.notes {
+			if containsString(n.Tags, tag) {
+				notes = append(notes, n)
+			}
+		}
+		if err := s.writeList(page, tag, notes, nav); err != nil {
+			return err
+		}
+	}
+	if err := s.writeIndex(nav); err != nil {
+		return err
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return s.writeFile(siteSearchIndex, data)
+}
+
+func (s *site) writeFile(name string, data []byte) error {
+	return ioutil.WriteFile(filepath.Join(s.dir, filepath.FromSlash(name)), data, exportFileMode)
+}
+
+// nav returns the navigation with the notebooks grouped by stack and all
+// the tags. The URLs are relative to the site's root.
+func (s *site) nav() *siteNav {
+	stacks := make(map[string]*siteNavGroup)
+	nav := new(siteNav)
+	for _, nb := range s.notebooks {
+		g, ok := stacks[nb.Stack]
+		if !ok {
+			g = &siteNavGroup{Name: nb.Stack}
+			stacks[nb.Stack] = g
+			nav.Stacks = append(nav.Stacks, g)
+		}
+		g.Links = append(g.Links, &siteLink{Title: nb.Name, URL: s.notebookPages[nb.GUID]})
+	}
+	// Notebooks without a stack are listed last.
+	sort.SliceStable(nav.Stacks, func(i, j int) bool {
+		a, b := nav.Stacks[i].Name, nav.Stacks[j].Name
+		if a == "" || b == "" {
+			return b == "" && a != ""
+		}
+		return a < b
+	})
+	for _, g := range nav.Stacks {
+		sortLinks(g.Links)
+	}
+	for tag, page := range s.tagPages {
+		nav.Tags = append(nav.Tags, &siteLink{Title: tag, URL: page})
+	}
+	sortLinks(nav.Tags)
+	return nav
+}
+
+func sortLinks(links []*siteLink) {
+	sort.SliceStable(links, func(i, j int) bool {
+		return strings.ToLower(links[i].Title) < strings.ToLower(links[j].Title)
+	})
+}
+
+// rootPath returns the relative path from the page to the site's root.
+func rootPath(page string) string {
+	return strings.Repeat("../", strings.Count(page, "/"))
+}
+
+func (s *site) writeNote(n *Note, nav *siteNav) (*SearchIndexEntry, error) {
+	page := s.notePages[n.GUID]
+	root := rootPath(page)
+	for _, r := range n.Resources {
+		if err := s.copyResource(r); err != nil {
+			return nil, err
+		}
+	}
+	c := &htmlConverter{
+		mediaURL: func(r *Resource) string {
+			return root + s.files[resourceHash(r)]
+		},
+		linkURL: func(href string) string {
+			if p, ok := s.notePages[noteLinkGUID(href)]; ok {
+				return root + p
+			}
+			return href
+		},
+	}
+	body, err := c.convert(n)
+	if err != nil {
+		return nil, err
+	}
+	data := &siteNoteData{
+		Root:        root,
+		Notebook:    n.Notebook.Name,
+		NotebookURL: s.notebookPages[n.Notebook.GUID],
+		Updated:     time.Unix(n.Updated/1000, 0).Format(timeFormat),
+		Body:        template.HTML(body),
+	}
+	for _, t := range n.Tags {
+		data.Tags = append(data.Tags, &siteLink{Title: t, URL: s.tagPages[t]})
+	}
+	content := new(bytes.Buffer)
+	if err = siteNoteContent.Execute(content, data); err != nil {
+		return nil, err
+	}
+	err = s.writePage(page, &sitePageData{Title: n.Title, Nav: nav, Content: template.HTML(content.String())})
+	if err != nil {
+		return nil, err
+	}
+	return &SearchIndexEntry{
+		Title:    n.Title,
+		URL:      page,
+		Notebook: n.Notebook.Name,
+		Stack:    n.Notebook.Stack,
+		Tags:     n.Tags,
+		Text:     searchText(body),
+	}, nil
+}
+
+// copyResource writes the resource to the attachment folder. Resources
+// are stored in a folder named by their hash so each is only copied once.
+func (s *site) copyResource(r *Resource) error {
+	hash := resourceHash(r)
+	if _, ok := s.files[hash]; ok {
+		return nil
+	}
+	dir := path.Join(siteFilesDir, hash)
+	if err := os.MkdirAll(filepath.Join(s.dir, filepath.FromSlash(dir)), 0755); err != nil {
+		return err
+	}
+	name := resourceFileName(r)
+	if err := s.writeFile(path.Join(dir, name), r.Data); err != nil {
+		return err
+	}
+	s.files[hash] = path.Join(dir, url.PathEscape(name))
+	return nil
+}
+
+// searchText returns the plain text of the HTML for the search index.
+func searchText(body string) string {
+	text := html.UnescapeString(markupTag.ReplaceAllString(body, " "))
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > searchTextLength {
+		text = string([]rune(text)[:searchTextLength])
+	}
+	return text
+}
+
+func (s *site) writeList(page, title string, notes []*Note, nav *siteNav) error {
+	root := rootPath(page)
+	list := &siteListData{Root: root, Notes: s.noteLinks(notes, true)}
+	sortLinks(list.Notes)
+	content := new(bytes.Buffer)
+	if err := siteNoteList.Execute(content, list); err != nil {
+		return err
+	}
+	return s.writePage(page, &sitePageData{
+		Title:   title,
+		Meta:    noteCount(len(notes)),
+		Nav:     nav,
+		Content: template.HTML(content.String()),
+	})
+}
+
+func (s *site) noteLinks(notes []*Note, withNotebook bool) []*siteLink {
+	links := make([]*siteLink, len(notes))
+	for i, n := range notes {
+		meta := time.Unix(n.Updated/1000, 0).Format(timeFormat)
+		if withNotebook {
+			meta = n.Notebook.Name + " · " + meta
+		}
+		links[i] = &siteLink{Title: n.Title, URL: s.notePages[n.GUID], Meta: meta}
+	}
+	return links
+}
+
+func noteCount(n int) string {
+	if n == 1 {
+		return "1 note"
+	}
+	return strconv.Itoa(n) + " notes"
+}
+
+func (s *site) writeIndex(nav *siteNav) error {
+	recent := make([]*Note, len(s.notes))
+	copy(recent, s.notes)
+	sort.SliceStable(recent, func(i, j int) bool { return recent[i].Updated > recent[j].Updated })
+	if len(recent) > siteRecentNotes {
+		recent = recent[:siteRecentNotes]
+	}
+	content := new(bytes.Buffer)
+	content.WriteString(siteSearchForm)
+	if len(recent) > 0 {
+		content.WriteString("<h2>Recently updated</h2>\n")
+		list := &siteListData{Notes: s.noteLinks(recent, true)}
+		if err := siteNoteList.Execute(content, list); err != nil {
+			return err
+		}
+	}
+	return s.writePage("index.html", &sitePageData{
+		Title:   s.title,
+		Meta:    noteCount(len(s.notes)),
+		Nav:     nav,
+		Content: template.HTML(content.String()),
+		Search:  true,
+	})
+}
+
+func (s *site) writePage(page string, data *sitePageData) error {
+	data.Site = s.title
+	data.Root = rootPath(page)
+	buf := new(bytes.Buffer)
+	if err := sitePage.Execute(buf, data); err != nil {
+		return err
+	}
+	return s.writeFile(page, buf.Bytes())
+}
+
+var sitePage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}{{if ne .Title .Site}} · {{.Site}}{{end}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<nav class="site-nav">
+<p class="site-title"><a href="{{.Root}}index.html">{{.Site}}</a></p>
+{{range .Nav.Stacks}}{{if .Name}}<h3>{{.Name}}</h3>
+{{end}}<ul>
+{{range .Links}}<li><a href="{{$.Root}}{{.URL}}">{{.Title}}</a></li>
+{{end}}</ul>
+{{end}}{{if .Nav.Tags}}<h3>Tags</h3>
+<ul class="tags">
+{{range .Nav.Tags}}<li><a href="{{$.Root}}{{.URL}}">{{.Title}}</a></li>
+{{end}}</ul>
+{{end}}</nav>
+<main>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Meta}}<p class="meta">{{.Meta}}</p>
+{{end}}</header>
+{{.Content}}
+</main>
+{{if .Search}}<script src="{{.Root}}search.js"></script>
+{{end}}</body>
+</html>
+`))
+
+var siteNoteContent = template.Must(template.New("note").Parse(`<p class="meta"><a href="{{.Root}}{{.NotebookURL}}">{{.Notebook}}</a> · Updated {{.Updated}}</p>
+{{.Body}}
+{{if .Tags}}<p class="tags">Tags: {{range $i, $t := .Tags}}{{if $i}}, {{end}}<a href="{{$.Root}}{{$t.URL}}">{{$t.Title}}</a>{{end}}</p>
+{{end}}`))
+
+var siteNoteList = template.Must(template.New("list").Parse(`<ul class="notes">
+{{range .Notes}}<li><a href="{{$.Root}}{{.URL}}">{{.Title}}</a><span class="meta">{{.Meta}}</span></li>
+{{end}}</ul>
+`))
+
+const siteSearchForm = `<form class="search" onsubmit="return false">
+<input id="search" type="search" placeholder="Search notes" autocomplete="off">
+</form>
+<ul id="search-results" class="notes"></ul>
+`
+
+const siteLayoutStylesheet = `
+body { max-width: 72em; display: flex; align-items: flex-start; }
+.site-nav { flex: 0 0 14em; margin-right: 2em; font-size: 0.9em; }
+.site-nav ul { list-style: none; padding-left: 0; }
+.site-nav h3 { font-size: 0.85em; text-transform: uppercase; color: #666; margin-bottom: 0.3em; }
+.site-title { font-weight: bold; font-size: 1.1em; }
+main { flex: 1; min-width: 0; }
+.search input { width: 100%; font-size: 1.1em; padding: 0.4em; box-sizing: border-box; }
+@media (max-width: 40em) { body { display: block; } .site-nav { margin-right: 0; } }
+@media print { .site-nav, .search { display: none; } }
+`
+
+// siteSearchScript searches the search index from the start page.
+const siteSearchScript = `(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  if (!input || !results) {
+    return;
+  }
+  var index = [];
+  function search() {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    if (!words.length) {
+      return;
+    }
+    index.filter(function (e) {
+      var text = [e.title, e.notebook, e.stack || "", (e.tags || []).join(" "), e.text].join(" ").toLowerCase();
+      return words.every(function (w) { return text.indexOf(w) >= 0; });
+    }).slice(0, 50).forEach(function (e) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = e.url;
+      a.textContent = e.title;
+      var meta = document.createElement("span");
+      meta.className = "meta";
+      meta.textContent = e.notebook;
+      li.appendChild(a);
+      li.appendChild(meta);
+      results.appendChild(li);
+    });
+  }
+  fetch("search.json").then(function (r) { return r.json(); }).then(function (data) {
+    index = data;
+    search();
+  });
+  input.addEventListener("input", search);
+})();
+`
@@ -0,0 +1,122 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishSite(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-site")
+	assert.NoError(err, "Should create a temp folder")
+	defer os.RemoveAll(dir)
+
+	guid1 := "11111111-1111-1111-1111-111111111111"
+	guid2 := "22222222-2222-2222-2222-222222222222"
+	guid3 := "33333333-3333-3333-3333-333333333333"
+	deploy := &Notebook{Name: "Deploy", GUID: "NB1", Stack: "Runbooks"}
+	incidents := &Notebook{Name: "Incidents", GUID: "NB2", Stack: "Runbooks"}
+	other := &Notebook{Name: "Other", GUID: "NB3"}
+	img := &Resource{Data: []byte("png"), Mime: "image/png", FileName: "../diagram.png"}
+	notes := map[string]*Note{
+		guid1: {
+			Title: "Release", GUID: guid1, Notebook: deploy, Tags: []string{"prod"},
+			Body: XMLHeader + `<en-note><p>Follow <a href="evernote:///view/1/s1/` + guid2 + `/` + guid2 + `/">rollback</a>` +
+				` and <a href="https://example.com">docs</a>.</p><en-media type="image/png" hash="` + resourceHash(img) + `"/></en-note>`,
+			Resources: []*Resource{img},
+		},
+		guid2: {Title: "Rollback", GUID: guid2, Notebook: incidents, Tags: []string{"prod", "urgent"},
+			Body: XMLHeader + "<en-note><p>Revert &amp; redeploy</p></en-note>"},
+		guid3: {Title: "Private", GUID: guid3, Notebook: other, Body: XMLHeader + "<en-note/>"},
+	}
+	ns := &mockNS{
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			var a []*Note
+			for _, n := range notes {
+				if n.Notebook.GUID == f.NotebookGUID {
+					a = append(a, &Note{GUID: n.GUID, Title: n.Title, Notebook: n.Notebook})
+				}
+			}
+			return a, nil
+		},
+		exportNote: func(guid string) (*Note, error) {
+			n := *notes[guid]
+			return &n, nil
+		},
+	}
+	store := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return NewNotebookCacheList([]*Notebook{deploy, incidents, other}), nil
+		},
+	}
+
+	_, err = PublishSite(store, ns, &PublishOptions{Dir: dir})
+	assert.Equal(ErrNothingToPublish, err, "Wrong error without notebooks")
+	_, err = PublishSite(store, ns, &PublishOptions{Dir: dir, Stacks: []string{"Missing"}})
+	assert.Equal(ErrNoStackFound, err, "Wrong error for missing stack")
+
+	result, err := PublishSite(store, ns, &PublishOptions{Dir: dir, Stacks: []string{"Runbooks"}, Title: "Runbooks"})
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(&PublishResult{Notes: 2, Notebooks: 2, Tags: 2, Attachments: 1}, result, "Wrong result")
+
+	read := func(name string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.NoError(err, name+" should be written")
+		return string(b)
+	}
+	release := read("notes/release.html")
+	assert.Contains(release, `<a href="../notes/rollback.html">rollback</a>`, "Note links should be relative")
+	assert.Contains(release, `<a href="https://example.com">docs</a>`, "Other links should be kept")
+	assert.Contains(release, `<img src="../attachments/`+resourceHash(img)+`/diagram.png" alt="diagram.png">`, "Images should link the copied file")
+	assert.Contains(release, `<a href="../notebooks/deploy.html">Deploy</a>`, "Note should link its notebook")
+	assert.Contains(release, `<a href="../tags/prod.html">prod</a>`, "Note should link its tags")
+	assert.Contains(release, "<h3>Runbooks</h3>", "Navigation should group by stack")
+	assert.Equal("png", read("attachments/"+resourceHash(img)+"/diagram.png"), "Attachment should be copied")
+
+	assert.Contains(read("tags/prod.html"), `<a href="../notes/rollback.html">Rollback</a>`, "Tag page should list the notes")
+	assert.Contains(read("notebooks/incidents.html"), `<a href="../notes/rollback.html">Rollback</a>`, "Notebook page should list the notes")
+	index := read("index.html")
+	assert.Contains(index, `<script src="search.js"></script>`, "Start page should have the search")
+	assert.NotContains(index, "Private", "Unselected notebooks should not be published")
+	read("search.js")
+	read("style.css")
+
+	var entries []*SearchIndexEntry
+	assert.NoError(json.Unmarshal([]byte(read("search.json")), &entries), "Search index should be JSON")
+	if assert.Len(entries, 2, "Wrong number of search entries") {
+		for _, e := range entries {
+			if e.Title == "Rollback" {
+				assert.Equal(&SearchIndexEntry{
+					Title:    "Rollback",
+					URL:      "notes/rollback.html",
+					Notebook: "Incidents",
+					Stack:    "Runbooks",
+					Tags:     []string{"prod", "urgent"},
+					Text:     "Revert & redeploy",
+				}, e, "Wrong search entry")
+			}
+		}
+	}
+}
@@ -0,0 +1,506 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidTodo is returned if the todo filter isn't one of any,
+	// checked or unchecked.
+	ErrInvalidTodo = errors.New("todo must be any, checked or unchecked")
+	// ErrUnbalancedQuotes is returned if a search query has a quote that
+	// isn't closed.
+	ErrUnbalancedQuotes = errors.New("unbalanced quotes in search query")
+)
+
+const queryDateFormat = "20060102"
+
+// InvalidQueryError is returned when a term in a search query isn't
+// valid Evernote search grammar.
+type InvalidQueryError struct {
+	// Term is the invalid term.
+	Term string
+	// Reason describes why the term is invalid.
+	Reason string
+}
+
+func (e *InvalidQueryError) Error() string {
+	return fmt.Sprintf("invalid search term %q: %s", e.Term, e.Reason)
+}
+
+// QueryBuilder builds an Evernote search query from structured filters.
+// The zero value matches all notes.
+type QueryBuilder struct {
+	// Words is a free text search. It can use the full search grammar.
+	Words string
+	// InTitle restricts the search to notes with the text in the title.
+	InTitle string
+	// Tags the notes must have.
+	Tags []string
+	// NotTags the notes must not have.
+	NotTags []string
+	// CreatedAfter matches notes created on or after the day.
+	CreatedAfter time.Time
+	// UpdatedBefore matches notes last updated before the day.
+	UpdatedBefore time.Time
+	// HasAttachment matches notes with at least one attached file.
+	HasAttachment bool
+	// Todo matches notes with checkboxes. It can be any, checked or
+	// unchecked.
+	Todo string
+	// Reminder matches notes with a reminder.
+	Reminder bool
+	// Source matches the application or method used to create the note,
+	// for example web.clip or mobile.*.
+	Source string
+}
+
+// Build returns the search query as Evernote search grammar. The query is
+// validated before it's returned.
+func (b *QueryBuilder) Build() (string, error) {
+	var terms []string
+	if w := strings.TrimSpace(b.Words); w != "" {
+		terms = append(terms, w)
+	}
+	if b.InTitle != "" {
+		terms = append(terms, "intitle:"+quoteQueryValue(b.InTitle))
+	}
+	for _, t := range b.Tags {
+		terms = append(terms, "tag:"+quoteQueryValue(t))
+	}
+	for _, t := range b.NotTags {
+		terms = append(terms, "-tag:"+quoteQueryValue(t))
+	}
+	if !b.CreatedAfter.IsZero() {
+		terms = append(terms, "created:"+b.CreatedAfter.Format(queryDateFormat))
+	}
+	if !b.UpdatedBefore.IsZero() {
+		terms = append(terms, "-updated:"+b.UpdatedBefore.Format(queryDateFormat))
+	}
+	if b.HasAttachment {
+		terms = append(terms, "resource:*")
+	}
+	switch b.Todo {
+	case "":
+	case "any", "*":
+		terms = append(terms, "todo:*")
+	case "checked", "true":
+		terms = append(terms, "todo:true")
+	case "unchecked", "false":
+		terms = append(terms, "todo:false")
+	default:
+		return "", ErrInvalidTodo
+	}
+	if b.Reminder {
+		terms = append(terms, "reminderOrder:*")
+	}
+	if b.Source != "" {
+		terms = append(terms, "source:"+quoteQueryValue(b.Source))
+	}
+	query := strings.Join(terms, " ")
+	if _, err := ParseQuery(query); err != nil {
+		return "", err
+	}
+	return query, nil
+}
+
+func quoteQueryValue(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// Query is a parsed Evernote search query. It can be evaluated locally
+// against notes, for example the notes from the last note list.
+type Query struct {
+	// Any is true if a note only needs to match one of the terms.
+	Any bool
+	// Terms in the query.
+	Terms []*QueryTerm
+	// Notebooks are the notebook names keyed by GUID. It's used to match
+	// notebook: against notes that only have the notebook's GUID.
+	Notebooks map[string]string
+}
+
+// QueryTerm is a single term of a search query.
+type QueryTerm struct {
+	// Negated is true if the term is prefixed with -.
+	Negated bool
+	// Field is the search modifier, for example tag. It's empty for
+	// plain words and phrases.
+	Field string
+	// Value is the term's value without quotes.
+	Value string
+}
+
+// queryFields are the search modifiers the local matcher understands.
+// Unknown modifiers are treated as words, as done by Evernote.
+var queryFields = map[string]bool{
+	"any":              true,
+	"intitle":          true,
+	"notebook":         true,
+	"tag":              true,
+	"created":          true,
+	"updated":          true,
+	"resource":         true,
+	"todo":             true,
+	"encryption":       true,
+	"source":           true,
+	"sourceUrl":        true,
+	"author":           true,
+	"reminderOrder":    true,
+	"reminderTime":     true,
+	"reminderDoneTime": true,
+}
+
+var relativeQueryDate = regexp.MustCompile(`^(day|week|month|year)([-+]\d+)?$`)
+
+// ParseQuery parses and validates a search query written in the Evernote
+// search grammar.
+func ParseQuery(s string) (*Query, error) {
+	tokens, err := splitQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	q := new(Query)
+	for _, tok := range tokens {
+		term := new(QueryTerm)
+		raw := tok
+		if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+			term.Negated = true
+			tok = tok[1:]
+		}
+		if i := strings.Index(tok, ":"); i > 0 && queryFields[tok[:i]] {
+			term.Field = tok[:i]
+			tok = tok[i+1:]
+		}
+		term.Value = strings.Trim(tok, `"`)
+		if err := validateQueryTerm(term); err != nil {
+			return nil, &InvalidQueryError{Term: raw, Reason: err.Error()}
+		}
+		if term.Field == "any" {
+			q.Any = true
+			continue
+		}
+		q.Terms = append(q.Terms, term)
+	}
+	return q, nil
+}
+
+// splitQuery splits the query on white space outside of quotes.
+func splitQuery(s string) ([]string, error) {
+	var tokens []string
+	buf := new(bytes.Buffer)
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			buf.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if buf.Len() > 0 {
+				tokens = append(tokens, buf.String())
+				buf.Reset()
+			}
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, ErrUnbalancedQuotes
+	}
+	if buf.Len() > 0 {
+		tokens = append(tokens, buf.String())
+	}
+	return tokens, nil
+}
+
+func validateQueryTerm(t *QueryTerm) error {
+	switch t.Field {
+	case "any":
+		if t.Negated || t.Value != "" {
+			return errors.New("any: takes no value")
+		}
+	case "encryption":
+		if t.Value != "" {
+			return errors.New("encryption: takes no value")
+		}
+	case "created", "updated":
+		if _, err := parseQueryDate(t.Value, now()); err != nil {
+			return err
+		}
+	case "reminderOrder", "reminderTime", "reminderDoneTime":
+		if t.Value == "*" {
+			return nil
+		}
+		if _, err := parseQueryDate(t.Value, now()); err != nil {
+			return err
+		}
+	case "todo":
+		if t.Value != "*" && t.Value != "true" && t.Value != "false" {
+			return errors.New("todo must be *, true or false")
+		}
+	case "":
+		if t.Value == "" {
+			return errors.New("empty term")
+		}
+	default:
+		if t.Value == "" {
+			return fmt.Errorf("%s: requires a value", t.Field)
+		}
+	}
+	return nil
+}
+
+// parseQueryDate parses an absolute date, YYYYMMDD or YYYYMMDDThhmmss
+// with an optional Z, or a relative date like day-1 or week.
+func parseQueryDate(s string, ref time.Time) (time.Time, error) {
+	if m := relativeQueryDate.FindStringSubmatch(s); m != nil {
+		offset := 0
+		if m[2] != "" {
+			offset, _ = strconv.Atoi(m[2])
+		}
+		y, mon, d := ref.Date()
+		loc := ref.Location()
+		switch m[1] {
+		case "day":
+			return time.Date(y, mon, d+offset, 0, 0, 0, 0, loc), nil
+		case "week":
+			return time.Date(y, mon, d-int(ref.Weekday())+7*offset, 0, 0, 0, 0, loc), nil
+		case "month":
+			return time.Date(y, mon+time.Month(offset), 1, 0, 0, 0, 0, loc), nil
+		default:
+			return time.Date(y+offset, 1, 1, 0, 0, 0, 0, loc), nil
+		}
+	}
+	for _, layout := range []string{queryDateFormat, "20060102T150405"} {
+		if t, err := time.ParseInLocation(layout, s, ref.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("date must be YYYYMMDD or relative, like day-1")
+}
+
+// Match returns true if the note matches the query. Terms that need the
+// note's content, like words and todo:, are matched against the title if
+// the note's content hasn't been loaded.
+func (q *Query) Match(n *Note) bool {
+	if len(q.Terms) == 0 {
+		return true
+	}
+	for _, t := range q.Terms {
+		m := t.match(n, q.Notebooks) != t.Negated
+		if q.Any && m {
+			return true
+		}
+		if !q.Any && !m {
+			return false
+		}
+	}
+	return !q.Any
+}
+
+var (
+	markupTag  = regexp.MustCompile(`<[^>]*>`)
+	todoTag    = regexp.MustCompile(`<en-todo[^>]*>`)
+	mediaType  = regexp.MustCompile(`<en-media[^>]*\stype="([^"]*)"`)
+	todoIsDone = regexp.MustCompile(`checked="true"`)
+)
+
+func (t *QueryTerm) match(n *Note, notebooks map[string]string) bool {
+	switch t.Field {
+	case "intitle":
+		return containsFold(n.Title, t.Value)
+	case "notebook":
+		if n.Notebook == nil {
+			return false
+		}
+		name := n.Notebook.Name
+		if name == "" {
+			name = notebooks[n.Notebook.GUID]
+		}
+		return strings.EqualFold(name, t.Value)
+	case "tag":
+		for _, tag := range n.Tags {
+			if matchWildcard(t.Value, tag) {
+				return true
+			}
+		}
+		return false
+	case "created":
+		return matchQueryDate(t.Value, n.Created)
+	case "updated":
+		return matchQueryDate(t.Value, n.Updated)
+	case "resource":
+		for _, r := range n.Resources {
+			if matchWildcard(t.Value, r.Mime) {
+				return true
+			}
+		}
+		for _, m := range mediaType.FindAllStringSubmatch(n.Body, -1) {
+			if matchWildcard(t.Value, m[1]) {
+				return true
+			}
+		}
+		return false
+	case "todo":
+		for _, todo := range todoTag.FindAllString(n.Body, -1) {
+			checked := todoIsDone.MatchString(todo)
+			if t.Value == "*" || (t.Value == "true") == checked {
+				return true
+			}
+		}
+		return false
+	case "encryption":
+		return strings.Contains(n.Body, "<en-crypt")
+	case "source":
+		return n.Attributes != nil && matchWildcard(t.Value, n.Attributes.Source)
+	case "sourceUrl":
+		return n.Attributes != nil && matchWildcard(t.Value, n.Attributes.SourceURL)
+	case "author":
+		return n.Attributes != nil && matchWildcard(t.Value, n.Attributes.Author)
+	case "reminderOrder":
+		return n.Attributes != nil && n.Attributes.ReminderOrder != 0
+	case "reminderTime":
+		return n.Attributes != nil && matchQueryDate(t.Value, n.Attributes.ReminderTime)
+	case "reminderDoneTime":
+		return n.Attributes != nil && matchQueryDate(t.Value, n.Attributes.ReminderDoneTime)
+	}
+	word := strings.TrimSuffix(t.Value, "*")
+	if word == "" {
+		return true
+	}
+	return containsFold(n.Title, word) || containsFold(noteText(n), word)
+}
+
+// matchQueryDate returns true if the timestamp, in milliseconds, is on or
+// after the date. The value * matches any timestamp that is set.
+func matchQueryDate(value string, timestamp int64) bool {
+	if timestamp == 0 {
+		return false
+	}
+	if value == "*" {
+		return true
+	}
+	date, err := parseQueryDate(value, now())
+	if err != nil {
+		return false
+	}
+	return timestamp >= date.UnixNano()/int64(time.Millisecond)
+}
+
+// matchWildcard matches the value case-insensitively against the pattern.
+// A * in the pattern matches any sequence of characters.
+func matchWildcard(pattern, value string) bool {
+	if value == "" {
+		return false
+	}
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	if err == nil && ok {
+		return true
+	}
+	// path.Match doesn't let * match /, which is needed for mime types
+	// like application/* when the pattern is *.
+	return pattern == "*"
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func noteText(n *Note) string {
+	if n.MD != "" {
+		return n.MD
+	}
+	return html.UnescapeString(markupTag.ReplaceAllString(n.Body, " "))
+}
+
+// FilterNotes returns the notes matching the query.
+func FilterNotes(notes []*Note, q *Query) []*Note {
+	var a []*Note
+	for _, n := range notes {
+		if q.Match(n) {
+			a = append(a, n)
+		}
+	}
+	return a
+}
+
+// FindCachedNotes evaluates the query locally against the notes from the
+// last note list, without contacting the server. If notebook is set, the
+// notes are restricted to the cached notebook with the name. Notebook
+// names are taken from the notebook cache.
+func FindCachedNotes(db Storager, query, notebook string) ([]*Note, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := db.GetSearch()
+	if err != nil {
+		return nil, err
+	}
+	list, err := db.GetNotebookCache()
+	if err != nil {
+		return nil, err
+	}
+	q.Notebooks = make(map[string]string, len(list.Notebooks))
+	for _, b := range list.Notebooks {
+		q.Notebooks[b.GUID] = b.Name
+	}
+	if notebook != "" {
+		guid, err := cachedNotebookGUID(db, notebook)
+		if err != nil {
+			return nil, err
+		}
+		var a []*Note
+		for _, n := range notes {
+			if n.Notebook != nil && n.Notebook.GUID == guid {
+				a = append(a, n)
+			}
+		}
+		notes = a
+	}
+	return FilterNotes(notes, q), nil
+}
+
+func cachedNotebookGUID(db Storager, name string) (string, error) {
+	list, err := db.GetNotebookCache()
+	if err != nil {
+		return "", err
+	}
+	for _, b := range list.Notebooks {
+		if b.Name == name {
+			return b.GUID, nil
+		}
+	}
+	return "", ErrNoNotebookCached
+}
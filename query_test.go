@@ -0,0 +1,192 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	assert := assert.New(t)
+	b := &QueryBuilder{
+		Words:         "meeting",
+		InTitle:       "weekly sync",
+		Tags:          []string{"work"},
+		NotTags:       []string{"done"},
+		CreatedAfter:  time.Date(2018, 5, 1, 0, 0, 0, 0, time.Local),
+		UpdatedBefore: time.Date(2018, 6, 1, 0, 0, 0, 0, time.Local),
+		HasAttachment: true,
+		Todo:          "unchecked",
+		Reminder:      true,
+		Source:        "web.clip",
+	}
+	query, err := b.Build()
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(`meeting intitle:"weekly sync" tag:work -tag:done created:20180501 -updated:20180601 resource:* todo:false reminderOrder:* source:web.clip`, query, "Wrong query")
+
+	query, err = new(QueryBuilder).Build()
+	assert.NoError(err, "Empty builder should be valid")
+	assert.Equal("", query, "Empty builder should match all notes")
+
+	_, err = (&QueryBuilder{Todo: "maybe"}).Build()
+	assert.Equal(ErrInvalidTodo, err, "Wrong error for invalid todo")
+	_, err = (&QueryBuilder{Words: `"open quote`}).Build()
+	assert.Equal(ErrUnbalancedQuotes, err, "Wrong error for unbalanced quotes")
+}
+
+func TestParseQuery(t *testing.T) {
+	assert := assert.New(t)
+	q, err := ParseQuery(`any: "two words" -tag:done intitle:"a title" http://example.com`)
+	assert.NoError(err, "Should not return an error")
+	assert.True(q.Any, "any: should be set")
+	assert.Equal([]*QueryTerm{
+		{Value: "two words"},
+		{Negated: true, Field: "tag", Value: "done"},
+		{Field: "intitle", Value: "a title"},
+		{Value: "http://example.com"},
+	}, q.Terms, "Wrong terms")
+
+	for _, s := range []string{"created:yesterday", "todo:maybe", "tag:", "-any:", "updated:2018"} {
+		_, err := ParseQuery(s)
+		_, ok := err.(*InvalidQueryError)
+		assert.True(ok, "Should return an InvalidQueryError for "+s)
+	}
+	for _, s := range []string{"created:day-1", "updated:week", "created:20180501T101500Z", "reminderOrder:*", "encryption:"} {
+		_, err := ParseQuery(s)
+		assert.NoError(err, "Should be valid: "+s)
+	}
+}
+
+func TestParseQueryDate(t *testing.T) {
+	assert := assert.New(t)
+	// Thursday.
+	ref := time.Date(2018, 5, 10, 9, 30, 0, 0, time.Local)
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"day", "2018-05-10"},
+		{"day-1", "2018-05-09"},
+		{"week", "2018-05-06"},
+		{"week-1", "2018-04-29"},
+		{"month", "2018-05-01"},
+		{"month-5", "2017-12-01"},
+		{"year", "2018-01-01"},
+		{"20180131", "2018-01-31"},
+	}
+	for _, test := range tests {
+		date, err := parseQueryDate(test.input, ref)
+		assert.NoError(err, "Should not return an error for "+test.input)
+		assert.Equal(test.expected, date.Format(timeFormat), "Wrong date for "+test.input)
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, 5, 10, 9, 0, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+	ms := func(y int, m time.Month, d int) int64 {
+		return time.Date(y, m, d, 12, 0, 0, 0, time.Local).UnixNano() / int64(time.Millisecond)
+	}
+	note := &Note{
+		Title:      "Weekly sync",
+		Tags:       []string{"Work", "meetings"},
+		Created:    ms(2018, 5, 2),
+		Updated:    ms(2018, 5, 9),
+		Body:       `<en-note><div>Agenda &amp; notes</div><en-todo checked="true"/>Done<en-media type="application/pdf" hash="00"/></en-note>`,
+		Attributes: &NoteAttributes{Source: "web.clip", ReminderOrder: 1},
+	}
+	// The plain note only has the notebook's GUID, like the notes returned
+	// by the notestore.
+	note.Notebook = &Notebook{Name: "Work", GUID: "NB1"}
+	plain := &Note{Title: "Shopping", Created: ms(2018, 4, 1), Updated: ms(2018, 4, 1), Notebook: &Notebook{GUID: "NB2"}}
+	tests := []struct {
+		query string
+		note  bool
+		plain bool
+	}{
+		{"", true, true},
+		{"agenda", true, false},
+		{"agen*", true, false},
+		{"sync", true, false},
+		{"intitle:shopping", false, true},
+		{"tag:work", true, false},
+		{"tag:meet*", true, false},
+		{"-tag:work", false, true},
+		{"created:20180501", true, false},
+		{"-updated:20180501", false, true},
+		{"updated:day-1", true, false},
+		{"resource:*", true, false},
+		{"resource:image/*", false, false},
+		{"resource:application/pdf", true, false},
+		{"todo:*", true, false},
+		{"todo:true", true, false},
+		{"todo:false", false, false},
+		{"reminderOrder:*", true, false},
+		{"source:web.*", true, false},
+		{"tag:work intitle:shopping", false, false},
+		{"any: tag:work intitle:shopping", true, true},
+		{"notebook:work", true, false},
+		{"notebook:Personal", false, true},
+	}
+	for _, test := range tests {
+		q, err := ParseQuery(test.query)
+		if !assert.NoError(t, err, "Should parse "+test.query) {
+			continue
+		}
+		q.Notebooks = map[string]string{"NB2": "Personal"}
+		assert.Equal(t, test.note, q.Match(note), "Wrong match for note with "+test.query)
+		assert.Equal(t, test.plain, q.Match(plain), "Wrong match for plain note with "+test.query)
+	}
+}
+
+func TestFindCachedNotes(t *testing.T) {
+	assert := assert.New(t)
+	notes := []*Note{
+		{Title: "Work todo", Tags: []string{"work"}, Notebook: &Notebook{GUID: "NB1"}},
+		{Title: "Home todo", Tags: []string{"home"}, Notebook: &Notebook{GUID: "NB2"}},
+		{Title: "Work notes", Tags: []string{"work"}, Notebook: &Notebook{GUID: "NB2"}},
+	}
+	store := &mockStore{
+		getSearch: func() ([]*Note, error) { return notes, nil },
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Notebooks: []*Notebook{{Name: "Personal", GUID: "NB2"}}}, nil
+		},
+	}
+
+	list, err := FindCachedNotes(store, "tag:work", "")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Note{notes[0], notes[2]}, list, "Wrong notes")
+
+	list, err = FindCachedNotes(store, "todo", "Personal")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Note{notes[1]}, list, "Wrong notes in notebook")
+
+	list, err = FindCachedNotes(store, "notebook:personal", "")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Note{notes[1], notes[2]}, list, "Notebook name should be found from the GUID")
+
+	list, err = FindCachedNotes(store, "-notebook:Personal", "")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Note{notes[0]}, list, "Wrong notes outside the notebook")
+
+	_, err = FindCachedNotes(store, "", "Missing")
+	assert.Equal(ErrNoNotebookCached, err, "Wrong error for missing notebook")
+}
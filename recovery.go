@@ -0,0 +1,80 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import "errors"
+
+// ErrNoRecoveryEntry is returned if the recovery journal doesn't have an
+// entry at the index.
+var ErrNoRecoveryEntry = errors.New("no recovery journal entry found")
+
+// RecoveryEntry is an entry in the recovery journal. It holds a note as it
+// was before it was changed so the change can be reverted.
+type RecoveryEntry struct {
+	// Note is the note before the change. The body holds the raw content.
+	Note *Note
+	// Operation is the name of the operation that changed the note.
+	Operation string
+	// Time is when the note was changed, in seconds since the epoch.
+	Time int64
+}
+
+// GetRecoveryJournal returns the entries in the recovery journal, the
+// most recent entry first.
+func GetRecoveryJournal(db Storager) ([]*RecoveryEntry, error) {
+	entries, err := db.GetRecoveryJournal()
+	if err != nil {
+		return nil, err
+	}
+	reversed := make([]*RecoveryEntry, len(entries))
+	for i, e := range entries {
+		reversed[len(entries)-1-i] = e
+	}
+	return reversed, nil
+}
+
+// RestoreRecoveryEntry restores the note in the recovery journal entry at
+// the index, as returned by GetRecoveryJournal. The current content of the
+// note is added to the journal before it's replaced so the restore can be
+// reverted too.
+func RestoreRecoveryEntry(db Storager, ns NotestoreClient, index int) (*RecoveryEntry, error) {
+	entries, err := GetRecoveryJournal(db)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(entries) {
+		return nil, ErrNoRecoveryEntry
+	}
+	entry := entries[index]
+	current, err := ns.GetNoteContent(entry.Note.GUID)
+	if err != nil {
+		return nil, err
+	}
+	if err = addRecoveryEntry(db, entry.Note, current, "restore"); err != nil {
+		return nil, err
+	}
+	return entry, ns.UpdateNote(entry.Note)
+}
+
+// addRecoveryEntry adds the note with the raw content to the recovery journal.
+func addRecoveryEntry(db Storager, n *Note, content, operation string) error {
+	saved := *n
+	saved.Body = content
+	saved.MD = ""
+	return db.AddRecoveryEntry(&RecoveryEntry{Note: &saved, Operation: operation, Time: now().Unix()})
+}
@@ -0,0 +1,187 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+// ErrEmptyMatch is returned if the replace pattern matches empty text.
+var ErrEmptyMatch = errors.New("pattern matches empty text")
+
+// Replacement is a change to a note made by a find and replace.
+type Replacement struct {
+	// Original is the note before the change. The body holds the raw content.
+	Original *Note
+	// Changed is the note after the change. The body holds the raw content.
+	Changed *Note
+	// Count is the number of replaced matches.
+	Count int
+}
+
+// FindReplacements returns the changes to the selected notes if the
+// matches of the pattern are replaced. The replacement text can reference
+// submatches, e.g. $1. Only the text of the notes is changed, never the
+// markup or encrypted content. Notes without matches are not included.
+func FindReplacements(db Storager, ns NotestoreClient, sel *BulkSelection, re *regexp.Regexp, with string) ([]*Replacement, error) {
+	if re.MatchString("") {
+		return nil, ErrEmptyMatch
+	}
+	notes, err := SelectNotes(db, ns, sel)
+	if err != nil {
+		return nil, err
+	}
+	var rs []*Replacement
+	for _, n := range notes {
+		content, err := ns.GetNoteContent(n.GUID)
+		if err != nil {
+			return nil, err
+		}
+		changed, count := replaceText(content, re, with)
+		if count == 0 {
+			continue
+		}
+		original, updated := *n, *n
+		original.Body = content
+		updated.Body = changed
+		rs = append(rs, &Replacement{Original: &original, Changed: &updated, Count: count})
+	}
+	return rs, nil
+}
+
+// WriteReplacementDiff writes a diff of the markdown representation of
+// the change using the writer.
+func WriteReplacementDiff(w io.Writer, r *Replacement, colored bool) error {
+	from, err := replacementMD(r.Original)
+	if err != nil {
+		return err
+	}
+	to, err := replacementMD(r.Changed)
+	if err != nil {
+		return err
+	}
+	if colored {
+		return WriteColoredNoteDiff(w, from, to, "original", "replaced", DefaultNoteOption)
+	}
+	return WriteNoteDiff(w, from, to, "original", "replaced", DefaultNoteOption)
+}
+
+func replacementMD(n *Note) (*Note, error) {
+	md := &Note{Title: n.Title, Notebook: n.Notebook}
+	if err := decodeXML(n.Body, md); err != nil {
+		return nil, err
+	}
+	var err error
+	md.MD, err = markdown.FromHTML(md.Body)
+	return md, err
+}
+
+// SaveReplacement adds the original note to the recovery journal and
+// saves the change to the notestore.
+func SaveReplacement(db Storager, ns NotestoreClient, r *Replacement) error {
+	if err := addRecoveryEntry(db, r.Original, r.Original.Body, "replace"); err != nil {
+		return err
+	}
+	return ns.UpdateNote(r.Changed)
+}
+
+// replaceText applies the replacement to the text nodes of the ENML
+// content. Tags, attributes, comments and encrypted content are left
+// untouched. The new content and the number of replaced matches are
+// returned.
+func replaceText(content string, re *regexp.Regexp, with string) (string, int) {
+	buf := new(strings.Builder)
+	count, crypt := 0, 0
+	for i := 0; i < len(content); {
+		end := strings.IndexByte(content[i:], '<')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += i
+		}
+		text := content[i:end]
+		if crypt == 0 && text != "" {
+			var n int
+			text, n = replaceInText(text, re, with)
+			count += n
+		}
+		buf.WriteString(text)
+		if end == len(content) {
+			break
+		}
+		i = markupEnd(content, end)
+		tag := content[end:i]
+		switch {
+		case strings.HasPrefix(tag, "<en-crypt") && !strings.HasSuffix(tag, "/>"):
+			crypt++
+		case strings.HasPrefix(tag, "</en-crypt") && crypt > 0:
+			crypt--
+		}
+		buf.WriteString(tag)
+	}
+	return buf.String(), count
+}
+
+// replaceInText applies the replacement to an escaped text node. The text
+// is only escaped again if it was changed.
+func replaceInText(text string, re *regexp.Regexp, with string) (string, int) {
+	unescaped := html.UnescapeString(text)
+	matches := re.FindAllStringIndex(unescaped, -1)
+	if len(matches) == 0 {
+		return text, 0
+	}
+	replaced := re.ReplaceAllString(unescaped, with)
+	return textEscaper.Replace(replaced), len(matches)
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// markupEnd returns the index after the markup that starts at start. The
+// markup can be a tag, a comment, a CDATA section or a declaration.
+func markupEnd(s string, start int) int {
+	for _, delims := range [][2]string{{"<!--", "-->"}, {"<![CDATA[", "]]>"}} {
+		if strings.HasPrefix(s[start:], delims[0]) {
+			end := strings.Index(s[start:], delims[1])
+			if end < 0 {
+				return len(s)
+			}
+			return start + end + len(delims[1])
+		}
+	}
+	var quote byte
+	for i := start + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,119 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceText(t *testing.T) {
+	assert := assert.New(t)
+	re := regexp.MustCompile(`Acme(\d*)`)
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		count    int
+	}{
+		{"text", `<en-note><div>Acme and Acme2</div></en-note>`, `<en-note><div>Globex and Globex2</div></en-note>`, 2},
+		{"attributes", `<en-note><a href="https://acme.com/Acme">Acme</a></en-note>`, `<en-note><a href="https://acme.com/Acme">Globex</a></en-note>`, 1},
+		{"quoted >", `<en-note><span title="a > Acme">x</span></en-note>`, `<en-note><span title="a > Acme">x</span></en-note>`, 0},
+		{"entities", `<en-note><div>Acme &amp; co &lt;3</div></en-note>`, `<en-note><div>Globex &amp; co &lt;3</div></en-note>`, 1},
+		{"unchanged entities", `<en-note><div>&#160;other</div><div>Acme</div></en-note>`, `<en-note><div>&#160;other</div><div>Globex</div></en-note>`, 1},
+		{"comment", `<en-note><!-- Acme --><div>Acme</div></en-note>`, `<en-note><!-- Acme --><div>Globex</div></en-note>`, 1},
+		{"encrypted", `<en-note><en-crypt cipher="AES">Acme</en-crypt>Acme</en-note>`, `<en-note><en-crypt cipher="AES">Acme</en-crypt>Globex</en-note>`, 1},
+		{"header", XMLHeader + `<en-note>Acme</en-note>`, XMLHeader + `<en-note>Globex</en-note>`, 1},
+	}
+	for _, test := range tests {
+		actual, count := replaceText(test.content, re, "Globex$1")
+		assert.Equal(test.expected, actual, "Wrong content for "+test.name)
+		assert.Equal(test.count, count, "Wrong count for "+test.name)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	assert := assert.New(t)
+	var journal []*RecoveryEntry
+	store := &mockStore{
+		getNotebookCache:   func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList:  func(list *NotebookCacheList) error { return nil },
+		addRecoveryEntry:   func(e *RecoveryEntry) error { journal = append(journal, e); return nil },
+		getRecoveryJournal: func() ([]*RecoveryEntry, error) { return journal, nil },
+	}
+	contents := map[string]string{
+		"1": XMLHeader + "<en-note><div>Acme rocks</div></en-note>",
+		"2": XMLHeader + "<en-note><div>Nothing here</div></en-note>",
+	}
+	var updated []*Note
+	ns := &mockNS{
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			return []*Note{
+				&Note{Title: "One", GUID: "1", Notebook: &Notebook{GUID: "NB"}},
+				&Note{Title: "Two", GUID: "2", Notebook: &Notebook{GUID: "NB"}},
+			}, nil
+		},
+		getNoteContent: func(guid string) (string, error) { return contents[guid], nil },
+		updateNote: func(n *Note) error {
+			updated = append(updated, n)
+			contents[n.GUID] = n.Body
+			return nil
+		},
+	}
+	sel := &BulkSelection{Search: "Acme"}
+
+	rs, err := FindReplacements(store, ns, sel, regexp.MustCompile("Acme"), "Globex")
+	assert.NoError(err, "Should not return an error")
+	if !assert.Len(rs, 1, "Only notes with matches should be returned") {
+		return
+	}
+	assert.Equal(1, rs[0].Count, "Wrong count")
+
+	buf := new(bytes.Buffer)
+	assert.NoError(WriteReplacementDiff(buf, rs[0], false))
+	assert.Contains(buf.String(), "-Acme rocks\n+Globex rocks\n", "Diff should show the markdown change")
+
+	assert.NoError(SaveReplacement(store, ns, rs[0]), "Should not return an error")
+	assert.Equal(XMLHeader+"<en-note><div>Globex rocks</div></en-note>", contents["1"], "Change not saved")
+	if assert.Len(journal, 1, "Original should be added to the journal") {
+		assert.Equal("replace", journal[0].Operation)
+		assert.Equal(XMLHeader+"<en-note><div>Acme rocks</div></en-note>", journal[0].Note.Body)
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		entry, err := RestoreRecoveryEntry(store, ns, 0)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("One", entry.Note.Title)
+		assert.Equal(XMLHeader+"<en-note><div>Acme rocks</div></en-note>", contents["1"], "Note not restored")
+		entries, err := GetRecoveryJournal(store)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(entries, 2, "Restore should be added to the journal") {
+			assert.Equal("restore", entries[0].Operation, "Most recent entry should be first")
+			assert.Equal(XMLHeader+"<en-note><div>Globex rocks</div></en-note>", entries[0].Note.Body)
+		}
+		_, err = RestoreRecoveryEntry(store, ns, 5)
+		assert.Equal(ErrNoRecoveryEntry, err, "Wrong error")
+	})
+	t.Run("empty match", func(t *testing.T) {
+		_, err := FindReplacements(store, ns, sel, regexp.MustCompile("x*"), "y")
+		assert.Equal(ErrEmptyMatch, err, "Wrong error")
+	})
+}
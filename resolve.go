@@ -0,0 +1,215 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GUIDPrefix is the prefix of a note reference using the note's GUID.
+	GUIDPrefix = "guid:"
+	// IndexPrefix is the prefix of a note reference using the note's
+	// index in the last saved search.
+	IndexPrefix = "#"
+	// guidLength is the length of a full GUID.
+	guidLength = 36
+	// shortGUIDLength is the number of characters shown of a GUID when
+	// listing candidates.
+	shortGUIDLength = 8
+	// titleSearchCount is the number of notes searched for a matching title.
+	titleSearchCount = 20
+	// guidSearchCount is the number of recent notes searched for a GUID prefix.
+	guidSearchCount = 250
+)
+
+// AmbiguousNoteError is returned when a note reference matches more than
+// one note.
+type AmbiguousNoteError struct {
+	// Reference is the reference used to find the note.
+	Reference string
+	// Candidates are the notes matching the reference.
+	Candidates []*Note
+}
+
+func (e *AmbiguousNoteError) Error() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%q matches %d notes, use one of:", e.Reference, len(e.Candidates))
+	for _, n := range e.Candidates {
+		fmt.Fprintf(buf, "\n  %s%s  %s", GUIDPrefix, shortGUID(n.GUID), n.Title)
+	}
+	return buf.String()
+}
+
+// IsGUID returns true if the text is a full note GUID.
+func IsGUID(s string) bool {
+	return len(s) == guidLength && guidRegexp.MatchString(s)
+}
+
+// ResolveNote finds the note the reference points to. The reference can be
+// one of:
+//
+//	guid:<GUID>      the note with the GUID or GUID prefix
+//	#<index>         the note at the index in the last saved search
+//	Notebook/Title   the note with the title in the notebook
+//	Title            the note with the title
+//
+// A plain number is first matched against the note titles and, if no note
+// has the number as title, used as an index in the last saved search.
+// If notebook is not an empty string, the search is restricted to the notebook.
+// An AmbiguousNoteError is returned if more than one note matches.
+func ResolveNote(db Storager, ns NotestoreClient, ref, notebook string) (*Note, error) {
+	return resolveNote(db, ns, ref, notebook, false)
+}
+
+// resolveNote resolves the reference. If inactive is true, only the notes
+// in the trash are searched and indexes refer to the last trash listing.
+func resolveNote(db Storager, ns NotestoreClient, ref, notebook string, inactive bool) (*Note, error) {
+	switch {
+	case strings.HasPrefix(ref, GUIDPrefix):
+		return findNoteByGUID(db, ns, ref, notebook, inactive)
+	case strings.HasPrefix(ref, IndexPrefix):
+		index, err := strconv.Atoi(strings.TrimPrefix(ref, IndexPrefix))
+		if err != nil {
+			break
+		}
+		return findNoteByIndex(db, index, inactive)
+	}
+	if notebook == "" {
+		if i := strings.Index(ref, "/"); i > 0 {
+			// The slash can be part of the title so the whole reference
+			// is searched as a title if no note is found in the notebook.
+			n, err := findNoteByTitle(db, ns, ref, ref[i+1:], ref[:i], inactive)
+			if err != ErrNoNotebookFound && err != ErrNoNoteFound {
+				return n, err
+			}
+		}
+	}
+	n, err := findNoteByTitle(db, ns, ref, ref, notebook, inactive)
+	if err != ErrNoNoteFound {
+		return n, err
+	}
+	if index, e := strconv.Atoi(ref); e == nil && index > 0 {
+		return findNoteByIndex(db, index, inactive)
+	}
+	return nil, err
+}
+
+// savedNotes returns the last saved search or, if inactive is true, the
+// last trash listing.
+func savedNotes(db Storager, inactive bool) ([]*Note, error) {
+	if inactive {
+		return db.GetTrashSearch()
+	}
+	return db.GetSearch()
+}
+
+func findNoteByIndex(db Storager, index int, inactive bool) (*Note, error) {
+	notes, err := savedNotes(db, inactive)
+	if err != nil {
+		return nil, err
+	}
+	if index < 1 || index > len(notes) {
+		return nil, ErrNoNoteFound
+	}
+	return notes[index-1], nil
+}
+
+func findNoteByTitle(db Storager, ns NotestoreClient, ref, title, notebook string, inactive bool) (*Note, error) {
+	filter := &NoteFilter{Words: title, Inactive: inactive}
+	if notebook != "" {
+		nb, err := findNotebook(db, ns, notebook)
+		if err != nil {
+			return nil, err
+		}
+		filter.NotebookGUID = nb.GUID
+	}
+	notes, err := ns.FindNotes(filter, 0, titleSearchCount)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*Note
+	for _, n := range notes {
+		if n.Title == title {
+			matches = append(matches, n)
+		}
+	}
+	return pickNote(ref, matches)
+}
+
+func findNoteByGUID(db Storager, ns NotestoreClient, ref, notebook string, inactive bool) (*Note, error) {
+	guid := strings.TrimPrefix(ref, GUIDPrefix)
+	if guid == "" {
+		return nil, ErrNoNoteFound
+	}
+	if len(guid) == guidLength {
+		return ns.GetNote(guid)
+	}
+	// The notestore can't search for a GUID prefix so the last saved
+	// search and the most recent notes are searched instead.
+	filter := &NoteFilter{Inactive: inactive}
+	if notebook != "" {
+		nb, err := findNotebook(db, ns, notebook)
+		if err != nil {
+			return nil, err
+		}
+		filter.NotebookGUID = nb.GUID
+	}
+	saved, err := savedNotes(db, inactive)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := ns.FindNotes(filter, 0, guidSearchCount)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var matches []*Note
+	for _, n := range append(saved, recent...) {
+		if seen[n.GUID] || !strings.HasPrefix(n.GUID, guid) {
+			continue
+		}
+		if filter.NotebookGUID != "" && (n.Notebook == nil || n.Notebook.GUID != filter.NotebookGUID) {
+			continue
+		}
+		seen[n.GUID] = true
+		matches = append(matches, n)
+	}
+	return pickNote(ref, matches)
+}
+
+func pickNote(ref string, matches []*Note) (*Note, error) {
+	switch len(matches) {
+	case 0:
+		return nil, ErrNoNoteFound
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, &AmbiguousNoteError{Reference: ref, Candidates: matches}
+	}
+}
+
+func shortGUID(guid string) string {
+	if len(guid) > shortGUIDLength {
+		return guid[:shortGUIDLength]
+	}
+	return guid
+}
@@ -0,0 +1,143 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveNote(t *testing.T) {
+	assert := assert.New(t)
+	work := &Notebook{Name: "Work", GUID: "WORK"}
+	home := &Notebook{Name: "Home", GUID: "HOME"}
+	workTodo := &Note{Title: "Todo", GUID: "aaaa1111-0000", Notebook: work}
+	homeTodo := &Note{Title: "Todo", GUID: "aaaa2222-0000", Notebook: home}
+	numbered := &Note{Title: "2018", GUID: "bbbb1111-0000", Notebook: home}
+	notes := []*Note{workTodo, homeTodo, numbered}
+	saved := []*Note{numbered, homeTodo}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+		getSearch:         func() ([]*Note, error) { return saved, nil },
+	}
+	ns := &mockNS{
+		getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{work, home}, nil },
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			var found []*Note
+			for _, n := range notes {
+				if f.NotebookGUID == "" || n.Notebook.GUID == f.NotebookGUID {
+					found = append(found, n)
+				}
+			}
+			return found, nil
+		},
+	}
+
+	t.Run("ambiguous title", func(t *testing.T) {
+		_, err := ResolveNote(store, ns, "Todo", "")
+		amb, ok := err.(*AmbiguousNoteError)
+		if assert.True(ok, "Should return an AmbiguousNoteError") {
+			assert.Equal([]*Note{workTodo, homeTodo}, amb.Candidates, "Wrong candidates")
+			assert.Contains(amb.Error(), "guid:aaaa1111", "Candidates should be listed")
+		}
+	})
+	t.Run("notebook and title", func(t *testing.T) {
+		n, err := ResolveNote(store, ns, "Home/Todo", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(homeTodo, n, "Wrong note returned")
+	})
+	t.Run("title with slash", func(t *testing.T) {
+		slashed := &Note{Title: "Either/Or", GUID: "cccc", Notebook: work}
+		notes = append(notes, slashed)
+		defer func() { notes = notes[:len(notes)-1] }()
+		n, err := ResolveNote(store, ns, "Either/Or", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(slashed, n, "Wrong note returned")
+	})
+	t.Run("title with slash starting with a notebook name", func(t *testing.T) {
+		slashed := &Note{Title: "Home/Office", GUID: "dddd", Notebook: work}
+		notes = append(notes, slashed)
+		defer func() { notes = notes[:len(notes)-1] }()
+		n, err := ResolveNote(store, ns, "Home/Office", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(slashed, n, "Wrong note returned")
+	})
+	t.Run("notebook flag", func(t *testing.T) {
+		n, err := ResolveNote(store, ns, "Todo", "Work")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(workTodo, n, "Wrong note returned")
+	})
+	t.Run("index", func(t *testing.T) {
+		n, err := ResolveNote(store, ns, "#2", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(homeTodo, n, "Wrong note returned")
+	})
+	t.Run("index out of range", func(t *testing.T) {
+		_, err := ResolveNote(store, ns, "#3", "")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+	t.Run("number as title", func(t *testing.T) {
+		n, err := ResolveNote(store, ns, "2018", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(numbered, n, "Title should match before index")
+	})
+	t.Run("number as index", func(t *testing.T) {
+		n, err := ResolveNote(store, ns, "1", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(numbered, n, "Wrong note returned")
+	})
+	t.Run("guid prefix", func(t *testing.T) {
+		n, err := ResolveNote(store, ns, "guid:aaaa1", "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(workTodo, n, "Wrong note returned")
+	})
+	t.Run("ambiguous guid prefix", func(t *testing.T) {
+		_, err := ResolveNote(store, ns, "guid:aaaa", "")
+		amb, ok := err.(*AmbiguousNoteError)
+		if assert.True(ok, "Should return an AmbiguousNoteError") {
+			assert.Len(amb.Candidates, 2, "Duplicates should be removed")
+		}
+	})
+	t.Run("full guid", func(t *testing.T) {
+		guid := "12345678-1234-1234-1234-123456789012"
+		expected := &Note{Title: "Full", GUID: guid}
+		ns.getNote = func(g string) (*Note, error) {
+			if g != guid {
+				return nil, ErrNoNoteFound
+			}
+			return expected, nil
+		}
+		n, err := ResolveNote(store, ns, GUIDPrefix+guid, "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected, n, "Wrong note returned")
+	})
+	t.Run("unknown guid", func(t *testing.T) {
+		_, err := ResolveNote(store, ns, "guid:ffff", "")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+}
+
+func TestIsGUID(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(IsGUID("2b94fb1c-e6ab-4b3e-8c5a-3f0d7b9e1a2c"), "Full GUID should be valid")
+	assert.False(IsGUID("2b94fb1c"), "GUID prefix should not be valid")
+	assert.False(IsGUID("2b94fb1c-e6ab-4b3e-8c5a-3f0d7b9e1a2c/x"), "Trailing text should not be valid")
+	assert.False(IsGUID(""), "Empty text should not be valid")
+}
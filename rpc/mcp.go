@@ -0,0 +1,210 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// The Model Context Protocol (MCP) runs on top of JSON-RPC. A client
+// starts with initialize and then lists and calls the tools. Each tool
+// calls one of the JSON-RPC methods.
+
+// MCPVersion is the latest MCP version spoken by the server.
+const MCPVersion = "2025-06-18"
+
+// mcpVersions are the MCP versions the server can speak. The tools only
+// use features that are the same in all of them.
+var mcpVersions = []string{"2024-11-05", "2025-03-26", MCPVersion}
+
+const (
+	// ServerName is the name the server gives in the MCP handshake.
+	ServerName = "clinote"
+	// APIVersion is the version of the methods. It is the server's version
+	// in the MCP handshake.
+	APIVersion = "1"
+)
+
+// tool is an MCP tool calling a JSON-RPC method.
+type tool struct {
+	method string
+	// description and params are used instead of the method's if set.
+	description string
+	params      string
+}
+
+// tools are the MCP tools by name. MCP clients can't handle the page
+// notifications so notes_find doesn't stream.
+var tools = map[string]*tool{
+	"notes_find": {method: "notes.find",
+		description: "Searches for notes with the Evernote search grammar, search aliases and saved searches. " +
+			"Returns a page of notes, more is set if there are more notes after the page.",
+		params: `{"type": "object", "properties": {
+		"search": {"type": "string"},
+		"notebook": {"type": "string"},
+		"offset": {"type": "integer", "minimum": 0},
+		"count": {"type": "integer", "minimum": 1, "maximum": 100, "description": "Notes per page."}}}`},
+	"notes_get":      {method: "notes.get"},
+	"notes_create":   {method: "notes.create"},
+	"notes_save":     {method: "notes.save"},
+	"notebooks_list": {method: "notebooks.list"},
+}
+
+// Implementation names the server or client in the MCP handshake.
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type initializeParams struct {
+	ProtocolVersion string          `json:"protocolVersion"`
+	Capabilities    json.RawMessage `json:"capabilities"`
+	ClientInfo      *Implementation `json:"clientInfo"`
+}
+
+// InitializeResult is the result of initialize.
+type InitializeResult struct {
+	ProtocolVersion string                     `json:"protocolVersion"`
+	Capabilities    map[string]json.RawMessage `json:"capabilities"`
+	ServerInfo      *Implementation            `json:"serverInfo"`
+}
+
+// Tool describes an MCP tool. It is returned by tools/list.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// ToolList is the result of tools/list.
+type ToolList struct {
+	Tools []*Tool `json:"tools"`
+}
+
+type callParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// Content is a text content block of a tool result.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ToolResult is the result of tools/call. Errors from the tool are
+// returned in the result with IsError set so the model can see them.
+type ToolResult struct {
+	Content []*Content `json:"content"`
+	IsError bool       `json:"isError,omitempty"`
+}
+
+// decodeMCPParams decodes the parameters of an MCP method. Unlike
+// decodeParams, unknown fields are allowed since MCP adds fields like
+// _meta to any request.
+func decodeMCPParams(req *Request, v interface{}) error {
+	if len(req.Params) == 0 || string(req.Params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return invalidParams(strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+// initialize answers the client's handshake. The client's version is used
+// if the server speaks it, otherwise the latest version is offered.
+func initialize(s *Server, req *Request) (interface{}, error) {
+	var p initializeParams
+	if err := decodeMCPParams(req, &p); err != nil {
+		return nil, err
+	}
+	version := MCPVersion
+	for _, v := range mcpVersions {
+		if v == p.ProtocolVersion {
+			version = v
+		}
+	}
+	return &InitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    map[string]json.RawMessage{"tools": json.RawMessage("{}")},
+		ServerInfo:      &Implementation{Name: ServerName, Version: APIVersion},
+	}, nil
+}
+
+// ping returns an empty result. It's also used for the initialized
+// notification which needs no handling.
+func ping(s *Server, req *Request) (interface{}, error) {
+	return struct{}{}, nil
+}
+
+func listTools(s *Server, req *Request) (interface{}, error) {
+	var p struct {
+		Cursor string `json:"cursor"`
+	}
+	if err := decodeMCPParams(req, &p); err != nil {
+		return nil, err
+	}
+	l := &ToolList{}
+	for name, t := range tools {
+		description, params := t.description, t.params
+		if description == "" {
+			description = methods[t.method].description
+		}
+		if params == "" {
+			params = methods[t.method].params
+		}
+		l.Tools = append(l.Tools, &Tool{Name: name, Description: description, InputSchema: compact(params)})
+	}
+	sort.Slice(l.Tools, func(i, j int) bool { return l.Tools[i].Name < l.Tools[j].Name })
+	return l, nil
+}
+
+// callTool calls the tool's method with the arguments. The method's
+// result is returned as JSON text.
+func callTool(s *Server, req *Request) (interface{}, error) {
+	var p callParams
+	if err := decodeMCPParams(req, &p); err != nil {
+		return nil, err
+	}
+	t, ok := tools[p.Name]
+	if !ok {
+		return nil, invalidParams("unknown tool: " + p.Name)
+	}
+	var stream struct {
+		Stream bool `json:"stream"`
+	}
+	if json.Unmarshal(p.Arguments, &stream) == nil && stream.Stream {
+		return toolError(invalidParams("stream can't be used by tools")), nil
+	}
+	result, err := methods[t.method].handler(s, &Request{Version: Version, ID: req.ID, Method: t.method, Params: p.Arguments})
+	if err != nil {
+		return toolError(err), nil
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &ToolResult{Content: []*Content{{Type: "text", Text: string(b)}}}, nil
+}
+
+func toolError(err error) *ToolResult {
+	return &ToolResult{Content: []*Content{{Type: "text", Text: rpcError(err).Message}}, IsError: true}
+}
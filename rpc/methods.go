@@ -0,0 +1,440 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/jsonapi"
+)
+
+// method is a JSON-RPC method with the JSON schemas of its parameters
+// and result.
+type method struct {
+	description string
+	params      string
+	result      string
+	handler     func(s *Server, req *Request) (interface{}, error)
+}
+
+// MethodSchema describes a method. It is returned by rpc.discover.
+type MethodSchema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Params      json.RawMessage `json:"params"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// Discovery is the result of rpc.discover.
+type Discovery struct {
+	Version       string          `json:"jsonrpc"`
+	Notifications []*MethodSchema `json:"notifications"`
+	Methods       []*MethodSchema `json:"methods"`
+}
+
+const (
+	noteSchema = `{"type": "object", "properties": {
+		"guid": {"type": "string"},
+		"title": {"type": "string"},
+		"notebook": {"type": "string"},
+		"notebookGuid": {"type": "string"},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"created": {"type": "integer", "description": "Milliseconds since the epoch."},
+		"updated": {"type": "integer", "description": "Milliseconds since the epoch."},
+		"markdown": {"type": "string"},
+		"enml": {"type": "string", "description": "The content inside the en-note element."}}}`
+	notebookSchema = `{"type": "object", "properties": {
+		"guid": {"type": "string"},
+		"name": {"type": "string"},
+		"stack": {"type": "string"},
+		"default": {"type": "boolean"}}}`
+	pageSchema = `{"type": "object", "properties": {
+		"id": {"description": "The ID of the notes.find request."},
+		"offset": {"type": "integer"},
+		"notes": {"type": "array", "items": ` + noteSchema + `}}}`
+)
+
+var methods = map[string]*method{
+	"notes.find": {
+		description: "Searches for notes with the Evernote search grammar, search aliases and saved searches. " +
+			"With stream set, every page is sent as a " + PageNotification + " notification and the result is the total number of notes.",
+		params: `{"type": "object", "properties": {
+			"search": {"type": "string"},
+			"notebook": {"type": "string"},
+			"offset": {"type": "integer", "minimum": 0},
+			"count": {"type": "integer", "minimum": 1, "maximum": 100, "description": "Notes per page."},
+			"stream": {"type": "boolean"},
+			"limit": {"type": "integer", "minimum": 0, "description": "Maximum number of notes to stream, 0 for all."}}}`,
+		result: `{"type": "object", "properties": {
+			"notes": {"type": "array", "items": ` + noteSchema + `},
+			"offset": {"type": "integer"},
+			"more": {"type": "boolean"},
+			"total": {"type": "integer", "description": "Only set when streaming."}}}`,
+		handler: findNotes,
+	},
+	"notes.get": {
+		description: "Returns a note with its content. The ref is a title, notebook/title, guid:GUID or an index from the last search.",
+		params: `{"type": "object", "required": ["ref"], "properties": {
+			"ref": {"type": "string"},
+			"format": {"type": "string", "enum": ["markdown", "enml", "both"]}}}`,
+		result:  noteSchema,
+		handler: getNote,
+	},
+	"notes.create": {
+		description: "Creates a new note from markdown or ENML.",
+		params: `{"type": "object", "required": ["title"], "properties": {
+			"title": {"type": "string"},
+			"notebook": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"markdown": {"type": "string"},
+			"enml": {"type": "string"}}}`,
+		result:  noteSchema,
+		handler: createNote,
+	},
+	"notes.save": {
+		description: "Saves new content for a note. The title and notebook are changed if given.",
+		params: `{"type": "object", "required": ["ref"], "properties": {
+			"ref": {"type": "string"},
+			"title": {"type": "string"},
+			"notebook": {"type": "string"},
+			"markdown": {"type": "string"},
+			"enml": {"type": "string"}}}`,
+		result:  noteSchema,
+		handler: saveNote,
+	},
+	"notebooks.list": {
+		description: "Returns the user's notebooks. The cached notebooks are used unless sync is set.",
+		params:      `{"type": "object", "properties": {"sync": {"type": "boolean"}}}`,
+		result:      `{"type": "array", "items": ` + notebookSchema + `}`,
+		handler:     listNotebooks,
+	},
+}
+
+func init() {
+	// rpc.discover and the MCP methods use the other methods so they are
+	// added after the map is initialized.
+	methods["rpc.discover"] = &method{
+		description: "Returns the methods and notifications with the JSON schemas of their parameters and results.",
+		params:      `{"type": "object", "properties": {}}`,
+		result:      `{"type": "object", "properties": {"jsonrpc": {"type": "string"}, "notifications": {"type": "array"}, "methods": {"type": "array"}}}`,
+		handler:     discover,
+	}
+	methods["initialize"] = &method{
+		description: "Starts an MCP session. Returns the MCP version and the server's capabilities.",
+		params: `{"type": "object", "properties": {
+			"protocolVersion": {"type": "string"},
+			"capabilities": {"type": "object"},
+			"clientInfo": {"type": "object", "properties": {"name": {"type": "string"}, "version": {"type": "string"}}}}}`,
+		result: `{"type": "object", "properties": {
+			"protocolVersion": {"type": "string"},
+			"capabilities": {"type": "object"},
+			"serverInfo": {"type": "object", "properties": {"name": {"type": "string"}, "version": {"type": "string"}}}}}`,
+		handler: initialize,
+	}
+	methods["notifications/initialized"] = &method{
+		description: "Sent by MCP clients after initialize. Nothing is done.",
+		params:      `{"type": "object", "properties": {}}`,
+		result:      `{"type": "object", "properties": {}}`,
+		handler:     ping,
+	}
+	methods["ping"] = &method{
+		description: "Returns an empty result.",
+		params:      `{"type": "object", "properties": {}}`,
+		result:      `{"type": "object", "properties": {}}`,
+		handler:     ping,
+	}
+	methods["tools/list"] = &method{
+		description: "Returns the MCP tools. Each tool calls the method with the same name, with the dot replaced by an underscore.",
+		params:      `{"type": "object", "properties": {"cursor": {"type": "string"}}}`,
+		result: `{"type": "object", "properties": {"tools": {"type": "array", "items": {"type": "object", "properties": {
+			"name": {"type": "string"},
+			"description": {"type": "string"},
+			"inputSchema": {"type": "object"}}}}}}`,
+		handler: listTools,
+	}
+	methods["tools/call"] = &method{
+		description: "Calls an MCP tool. The method's result is returned as JSON text and its errors with isError set.",
+		params: `{"type": "object", "required": ["name"], "properties": {
+			"name": {"type": "string"},
+			"arguments": {"type": "object"}}}`,
+		result: `{"type": "object", "properties": {
+			"content": {"type": "array", "items": {"type": "object", "properties": {"type": {"type": "string"}, "text": {"type": "string"}}}},
+			"isError": {"type": "boolean"}}}`,
+		handler: callTool,
+	}
+}
+
+func discover(s *Server, req *Request) (interface{}, error) {
+	d := &Discovery{
+		Version: Version,
+		Notifications: []*MethodSchema{{
+			Name:        PageNotification,
+			Description: "A page of notes from a streamed notes.find request.",
+			Params:      compact(pageSchema),
+			Result:      json.RawMessage("null"),
+		}},
+	}
+	for name, m := range methods {
+		d.Methods = append(d.Methods, &MethodSchema{
+			Name:        name,
+			Description: m.description,
+			Params:      compact(m.params),
+			Result:      compact(m.result),
+		})
+	}
+	sort.Slice(d.Methods, func(i, j int) bool { return d.Methods[i].Name < d.Methods[j].Name })
+	return d, nil
+}
+
+// compact removes the indentation from the schema.
+func compact(schema string) json.RawMessage {
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(schema)); err != nil {
+		panic("invalid schema: " + err.Error())
+	}
+	return json.RawMessage(b.Bytes())
+}
+
+type findParams struct {
+	Search   string `json:"search"`
+	Notebook string `json:"notebook"`
+	Offset   int    `json:"offset"`
+	Count    int    `json:"count"`
+	Stream   bool   `json:"stream"`
+	Limit    int    `json:"limit"`
+}
+
+type findResult struct {
+	Notes  []*jsonapi.Note `json:"notes,omitempty"`
+	Offset int             `json:"offset"`
+	More   bool            `json:"more"`
+	Total  int             `json:"total,omitempty"`
+}
+
+type page struct {
+	ID     json.RawMessage `json:"id"`
+	Offset int             `json:"offset"`
+	Notes  []*jsonapi.Note `json:"notes"`
+}
+
+func findNotes(s *Server, req *Request) (interface{}, error) {
+	p := findParams{Count: DefaultPageSize}
+	if err := decodeParams(req, &p); err != nil {
+		return nil, err
+	}
+	if p.Offset < 0 || p.Limit < 0 {
+		return nil, invalidParams("offset and limit can't be negative")
+	}
+	if p.Count < 1 || p.Count > MaxPageSize {
+		return nil, invalidParams("count must be between 1 and 100")
+	}
+	filter := &clinote.NoteFilter{Order: clinote.NoteFilterOrderUpdated}
+	if p.Search != "" {
+		var err error
+		if filter.Words, err = clinote.ExpandSearch(s.db, s.ns, p.Search); err != nil {
+			return nil, err
+		}
+		if _, err = clinote.ParseQuery(filter.Words); err != nil {
+			return nil, err
+		}
+	}
+	if p.Notebook != "" {
+		b, err := clinote.FindNotebook(s.db, s.ns, p.Notebook)
+		if err != nil {
+			return nil, err
+		}
+		filter.NotebookGUID = b.GUID
+	}
+	names := jsonapi.NotebookNames(s.db, s.ns)
+	if !p.Stream {
+		notes, err := clinote.FindNotes(s.ns, filter, p.Offset, p.Count)
+		if err != nil {
+			return nil, err
+		}
+		return &findResult{Notes: jsonNotes(notes, names), Offset: p.Offset, More: len(notes) == p.Count}, nil
+	}
+	// Stream the pages as notifications. A page with fewer notes than
+	// requested is the last page.
+	total := 0
+	for offset := p.Offset; ; offset += p.Count {
+		count := p.Count
+		if p.Limit > 0 && p.Limit-total < count {
+			count = p.Limit - total
+		}
+		notes, err := clinote.FindNotes(s.ns, filter, offset, count)
+		if err != nil {
+			return nil, err
+		}
+		if len(notes) > 0 {
+			if err = s.notify(PageNotification, &page{ID: req.ID, Offset: offset, Notes: jsonNotes(notes, names)}); err != nil {
+				return nil, err
+			}
+		}
+		total += len(notes)
+		if len(notes) < count {
+			return &findResult{Offset: p.Offset, Total: total}, nil
+		}
+		if p.Limit > 0 && total >= p.Limit {
+			return &findResult{Offset: p.Offset, Total: total, More: true}, nil
+		}
+	}
+}
+
+type getParams struct {
+	Ref    string `json:"ref"`
+	Format string `json:"format"`
+}
+
+func getNote(s *Server, req *Request) (interface{}, error) {
+	var p getParams
+	if err := decodeParams(req, &p); err != nil {
+		return nil, err
+	}
+	if p.Ref == "" {
+		return nil, invalidParams(errNoRef.Error())
+	}
+	if p.Format != "" && p.Format != "markdown" && p.Format != "enml" && p.Format != "both" {
+		return nil, invalidParams("format must be markdown, enml or both")
+	}
+	n, err := clinote.GetNoteWithContent(s.db, s.ns, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	note := jsonapi.NewNote(n, jsonapi.NotebookNames(s.db, s.ns))
+	if p.Format != "enml" {
+		note.Markdown = n.MD
+	}
+	if p.Format == "enml" || p.Format == "both" {
+		note.ENML = n.Body
+	}
+	return note, nil
+}
+
+type noteParams struct {
+	Ref      string   `json:"ref"`
+	Title    string   `json:"title"`
+	Notebook string   `json:"notebook"`
+	Tags     []string `json:"tags"`
+	Markdown *string  `json:"markdown"`
+	ENML     *string  `json:"enml"`
+}
+
+func createNote(s *Server, req *Request) (interface{}, error) {
+	var p noteParams
+	if err := decodeParams(req, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Title) == "" {
+		return nil, invalidParams("title is required")
+	}
+	if p.Ref != "" {
+		return nil, invalidParams("ref can't be used when creating a note")
+	}
+	if p.Markdown != nil && p.ENML != nil {
+		return nil, invalidParams("only one of markdown and enml can be given")
+	}
+	n := &clinote.Note{Title: p.Title, Tags: p.Tags}
+	if p.Notebook != "" {
+		b, err := clinote.FindNotebook(s.db, s.ns, p.Notebook)
+		if err != nil {
+			return nil, err
+		}
+		n.Notebook = b
+	}
+	raw := p.ENML != nil
+	if raw {
+		n.Body = *p.ENML
+	} else if p.Markdown != nil {
+		n.MD = *p.Markdown
+	}
+	if err := clinote.SaveNewNote(s.ns, n, raw); err != nil {
+		return nil, err
+	}
+	return jsonapi.NewNote(n, jsonapi.NotebookNames(s.db, s.ns)), nil
+}
+
+func saveNote(s *Server, req *Request) (interface{}, error) {
+	var p noteParams
+	if err := decodeParams(req, &p); err != nil {
+		return nil, err
+	}
+	if p.Ref == "" {
+		return nil, invalidParams(errNoRef.Error())
+	}
+	if p.Tags != nil {
+		return nil, invalidParams("tags can't be changed")
+	}
+	if (p.Markdown == nil) == (p.ENML == nil) {
+		return nil, invalidParams("one of markdown and enml is required")
+	}
+	n, err := clinote.GetNoteWithContent(s.db, s.ns, p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Title) != "" {
+		n.Title = p.Title
+	}
+	if p.Notebook != "" {
+		if n.Notebook, err = clinote.FindNotebook(s.db, s.ns, p.Notebook); err != nil {
+			return nil, err
+		}
+	}
+	opts := clinote.DefaultNoteOption
+	if p.ENML != nil {
+		n.Body = *p.ENML
+		opts |= clinote.RawNote
+	} else {
+		n.MD = *p.Markdown
+	}
+	if err = clinote.SaveChanges(s.ns, n, opts); err != nil {
+		return nil, err
+	}
+	return jsonapi.NewNote(n, jsonapi.NotebookNames(s.db, s.ns)), nil
+}
+
+type notebooksParams struct {
+	Sync bool `json:"sync"`
+}
+
+func listNotebooks(s *Server, req *Request) (interface{}, error) {
+	var p notebooksParams
+	if err := decodeParams(req, &p); err != nil {
+		return nil, err
+	}
+	bs, err := clinote.GetNotebooks(s.db, s.ns, p.Sync)
+	if err != nil {
+		return nil, err
+	}
+	a := make([]*jsonapi.Notebook, len(bs))
+	for i, b := range bs {
+		a[i] = jsonapi.NewNotebook(b)
+	}
+	return a, nil
+}
+
+func jsonNotes(notes []*clinote.Note, names map[string]string) []*jsonapi.Note {
+	a := make([]*jsonapi.Note, len(notes))
+	for i, n := range notes {
+		a[i] = jsonapi.NewNote(n, names)
+	}
+	return a
+}
@@ -0,0 +1,240 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+// Package rpc provides a JSON-RPC 2.0 server for the notes in an Evernote
+// account. Requests and responses are JSON values separated by newlines
+// so the server can be used over stdin and stdout by other programs.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+)
+
+// Version is the JSON-RPC version spoken by the server.
+const Version = "2.0"
+
+const (
+	// DefaultPageSize is the number of notes in a page if no count is given.
+	DefaultPageSize = 20
+	// MaxPageSize is the maximum number of notes in a page.
+	MaxPageSize = 100
+	// PageNotification is the method of the notifications sent for each
+	// page of a streamed search.
+	PageNotification = "notes.page"
+)
+
+// Error codes defined by JSON-RPC and the codes used for errors from the
+// clinote package.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeNotFound       = -32001
+	CodeAmbiguous      = -32002
+	CodeInvalidQuery   = -32003
+)
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Request is a JSON-RPC request. Requests without an ID are notifications
+// and don't get a response.
+type Request struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response.
+type Response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is sent by the server while a request is handled.
+type Notification struct {
+	Version string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+var nullID = json.RawMessage("null")
+
+// Server handles JSON-RPC requests using the storage and notestore.
+// Requests are handled one at a time in the order they are read.
+type Server struct {
+	db  clinote.Storager
+	ns  clinote.NotestoreClient
+	enc *json.Encoder
+}
+
+// NewServer returns a server using the storage and notestore.
+func NewServer(db clinote.Storager, ns clinote.NotestoreClient) *Server {
+	return &Server{db: db, ns: ns}
+}
+
+// Serve reads requests from r and writes the responses to w until r is
+// closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.enc = json.NewEncoder(w)
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if e := s.handleMessage(line); e != nil {
+				return e
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleMessage handles a single request or a batch of requests.
+func (s *Server) handleMessage(msg []byte) error {
+	msg = bytes.TrimSpace(msg)
+	if msg[0] != '[' {
+		if resp := s.handleRaw(msg); resp != nil {
+			return s.enc.Encode(resp)
+		}
+		return nil
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(msg, &batch); err != nil {
+		return s.enc.Encode(errorResponse(nullID, &Error{Code: CodeParseError, Message: "parse error"}))
+	}
+	if len(batch) == 0 {
+		return s.enc.Encode(errorResponse(nullID, &Error{Code: CodeInvalidRequest, Message: "empty batch"}))
+	}
+	var resps []*Response
+	for _, raw := range batch {
+		if resp := s.handleRaw(raw); resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+	if len(resps) == 0 {
+		return nil
+	}
+	return s.enc.Encode(resps)
+}
+
+func (s *Server) handleRaw(raw []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return errorResponse(nullID, &Error{Code: CodeParseError, Message: "parse error"})
+		}
+		return errorResponse(nullID, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+	}
+	if req.Version != Version || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = nullID
+		}
+		return errorResponse(id, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+	}
+	result, err := s.call(&req)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, rpcError(err))
+	}
+	return &Response{Version: Version, ID: req.ID, Result: result}
+}
+
+func (s *Server) call(req *Request) (interface{}, error) {
+	m, ok := methods[req.Method]
+	if !ok {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+	return m.handler(s, req)
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params interface{}) error {
+	return s.enc.Encode(&Notification{Version: Version, Method: method, Params: params})
+}
+
+func errorResponse(id json.RawMessage, e *Error) *Response {
+	return &Response{Version: Version, ID: id, Error: e}
+}
+
+// rpcError converts errors from the clinote package to JSON-RPC errors.
+func rpcError(err error) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case *clinote.AmbiguousNoteError:
+		return &Error{Code: CodeAmbiguous, Message: e.Error()}
+	case *clinote.InvalidQueryError:
+		return &Error{Code: CodeInvalidQuery, Message: e.Error()}
+	}
+	switch err {
+	case clinote.ErrNoNoteFound, clinote.ErrNoNotebookFound, clinote.ErrNoNotebookCached,
+		clinote.ErrNoSavedSearchFound:
+		return &Error{Code: CodeNotFound, Message: err.Error()}
+	case clinote.ErrUnbalancedQuotes, clinote.ErrInvalidTodo:
+		return &Error{Code: CodeInvalidQuery, Message: err.Error()}
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
+
+// invalidParams returns an invalid params error with the message.
+func invalidParams(msg string) error {
+	return &Error{Code: CodeInvalidParams, Message: "invalid params: " + msg}
+}
+
+// decodeParams decodes the request's parameters into v. Unknown fields
+// are rejected so typos don't go unnoticed.
+func decodeParams(req *Request, v interface{}) error {
+	if len(req.Params) == 0 || string(req.Params) == "null" {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(req.Params))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return invalidParams(strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+var errNoRef = errors.New("ref is required")
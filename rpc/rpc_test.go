@@ -0,0 +1,286 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	guidNotebook = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	guidNew      = testutil.NewNoteGUID
+)
+
+// newFakeNS returns a notestore with numbered notes.
+func newFakeNS(count int) *testutil.Notestore {
+	ns := testutil.NewNotestore(&clinote.Notebook{GUID: guidNotebook, Name: "Notes", Default: true})
+	for i := 0; i < count; i++ {
+		ns.AddNote(&clinote.Note{
+			GUID:     fmt.Sprintf("%08d-0000-0000-0000-000000000000", i),
+			Title:    fmt.Sprintf("Note %d", i),
+			Notebook: &clinote.Notebook{GUID: guidNotebook},
+		}, fmt.Sprintf("<p>Content <i>%d</i></p>", i))
+	}
+	return ns
+}
+
+// message is a response or notification read from the server.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+func serve(t *testing.T, ns *testutil.Notestore, input ...string) []string {
+	db, _, cleanup := testutil.OpenDB(t)
+	defer cleanup()
+	var out bytes.Buffer
+	err := NewServer(db, ns).Serve(strings.NewReader(strings.Join(input, "\n")), &out)
+	assert.NoError(t, err)
+	var lines []string
+	s := bufio.NewScanner(&out)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines
+}
+
+func decode(t *testing.T, line string) *message {
+	m := new(message)
+	if err := json.Unmarshal([]byte(line), m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestDiscover(t *testing.T) {
+	lines := serve(t, newFakeNS(0), `{"jsonrpc": "2.0", "id": 1, "method": "rpc.discover"}`)
+	assert.Len(t, lines, 1)
+	m := decode(t, lines[0])
+	assert.Equal(t, "1", string(m.ID))
+	var d Discovery
+	assert.NoError(t, json.Unmarshal(m.Result, &d))
+	var names []string
+	for _, ms := range d.Methods {
+		names = append(names, ms.Name)
+		assert.NotEmpty(t, ms.Description, ms.Name)
+		assert.True(t, json.Valid(ms.Params), ms.Name)
+	}
+	assert.Equal(t, []string{"initialize", "notebooks.list", "notes.create", "notes.find", "notes.get", "notes.save",
+		"notifications/initialized", "ping", "rpc.discover", "tools/call", "tools/list"}, names)
+	assert.Equal(t, PageNotification, d.Notifications[0].Name)
+}
+
+func TestFindNotes(t *testing.T) {
+	t.Run("page", func(t *testing.T) {
+		lines := serve(t, newFakeNS(5), `{"jsonrpc": "2.0", "id": "a", "method": "notes.find", "params": {"offset": 2, "count": 2}}`)
+		assert.Len(t, lines, 1)
+		var r findResult
+		assert.NoError(t, json.Unmarshal(decode(t, lines[0]).Result, &r))
+		assert.Len(t, r.Notes, 2)
+		assert.Equal(t, "Note 2", r.Notes[0].Title)
+		assert.Equal(t, "Notes", r.Notes[0].Notebook)
+		assert.True(t, r.More)
+	})
+	t.Run("stream", func(t *testing.T) {
+		ns := newFakeNS(5)
+		lines := serve(t, ns, `{"jsonrpc": "2.0", "id": 7, "method": "notes.find", "params": {"count": 2, "stream": true}}`)
+		assert.Len(t, lines, 4)
+		for i, line := range lines[:3] {
+			m := decode(t, line)
+			assert.Equal(t, PageNotification, m.Method)
+			var p page
+			assert.NoError(t, json.Unmarshal(m.Params, &p))
+			assert.Equal(t, "7", string(p.ID))
+			assert.Equal(t, i*2, p.Offset)
+			assert.Equal(t, fmt.Sprintf("Note %d", i*2), p.Notes[0].Title)
+		}
+		var r findResult
+		assert.NoError(t, json.Unmarshal(decode(t, lines[3]).Result, &r))
+		assert.Equal(t, 5, r.Total)
+		assert.False(t, r.More)
+		assert.Equal(t, 3, len(ns.Filters))
+	})
+	t.Run("stream with limit", func(t *testing.T) {
+		lines := serve(t, newFakeNS(10), `{"jsonrpc": "2.0", "id": 1, "method": "notes.find", "params": {"count": 2, "stream": true, "limit": 3}}`)
+		assert.Len(t, lines, 3)
+		var p page
+		assert.NoError(t, json.Unmarshal(decode(t, lines[1]).Params, &p))
+		assert.Len(t, p.Notes, 1)
+		var r findResult
+		assert.NoError(t, json.Unmarshal(decode(t, lines[2]).Result, &r))
+		assert.Equal(t, 3, r.Total)
+		assert.True(t, r.More)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		lines := serve(t, newFakeNS(1),
+			`{"jsonrpc": "2.0", "id": 1, "method": "notes.find", "params": {"count": 500}}`,
+			`{"jsonrpc": "2.0", "id": 2, "method": "notes.find", "params": {"search": "\"open"}}`,
+			`{"jsonrpc": "2.0", "id": 3, "method": "notes.find", "params": {"notebook": "Missing"}}`,
+			`{"jsonrpc": "2.0", "id": 4, "method": "notes.find", "params": {"typo": 1}}`)
+		assert.Len(t, lines, 4)
+		for i, code := range []int{CodeInvalidParams, CodeInvalidQuery, CodeNotFound, CodeInvalidParams} {
+			assert.Equal(t, code, decode(t, lines[i]).Error.Code, lines[i])
+		}
+	})
+}
+
+func TestGetNote(t *testing.T) {
+	ns := newFakeNS(2)
+	lines := serve(t, ns,
+		`{"jsonrpc": "2.0", "id": 1, "method": "notes.get", "params": {"ref": "guid:`+ns.Notes[1].GUID+`"}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "notes.get", "params": {"ref": "guid:`+ns.Notes[0].GUID+`", "format": "both"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "notes.get", "params": {"ref": "guid:`+guidNew+`"}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "notes.get", "params": {}}`)
+	assert.Len(t, lines, 4)
+	var n struct{ Title, Markdown, ENML string }
+	assert.NoError(t, json.Unmarshal(decode(t, lines[0]).Result, &n))
+	assert.Equal(t, "Note 1", n.Title)
+	assert.Equal(t, "Content _1_", strings.TrimSpace(n.Markdown))
+	assert.Empty(t, n.ENML)
+	assert.NoError(t, json.Unmarshal(decode(t, lines[1]).Result, &n))
+	assert.Equal(t, "<p>Content <i>0</i></p>", n.ENML)
+	assert.Equal(t, CodeNotFound, decode(t, lines[2]).Error.Code)
+	assert.Equal(t, CodeInvalidParams, decode(t, lines[3]).Error.Code)
+}
+
+func TestCreateAndSaveNote(t *testing.T) {
+	ns := newFakeNS(1)
+	guid := ns.Notes[0].GUID
+	lines := serve(t, ns,
+		`{"jsonrpc": "2.0", "id": 1, "method": "notes.create", "params": {"title": "New", "notebook": "Notes", "markdown": "**Hi**"}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "notes.save", "params": {"ref": "guid:`+guid+`", "enml": "<div>Raw</div>"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "notes.save", "params": {"ref": "guid:`+guid+`"}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "notes.create", "params": {"markdown": "no title"}}`)
+	assert.Len(t, lines, 4)
+	var n struct{ GUID, Notebook string }
+	assert.NoError(t, json.Unmarshal(decode(t, lines[0]).Result, &n))
+	assert.Equal(t, guidNew, n.GUID)
+	assert.Equal(t, "Notes", n.Notebook)
+	assert.Contains(t, ns.Content[guidNew], "<strong>Hi</strong>")
+	assert.Nil(t, decode(t, lines[1]).Error)
+	assert.Equal(t, clinote.XMLHeader+"<en-note><div>Raw</div></en-note>", ns.Content[guid])
+	assert.Equal(t, CodeInvalidParams, decode(t, lines[2]).Error.Code)
+	assert.Equal(t, CodeInvalidParams, decode(t, lines[3]).Error.Code)
+}
+
+func TestListNotebooks(t *testing.T) {
+	lines := serve(t, newFakeNS(0), `{"jsonrpc": "2.0", "id": 1, "method": "notebooks.list", "params": {"sync": true}}`)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":[{"guid":"`+guidNotebook+`","name":"Notes","default":true}]}`, lines[0])
+}
+
+func TestProtocolErrors(t *testing.T) {
+	lines := serve(t, newFakeNS(0),
+		`{not json`,
+		`{"jsonrpc": "1.0", "id": 1, "method": "notes.find"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "notes.remove"}`,
+		`{"jsonrpc": "2.0", "method": "notebooks.list"}`,
+		`"text"`,
+		``,
+		`[]`)
+	assert.Len(t, lines, 5)
+	expected := []struct {
+		id   string
+		code int
+	}{
+		{"null", CodeParseError},
+		{"1", CodeInvalidRequest},
+		{"2", CodeMethodNotFound},
+		{"null", CodeInvalidRequest},
+		{"null", CodeInvalidRequest},
+	}
+	for i, e := range expected {
+		m := decode(t, lines[i])
+		assert.Equal(t, e.id, string(m.ID), lines[i])
+		assert.Equal(t, e.code, m.Error.Code, lines[i])
+	}
+}
+
+func TestBatch(t *testing.T) {
+	lines := serve(t, newFakeNS(0), `[{"jsonrpc": "2.0", "id": 1, "method": "notebooks.list"}, {"jsonrpc": "2.0", "method": "notebooks.list"}, {"jsonrpc": "2.0", "id": 2, "method": "nope"}]`)
+	assert.Len(t, lines, 1)
+	var resps []*message
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &resps))
+	assert.Len(t, resps, 2)
+	assert.Nil(t, resps[0].Error)
+	assert.Equal(t, CodeMethodNotFound, resps[1].Error.Code)
+}
+
+func TestMCP(t *testing.T) {
+	ns := newFakeNS(3)
+	lines := serve(t, ns,
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2024-11-05", "capabilities": {}, "clientInfo": {"name": "test", "title": "Test", "version": "1.0"}}}`,
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list", "params": {"_meta": {}}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "notes_find", "arguments": {"count": 2}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "notes_get", "arguments": {"ref": "guid:`+guidNew+`"}}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "tools/call", "params": {"name": "notes_find", "arguments": {"stream": true}}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "tools/call", "params": {"name": "notes_remove", "arguments": {}}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "initialize", "params": {"protocolVersion": "1999-01-01"}}`,
+		`{"jsonrpc": "2.0", "id": 8, "method": "ping"}`)
+	assert.Len(t, lines, 8)
+
+	var init InitializeResult
+	assert.NoError(t, json.Unmarshal(decode(t, lines[0]).Result, &init))
+	assert.Equal(t, "2024-11-05", init.ProtocolVersion, "The client's version should be used")
+	assert.Equal(t, ServerName, init.ServerInfo.Name)
+	assert.Contains(t, init.Capabilities, "tools")
+
+	var l ToolList
+	assert.NoError(t, json.Unmarshal(decode(t, lines[1]).Result, &l))
+	var names []string
+	for _, tool := range l.Tools {
+		names = append(names, tool.Name)
+		assert.NotEmpty(t, tool.Description, tool.Name)
+		assert.True(t, json.Valid(tool.InputSchema), tool.Name)
+	}
+	assert.Equal(t, []string{"notebooks_list", "notes_create", "notes_find", "notes_get", "notes_save"}, names)
+	assert.NotContains(t, string(l.Tools[2].InputSchema), "stream", "notes_find should not stream")
+
+	var r ToolResult
+	assert.NoError(t, json.Unmarshal(decode(t, lines[2]).Result, &r))
+	assert.False(t, r.IsError)
+	var found findResult
+	assert.NoError(t, json.Unmarshal([]byte(r.Content[0].Text), &found))
+	assert.Len(t, found.Notes, 2)
+	assert.True(t, found.More)
+
+	for _, line := range lines[3:5] {
+		r = ToolResult{}
+		assert.NoError(t, json.Unmarshal(decode(t, line).Result, &r))
+		assert.True(t, r.IsError, "Tool errors should be in the result: "+line)
+	}
+	assert.Equal(t, CodeInvalidParams, decode(t, lines[5]).Error.Code, "Unknown tools should be a protocol error")
+
+	init = InitializeResult{}
+	assert.NoError(t, json.Unmarshal(decode(t, lines[6]).Result, &init))
+	assert.Equal(t, MCPVersion, init.ProtocolVersion, "The latest version should be offered")
+	assert.Equal(t, `{"jsonrpc":"2.0","id":8,"result":{}}`, lines[7])
+}
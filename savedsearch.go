@@ -0,0 +1,185 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// SearchAliasPrefix is the prefix used to reference an alias or a saved
+// search in place of a search query, e.g. @work-todos.
+const SearchAliasPrefix = "@"
+
+var (
+	// ErrNoSavedSearchFound is returned if no saved search or alias matches the name.
+	ErrNoSavedSearchFound = errors.New("no saved search found")
+	// ErrSavedSearchExists is returned if a saved search with the name already exists.
+	ErrSavedSearchExists = errors.New("a saved search with the name already exists")
+	// ErrInvalidSearchName is returned if the name of a saved search or an alias is empty.
+	ErrInvalidSearchName = errors.New("invalid search name")
+	// ErrEmptyQuery is returned if the search query is empty.
+	ErrEmptyQuery = errors.New("search query can't be empty")
+)
+
+// SavedSearch is a search query saved in the user's account.
+type SavedSearch struct {
+	// GUID is the unique identifier.
+	GUID string
+	// Name is the name of the search.
+	Name string
+	// Query is the search query.
+	Query string
+}
+
+// GetSavedSearches returns the user's saved searches sorted by name.
+func GetSavedSearches(ns NotestoreClient) ([]*SavedSearch, error) {
+	searches, err := ns.GetSavedSearches()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		return strings.ToLower(searches[i].Name) < strings.ToLower(searches[j].Name)
+	})
+	return searches, nil
+}
+
+// GetSavedSearch returns the saved search with the name. The name is first
+// matched exactly and then case insensitively.
+func GetSavedSearch(ns NotestoreClient, name string) (*SavedSearch, error) {
+	searches, err := ns.GetSavedSearches()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range searches {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	for _, s := range searches {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+	}
+	return nil, ErrNoSavedSearchFound
+}
+
+// NewSavedSearch saves the query as a saved search with the name.
+func NewSavedSearch(ns NotestoreClient, name, query string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrInvalidSearchName
+	}
+	if strings.TrimSpace(query) == "" {
+		return ErrEmptyQuery
+	}
+	if _, err := GetSavedSearch(ns, name); err == nil {
+		return ErrSavedSearchExists
+	} else if err != ErrNoSavedSearchFound {
+		return err
+	}
+	return ns.CreateSavedSearch(&SavedSearch{Name: name, Query: query})
+}
+
+// DeleteSavedSearch removes the saved search with the name.
+func DeleteSavedSearch(ns NotestoreClient, name string) error {
+	s, err := GetSavedSearch(ns, name)
+	if err != nil {
+		return err
+	}
+	return ns.DeleteSavedSearch(s.GUID)
+}
+
+// RunSavedSearch returns the notes matching the query of the saved search,
+// sorted by the modified time.
+func RunSavedSearch(ns NotestoreClient, name string, count int) ([]*Note, error) {
+	s, err := GetSavedSearch(ns, name)
+	if err != nil {
+		return nil, err
+	}
+	filter := &NoteFilter{Words: s.Query, Order: NoteFilterOrderUpdated}
+	return ns.FindNotes(filter, 0, count)
+}
+
+// ExpandSearch returns the query for the search. If the search starts with
+// @, the rest is the name of a local alias or a saved search and its query
+// is returned. Local aliases take precedence over saved searches. Other
+// searches are returned as they are. If ns is nil, only local aliases are
+// expanded.
+func ExpandSearch(db Storager, ns NotestoreClient, search string) (string, error) {
+	if !strings.HasPrefix(search, SearchAliasPrefix) {
+		return search, nil
+	}
+	name := strings.TrimPrefix(search, SearchAliasPrefix)
+	settings, err := db.GetSettings()
+	if err != nil {
+		return "", err
+	}
+	if query, ok := settings.SearchAliases[name]; ok {
+		return query, nil
+	}
+	if ns == nil {
+		return "", ErrNoSavedSearchFound
+	}
+	s, err := GetSavedSearch(ns, name)
+	if err != nil {
+		return "", err
+	}
+	return s.Query, nil
+}
+
+// SetSearchAlias saves the query as a local alias.
+func SetSearchAlias(db Storager, name, query string) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " \t") {
+		return ErrInvalidSearchName
+	}
+	if strings.TrimSpace(query) == "" {
+		return ErrEmptyQuery
+	}
+	settings, err := db.GetSettings()
+	if err != nil {
+		return err
+	}
+	if settings.SearchAliases == nil {
+		settings.SearchAliases = make(map[string]string)
+	}
+	settings.SearchAliases[name] = query
+	return db.StoreSettings(settings)
+}
+
+// RemoveSearchAlias removes the local alias.
+func RemoveSearchAlias(db Storager, name string) error {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return err
+	}
+	if _, ok := settings.SearchAliases[name]; !ok {
+		return ErrNoSavedSearchFound
+	}
+	delete(settings.SearchAliases, name)
+	return db.StoreSettings(settings)
+}
+
+// GetSearchAliases returns the local aliases.
+func GetSearchAliases(db Storager) (map[string]string, error) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	return settings.SearchAliases, nil
+}
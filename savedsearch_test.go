@@ -0,0 +1,102 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedSearches(t *testing.T) {
+	assert := assert.New(t)
+	searches := []*SavedSearch{
+		&SavedSearch{GUID: "2", Name: "todos", Query: "tag:todo"},
+		&SavedSearch{GUID: "1", Name: "Inbox", Query: "notebook:Inbox"},
+	}
+	var created *SavedSearch
+	var deleted string
+	var filter *NoteFilter
+	ns := &mockNS{
+		savedSearches: func() ([]*SavedSearch, error) { return searches, nil },
+		createSearch:  func(s *SavedSearch) error { created = s; return nil },
+		deleteSearch:  func(guid string) error { deleted = guid; return nil },
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			filter = f
+			return []*Note{}, nil
+		},
+	}
+
+	list, err := GetSavedSearches(ns)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("Inbox", list[0].Name, "Searches should be sorted by name")
+
+	s, err := GetSavedSearch(ns, "TODOS")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("2", s.GUID, "Name should match case insensitively")
+	_, err = GetSavedSearch(ns, "missing")
+	assert.Equal(ErrNoSavedSearchFound, err, "Wrong error")
+
+	assert.NoError(NewSavedSearch(ns, "work", "notebook:Work"))
+	assert.Equal(&SavedSearch{Name: "work", Query: "notebook:Work"}, created)
+	assert.Equal(ErrSavedSearchExists, NewSavedSearch(ns, "todos", "x"))
+	assert.Equal(ErrEmptyQuery, NewSavedSearch(ns, "empty", " "))
+
+	assert.NoError(DeleteSavedSearch(ns, "Inbox"))
+	assert.Equal("1", deleted, "Wrong search deleted")
+
+	_, err = RunSavedSearch(ns, "todos", 10)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("tag:todo", filter.Words, "Saved query should be used")
+}
+
+func TestSearchAliases(t *testing.T) {
+	assert := assert.New(t)
+	settings := new(Settings)
+	store := &mockStore{
+		getSettings:   func() (*Settings, error) { return settings, nil },
+		storeSettings: func(s *Settings) error { settings = s; return nil },
+	}
+	ns := &mockNS{
+		savedSearches: func() ([]*SavedSearch, error) {
+			return []*SavedSearch{&SavedSearch{Name: "todos", Query: "tag:todo"}}, nil
+		},
+	}
+
+	assert.NoError(SetSearchAlias(store, "work-todos", "notebook:Work tag:todo"))
+	assert.Equal(ErrInvalidSearchName, SetSearchAlias(store, "work todos", "x"))
+
+	tests := map[string]string{
+		"plain words": "plain words",
+		"@work-todos": "notebook:Work tag:todo",
+		"@todos":      "tag:todo",
+	}
+	for search, expected := range tests {
+		query, err := ExpandSearch(store, ns, search)
+		assert.NoError(err, "Should not return an error for "+search)
+		assert.Equal(expected, query, "Wrong query for "+search)
+	}
+	_, err := ExpandSearch(store, ns, "@missing")
+	assert.Equal(ErrNoSavedSearchFound, err, "Wrong error")
+
+	assert.NoError(RemoveSearchAlias(store, "work-todos"))
+	assert.Equal(ErrNoSavedSearchFound, RemoveSearchAlias(store, "work-todos"))
+	aliases, err := GetSearchAliases(store)
+	assert.NoError(err, "Should not return an error")
+	assert.Empty(aliases, "Alias should be removed")
+}
@@ -0,0 +1,418 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+// Package server provides a JSON HTTP API for the notes in an Evernote
+// account. The API is backed by the same storage and notestore as the
+// command line client.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/jsonapi"
+)
+
+const (
+	// APIPrefix is the path prefix of all API endpoints.
+	APIPrefix = "/v1"
+	// DefaultCount is the number of notes returned by a search if no
+	// count is given.
+	DefaultCount = 20
+	// MaxCount is the maximum number of notes returned by a search.
+	MaxCount = 100
+	// maxBodySize is the maximum size of a request body.
+	maxBodySize = 10 << 20
+)
+
+var (
+	// ErrNoToken is returned if the server is created without a token.
+	ErrNoToken = errors.New("an API token is required")
+	// errBadRequest is returned for request bodies that can't be decoded.
+	errBadRequest = errors.New("invalid request body")
+	// errNoTitle is returned if a new note doesn't have a title.
+	errNoTitle = errors.New("title is required")
+	// errBothContents is returned if both markdown and ENML are given.
+	errBothContents = errors.New("only one of markdown and enml can be given")
+)
+
+// Server is an http.Handler serving the API. Requests are handled one
+// at a time because the notestore client isn't safe for concurrent use.
+type Server struct {
+	db    clinote.Storager
+	ns    clinote.NotestoreClient
+	token string
+	mux   *http.ServeMux
+	mu    sync.Mutex
+}
+
+// New returns a server using the storage and notestore. Requests must
+// have the token in an "Authorization: Bearer <token>" header.
+func New(db clinote.Storager, ns clinote.NotestoreClient, token string) (*Server, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+	s := &Server{db: db, ns: ns, token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc(APIPrefix+"/notes", s.handleNotes)
+	s.mux.HandleFunc(APIPrefix+"/notes/", s.handleNote)
+	s.mux.HandleFunc(APIPrefix+"/notebooks", s.handleNotebooks)
+	return s, nil
+}
+
+// ServeHTTP authenticates the request and passes it to the endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="clinote"`)
+		writeError(w, http.StatusUnauthorized, errors.New("invalid or missing API token"))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	token := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// NoteRequest is the body used to create or update a note. When
+// updating, only the fields that are set are changed.
+type NoteRequest struct {
+	Title    *string  `json:"title"`
+	Notebook *string  `json:"notebook"`
+	Tags     []string `json:"tags"`
+	Markdown *string  `json:"markdown"`
+	ENML     *string  `json:"enml"`
+}
+
+// NotebookRequest is the body used to create a notebook.
+type NotebookRequest struct {
+	Name    string `json:"name"`
+	Stack   string `json:"stack"`
+	Default bool   `json:"default"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// handleNotes searches for notes and creates new notes.
+func (s *Server) handleNotes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listNotes(w, r)
+	case http.MethodPost:
+		s.createNote(w, r)
+	default:
+		methodNotAllowed(w, "GET, POST")
+	}
+}
+
+// handleNote serves a single note, /notes/{guid}. Only full GUIDs are
+// accepted so a request never acts on a note matched by a GUID prefix.
+func (s *Server) handleNote(w http.ResponseWriter, r *http.Request) {
+	guid := strings.TrimPrefix(r.URL.Path, APIPrefix+"/notes/")
+	if !clinote.IsGUID(guid) {
+		writeError(w, http.StatusNotFound, clinote.ErrNoNoteFound)
+		return
+	}
+	ref := clinote.GUIDPrefix + guid
+	switch r.Method {
+	case http.MethodGet:
+		s.getNote(w, r, ref)
+	case http.MethodPatch, http.MethodPut:
+		s.updateNote(w, r, ref)
+	case http.MethodDelete:
+		if err := clinote.DeleteNote(s.db, s.ns, ref, ""); err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, "GET, PATCH, PUT, DELETE")
+	}
+}
+
+func (s *Server) handleNotebooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		bs, err := clinote.GetNotebooks(s.db, s.ns, r.URL.Query().Get("sync") == "true")
+		if err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		a := make([]*jsonapi.Notebook, len(bs))
+		for i, b := range bs {
+			a[i] = jsonapi.NewNotebook(b)
+		}
+		writeJSON(w, http.StatusOK, a)
+	case http.MethodPost:
+		var req NotebookRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			writeError(w, http.StatusBadRequest, errors.New("name is required"))
+			return
+		}
+		b := &clinote.Notebook{Name: req.Name, Stack: req.Stack}
+		if err := clinote.CreateNotebook(s.ns, b, req.Default); err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		// Refresh the cache so the new notebook can be used right away.
+		clinote.GetNotebooks(s.db, s.ns, true)
+		b.Default = req.Default
+		writeJSON(w, http.StatusCreated, jsonapi.NewNotebook(b))
+	default:
+		methodNotAllowed(w, "GET, POST")
+	}
+}
+
+// listNotes returns the notes matching the search and notebook query
+// parameters. The search can use the Evernote search grammar, aliases
+// and saved searches.
+func (s *Server) listNotes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	offset, err := intParam(q.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("invalid offset"))
+		return
+	}
+	count, err := intParam(q.Get("count"), DefaultCount)
+	if err != nil || count < 1 || count > MaxCount {
+		writeError(w, http.StatusBadRequest, errors.New("count must be between 1 and "+strconv.Itoa(MaxCount)))
+		return
+	}
+	filter := &clinote.NoteFilter{Order: clinote.NoteFilterOrderUpdated}
+	if search := q.Get("search"); search != "" {
+		if filter.Words, err = clinote.ExpandSearch(s.db, s.ns, search); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if _, err = clinote.ParseQuery(filter.Words); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if name := q.Get("notebook"); name != "" {
+		b, err := clinote.FindNotebook(s.db, s.ns, name)
+		if err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		filter.NotebookGUID = b.GUID
+	}
+	notes, err := clinote.FindNotes(s.ns, filter, offset, count)
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	names := s.notebookNames()
+	a := make([]*jsonapi.Note, len(notes))
+	for i, n := range notes {
+		a[i] = jsonapi.NewNote(n, names)
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+// getNote returns the note with its content. The format query parameter
+// can be markdown, enml or both. The default is markdown.
+func (s *Server) getNote(w http.ResponseWriter, r *http.Request, ref string) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "markdown" && format != "enml" && format != "both" {
+		writeError(w, http.StatusBadRequest, errors.New("format must be markdown, enml or both"))
+		return
+	}
+	n, err := clinote.GetNoteWithContent(s.db, s.ns, ref)
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	note := jsonapi.NewNote(n, s.notebookNames())
+	if format != "enml" {
+		note.Markdown = n.MD
+	}
+	if format == "enml" || format == "both" {
+		note.ENML = n.Body
+	}
+	writeJSON(w, http.StatusOK, note)
+}
+
+func (s *Server) createNote(w http.ResponseWriter, r *http.Request) {
+	var req NoteRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Title == nil || strings.TrimSpace(*req.Title) == "" {
+		writeError(w, http.StatusBadRequest, errNoTitle)
+		return
+	}
+	if req.Markdown != nil && req.ENML != nil {
+		writeError(w, http.StatusBadRequest, errBothContents)
+		return
+	}
+	n := &clinote.Note{Title: *req.Title, Tags: req.Tags}
+	if req.Notebook != nil {
+		b, err := clinote.FindNotebook(s.db, s.ns, *req.Notebook)
+		if err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		n.Notebook = b
+	}
+	raw := req.ENML != nil
+	if raw {
+		n.Body = *req.ENML
+	} else if req.Markdown != nil {
+		n.MD = *req.Markdown
+	}
+	if err := clinote.SaveNewNote(s.ns, n, raw); err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, jsonapi.NewNote(n, s.notebookNames()))
+}
+
+// updateNote changes the fields set in the request. Moving a note is done
+// by setting the notebook.
+func (s *Server) updateNote(w http.ResponseWriter, r *http.Request, ref string) {
+	var req NoteRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		writeError(w, http.StatusBadRequest, errNoTitle)
+		return
+	}
+	if req.Markdown != nil && req.ENML != nil {
+		writeError(w, http.StatusBadRequest, errBothContents)
+		return
+	}
+	if req.Tags != nil {
+		writeError(w, http.StatusBadRequest, errors.New("tags can't be changed, use clinote note bulk tag"))
+		return
+	}
+	var err error
+	if req.Markdown != nil || req.ENML != nil {
+		err = s.updateContent(ref, &req)
+	} else {
+		if req.Title != nil {
+			err = clinote.ChangeTitle(s.db, s.ns, ref, *req.Title)
+		}
+		if err == nil && req.Notebook != nil {
+			err = clinote.MoveNote(s.db, s.ns, ref, *req.Notebook)
+		}
+	}
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	n, err := clinote.GetNote(s.db, s.ns, ref, "")
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, jsonapi.NewNote(n, s.notebookNames()))
+}
+
+func (s *Server) updateContent(ref string, req *NoteRequest) error {
+	n, err := clinote.GetNoteWithContent(s.db, s.ns, ref)
+	if err != nil {
+		return err
+	}
+	if req.Title != nil {
+		n.Title = *req.Title
+	}
+	if req.Notebook != nil {
+		if n.Notebook, err = clinote.FindNotebook(s.db, s.ns, *req.Notebook); err != nil {
+			return err
+		}
+	}
+	opts := clinote.DefaultNoteOption
+	if req.ENML != nil {
+		n.Body = *req.ENML
+		opts |= clinote.RawNote
+	} else {
+		n.MD = *req.Markdown
+	}
+	return clinote.SaveChanges(s.ns, n, opts)
+}
+
+func (s *Server) notebookNames() map[string]string {
+	return jsonapi.NotebookNames(s.db, s.ns)
+}
+
+// statusCode returns the HTTP status for an error from the clinote package.
+func statusCode(err error) int {
+	switch err.(type) {
+	case *clinote.AmbiguousNoteError:
+		return http.StatusConflict
+	case *clinote.InvalidQueryError:
+		return http.StatusBadRequest
+	}
+	switch err {
+	case clinote.ErrNoNoteFound, clinote.ErrNoNotebookFound, clinote.ErrNoNotebookCached:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func intParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	d := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return errBadRequest
+	}
+	return nil
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,298 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/internal/testutil"
+	"github.com/TcM1911/clinote/jsonapi"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testToken    = "secret"
+	guidNote1    = "11111111-1111-1111-1111-111111111111"
+	guidNote2    = "22222222-2222-2222-2222-222222222222"
+	guidNew      = testutil.NewNoteGUID
+	guidNotebook = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	guidArchive  = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+)
+
+func newFakeNS() *testutil.Notestore {
+	ns := testutil.NewNotestore(
+		&clinote.Notebook{GUID: guidNotebook, Name: "Notes", Default: true},
+		&clinote.Notebook{GUID: guidArchive, Name: "Archive", Stack: "Old"})
+	ns.AddNote(&clinote.Note{GUID: guidNote1, Title: "Shopping", Notebook: &clinote.Notebook{GUID: guidNotebook}}, "<p>Milk <b>and</b> eggs</p>")
+	ns.AddNote(&clinote.Note{GUID: guidNote2, Title: "Ideas", Notebook: &clinote.Notebook{GUID: guidArchive}}, "<p>Nothing yet</p>")
+	return ns
+}
+
+func setupServer(t *testing.T) (*httptest.Server, *testutil.Notestore, func()) {
+	db, _, cleanup := testutil.OpenDB(t)
+	ns := newFakeNS()
+	s, err := New(db, ns, testToken)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	return ts, ns, func() {
+		ts.Close()
+		cleanup()
+	}
+}
+
+func doRequest(t *testing.T, ts *httptest.Server, method, path, body string, v interface{}) *http.Response {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, nil, "")
+	assert.Equal(t, ErrNoToken, err)
+}
+
+func TestAuthorization(t *testing.T) {
+	ts, _, cleanup := setupServer(t)
+	defer cleanup()
+	for _, auth := range []string{"", "Bearer wrong", "secret", "Basic secret"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/notebooks", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e errorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, auth)
+		assert.NotEmpty(t, e.Error)
+	}
+}
+
+func TestListNotes(t *testing.T) {
+	ts, ns, cleanup := setupServer(t)
+	defer cleanup()
+
+	t.Run("all", func(t *testing.T) {
+		var notes []*jsonapi.Note
+		resp := doRequest(t, ts, http.MethodGet, "/v1/notes", "", &notes)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, notes, 2)
+		assert.Equal(t, "Shopping", notes[0].Title)
+		assert.Equal(t, "Notes", notes[0].Notebook)
+		assert.Equal(t, "Archive", notes[1].Notebook)
+	})
+	t.Run("search in notebook", func(t *testing.T) {
+		var notes []*jsonapi.Note
+		resp := doRequest(t, ts, http.MethodGet, "/v1/notes?search=intitle:idea&notebook=Archive", "", &notes)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, notes, 1)
+		assert.Equal(t, guidNote2, notes[0].GUID)
+		assert.Equal(t, "intitle:idea", ns.LastFilter().Words)
+		assert.Equal(t, guidArchive, ns.LastFilter().NotebookGUID)
+	})
+	t.Run("bad requests", func(t *testing.T) {
+		for path, status := range map[string]int{
+			"/v1/notes?count=0":            http.StatusBadRequest,
+			"/v1/notes?count=1000":         http.StatusBadRequest,
+			"/v1/notes?offset=-1":          http.StatusBadRequest,
+			"/v1/notes?search=%22unclosed": http.StatusBadRequest,
+			"/v1/notes?notebook=Missing":   http.StatusNotFound,
+		} {
+			var e errorResponse
+			resp := doRequest(t, ts, http.MethodGet, path, "", &e)
+			assert.Equal(t, status, resp.StatusCode, path)
+			assert.NotEmpty(t, e.Error, path)
+		}
+	})
+}
+
+func TestGetNote(t *testing.T) {
+	ts, _, cleanup := setupServer(t)
+	defer cleanup()
+
+	t.Run("markdown", func(t *testing.T) {
+		var n jsonapi.Note
+		resp := doRequest(t, ts, http.MethodGet, "/v1/notes/"+guidNote1, "", &n)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "Shopping", n.Title)
+		assert.Equal(t, "Milk **and** eggs", strings.TrimSpace(n.Markdown))
+		assert.Empty(t, n.ENML)
+	})
+	t.Run("enml", func(t *testing.T) {
+		var n jsonapi.Note
+		doRequest(t, ts, http.MethodGet, "/v1/notes/"+guidNote1+"?format=enml", "", &n)
+		assert.Equal(t, "<p>Milk <b>and</b> eggs</p>", n.ENML)
+		assert.Empty(t, n.Markdown)
+	})
+	t.Run("not found", func(t *testing.T) {
+		var e errorResponse
+		resp := doRequest(t, ts, http.MethodGet, "/v1/notes/"+guidNew, "", &e)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, clinote.ErrNoNoteFound.Error(), e.Error)
+	})
+	t.Run("bad format", func(t *testing.T) {
+		resp := doRequest(t, ts, http.MethodGet, "/v1/notes/"+guidNote1+"?format=pdf", "", nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestCreateNote(t *testing.T) {
+	ts, ns, cleanup := setupServer(t)
+	defer cleanup()
+
+	t.Run("markdown", func(t *testing.T) {
+		var n jsonapi.Note
+		body := `{"title": "Todo", "notebook": "Archive", "markdown": "# Today"}`
+		resp := doRequest(t, ts, http.MethodPost, "/v1/notes", body, &n)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, guidNew, n.GUID)
+		assert.Equal(t, "Archive", n.Notebook)
+		assert.Equal(t, guidArchive, ns.Note(guidNew).Notebook.GUID)
+		assert.Contains(t, ns.Content[guidNew], "<h1>Today</h1>")
+	})
+	t.Run("enml", func(t *testing.T) {
+		body := `{"title": "Raw", "enml": "<div>Hi</div>"}`
+		resp := doRequest(t, ts, http.MethodPost, "/v1/notes", body, nil)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, clinote.XMLHeader+"<en-note><div>Hi</div></en-note>", ns.Content[guidNew])
+	})
+	t.Run("bad requests", func(t *testing.T) {
+		for body, status := range map[string]int{
+			`{"markdown": "no title"}`:                   http.StatusBadRequest,
+			`{"title": "a", "markdown": "", "enml": ""}`: http.StatusBadRequest,
+			`{"title": "a", "unknown": true}`:            http.StatusBadRequest,
+			`not json`:                                   http.StatusBadRequest,
+			`{"title": "a", "notebook": "Missing"}`:      http.StatusNotFound,
+		} {
+			resp := doRequest(t, ts, http.MethodPost, "/v1/notes", body, nil)
+			assert.Equal(t, status, resp.StatusCode, body)
+		}
+	})
+}
+
+func TestUpdateNote(t *testing.T) {
+	ts, ns, cleanup := setupServer(t)
+	defer cleanup()
+
+	t.Run("title and notebook", func(t *testing.T) {
+		var n jsonapi.Note
+		body := `{"title": "Groceries", "notebook": "Archive"}`
+		resp := doRequest(t, ts, http.MethodPatch, "/v1/notes/"+guidNote1, body, &n)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "Groceries", n.Title)
+		assert.Equal(t, "Archive", n.Notebook)
+		assert.Equal(t, guidArchive, ns.Note(guidNote1).Notebook.GUID)
+	})
+	t.Run("markdown", func(t *testing.T) {
+		body := `{"markdown": "Bread"}`
+		resp := doRequest(t, ts, http.MethodPatch, "/v1/notes/"+guidNote2, body, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, ns.Content[guidNote2], "<p>Bread</p>")
+		assert.Equal(t, "Ideas", ns.Note(guidNote2).Title)
+	})
+	t.Run("enml", func(t *testing.T) {
+		body := `{"enml": "<div>Raw</div>", "title": "Raw ideas"}`
+		resp := doRequest(t, ts, http.MethodPatch, "/v1/notes/"+guidNote2, body, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, clinote.XMLHeader+"<en-note><div>Raw</div></en-note>", ns.Content[guidNote2])
+		assert.Equal(t, "Raw ideas", ns.Note(guidNote2).Title)
+	})
+	t.Run("empty title", func(t *testing.T) {
+		resp := doRequest(t, ts, http.MethodPatch, "/v1/notes/"+guidNote2, `{"title": " "}`, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestDeleteNote(t *testing.T) {
+	ts, ns, cleanup := setupServer(t)
+	defer cleanup()
+
+	resp := doRequest(t, ts, http.MethodDelete, "/v1/notes/"+guidNote2, "", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, []string{guidNote2}, ns.Deleted)
+
+	resp = doRequest(t, ts, http.MethodDelete, "/v1/notes/"+guidNote2, "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestNoteGUIDPrefix(t *testing.T) {
+	ts, ns, cleanup := setupServer(t)
+	defer cleanup()
+
+	for _, method := range []string{http.MethodGet, http.MethodPatch, http.MethodDelete} {
+		resp := doRequest(t, ts, method, "/v1/notes/1", `{"title": "New"}`, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "A GUID prefix should not match for "+method)
+	}
+	assert.Empty(t, ns.Deleted, "No note should be deleted")
+}
+
+func TestNotebooks(t *testing.T) {
+	ts, ns, cleanup := setupServer(t)
+	defer cleanup()
+
+	var bs []*jsonapi.Notebook
+	resp := doRequest(t, ts, http.MethodGet, "/v1/notebooks", "", &bs)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []*jsonapi.Notebook{
+		{GUID: guidNotebook, Name: "Notes", Default: true},
+		{GUID: guidArchive, Name: "Archive", Stack: "Old"},
+	}, bs)
+
+	var b jsonapi.Notebook
+	resp = doRequest(t, ts, http.MethodPost, "/v1/notebooks", `{"name": "Work", "stack": "Jobs"}`, &b)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "Work", b.Name)
+	assert.Equal(t, "Jobs", b.Stack)
+	assert.Len(t, ns.Notebooks, 3)
+
+	resp = doRequest(t, ts, http.MethodPost, "/v1/notebooks", `{"stack": "Jobs"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	ts, _, cleanup := setupServer(t)
+	defer cleanup()
+
+	resp := doRequest(t, ts, http.MethodDelete, "/v1/notes", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "GET, POST", resp.Header.Get("Allow"))
+}
@@ -0,0 +1,101 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	// ErrNoStackFound is returned if no notebook belongs to the stack.
+	ErrNoStackFound = errors.New("no stack found")
+)
+
+// Stack is a group of notebooks.
+type Stack struct {
+	// Name is the stack's name.
+	Name string
+	// Notebooks are the notebooks in the stack.
+	Notebooks []*Notebook
+}
+
+// GetStacks returns all the stacks sorted by name. Notebooks that don't
+// belong to a stack are not included.
+func GetStacks(db Storager, ns NotestoreClient) ([]*Stack, error) {
+	bs, err := GetNotebooks(db, ns, false)
+	if err != nil {
+		return nil, err
+	}
+	return groupByStack(bs), nil
+}
+
+// RenameStack moves all the notebooks in the stack to the new stack.
+func RenameStack(db Storager, ns NotestoreClient, name, newName string) error {
+	return updateStack(db, ns, name, newName)
+}
+
+// RemoveStack removes the stack. The notebooks in the stack are kept.
+func RemoveStack(db Storager, ns NotestoreClient, name string) error {
+	return updateStack(db, ns, name, "")
+}
+
+func updateStack(db Storager, ns NotestoreClient, name, newName string) error {
+	bs, err := GetNotebooks(db, ns, true)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, b := range bs {
+		if b.Stack != name {
+			continue
+		}
+		found = true
+		b.Stack = newName
+		if err = ns.UpdateNotebook(b); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return ErrNoStackFound
+	}
+	_, err = GetNotebooks(db, ns, true)
+	return err
+}
+
+func groupByStack(bs []*Notebook) []*Stack {
+	stacks := make(map[string]*Stack)
+	for _, b := range bs {
+		if b.Stack == "" {
+			continue
+		}
+		s, ok := stacks[b.Stack]
+		if !ok {
+			s = &Stack{Name: b.Stack}
+			stacks[b.Stack] = s
+		}
+		s.Notebooks = append(s.Notebooks, b)
+	}
+	list := make([]*Stack, 0, len(stacks))
+	for _, s := range stacks {
+		sort.Slice(s.Notebooks, func(i, j int) bool { return s.Notebooks[i].Name < s.Notebooks[j].Name })
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
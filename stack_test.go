@@ -0,0 +1,69 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStacks(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	setupNS := func() *mockNS {
+		return &mockNS{getAllNotebooks: func() ([]*Notebook, error) {
+			return []*Notebook{
+				&Notebook{Name: "B", GUID: "B", Stack: "Work"},
+				&Notebook{Name: "A", GUID: "A", Stack: "Work"},
+				&Notebook{Name: "C", GUID: "C", Stack: "Home"},
+				&Notebook{Name: "D", GUID: "D"},
+			}, nil
+		}}
+	}
+	t.Run("list stacks", func(t *testing.T) {
+		stacks, err := GetStacks(store, setupNS())
+		assert.NoError(err, "Should not return an error")
+		assert.Len(stacks, 2, "Wrong number of stacks")
+		assert.Equal("Home", stacks[0].Name, "Stacks should be sorted")
+		assert.Equal("Work", stacks[1].Name, "Stacks should be sorted")
+		assert.Equal("A", stacks[1].Notebooks[0].Name, "Notebooks should be sorted")
+		assert.Len(stacks[1].Notebooks, 2, "Wrong number of notebooks in stack")
+	})
+	t.Run("rename stack", func(t *testing.T) {
+		ns := setupNS()
+		updated := map[string]string{}
+		ns.updateNotebook = func(b *Notebook) error { updated[b.GUID] = b.Stack; return nil }
+		assert.NoError(RenameStack(store, ns, "Work", "Office"), "Should not return an error")
+		assert.Equal(map[string]string{"A": "Office", "B": "Office"}, updated)
+	})
+	t.Run("remove stack", func(t *testing.T) {
+		ns := setupNS()
+		updated := map[string]string{}
+		ns.updateNotebook = func(b *Notebook) error { updated[b.GUID] = b.Stack; return nil }
+		assert.NoError(RemoveStack(store, ns, "Home"), "Should not return an error")
+		assert.Equal(map[string]string{"C": ""}, updated)
+	})
+	t.Run("stack not found", func(t *testing.T) {
+		err := RemoveStack(store, setupNS(), "Missing")
+		assert.Equal(ErrNoStackFound, err, "Wrong error returned")
+	})
+}
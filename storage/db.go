@@ -37,6 +37,9 @@ const (
 // 1: Added credential store, migration of OAuth token.
 var softwareDBVersion = uint64(1)
 
+// recoveryJournalSize is the number of entries kept in the recovery journal.
+var recoveryJournalSize = 100
+
 // This is what the current wait time before the database is closed.
 var currentWaitTime = 5 * time.Second
 
@@ -53,7 +56,10 @@ var (
 	credentialsKey      = []byte("user_credentials")
 	notebookCacheKey    = []byte("notebook_cache")
 	searchCacheKey      = []byte("note_search_cache")
+	trashSearchCacheKey = []byte("note_trash_search_cache")
 	noteRecoverCacheKey = []byte("note_recover_cache")
+	recoveryJournalKey  = []byte("note_recovery_journal")
+	linkIndexKey        = []byte("note_link_index")
 	dbVersionKey        = []byte("dbVersion")
 )
 
@@ -63,18 +69,33 @@ var (
 	ErrIndexOutOfRange = errors.New("index out of range")
 	// ErrEncodeDBVersion is returned if the database version fails to be encoded.
 	ErrEncodeDBVersion = errors.New("failed to encode db version")
+	// ErrDatabaseLocked is returned if another process has the database
+	// open for longer than the timeout.
+	ErrDatabaseLocked = errors.New("database in use by another clinote process")
 )
 
 // Open returns an instance of the database.
 func Open(cfgFolder string) (*Database, error) {
+	return open(cfgFolder, nil)
+}
+
+// OpenTimeout returns an instance of the database. If another process
+// has the database open, ErrDatabaseLocked is returned once the timeout
+// has passed. The timeout is also used when the database is reopened.
+func OpenTimeout(cfgFolder string, timeout time.Duration) (*Database, error) {
+	return open(cfgFolder, &bolt.Options{Timeout: timeout})
+}
+
+func open(cfgFolder string, opts *bolt.Options) (*Database, error) {
 	filename := filepath.Join(cfgFolder, dbFilename)
-	b, err := bolt.Open(filename, 0600, nil)
+	b, err := openBolt(filename, opts)
 	if err != nil {
 		return nil, err
 	}
 	d := &Database{
 		bolt:       b,
 		dbFilename: filename,
+		options:    opts,
 		resetChan:  make(chan struct{}, 1),
 		// TODO: This property should be configurable.
 		waitTime: currentWaitTime,
@@ -126,6 +147,8 @@ type Database struct {
 	resetChan chan struct{}
 	// waitTime is how long the database should be held open.
 	waitTime time.Duration
+	// options are used when the database file is opened.
+	options *bolt.Options
 }
 
 // open is used internally to reopen the database file. This method is not thread safe and
@@ -136,7 +159,15 @@ func (d *Database) open() (*bolt.DB, error) {
 	}
 	// Start closing wait loop
 	go dbWaitingLoop(d)
-	return bolt.Open(d.dbFilename, 0600, nil)
+	return openBolt(d.dbFilename, d.options)
+}
+
+func openBolt(filename string, opts *bolt.Options) (*bolt.DB, error) {
+	b, err := bolt.Open(filename, 0600, opts)
+	if err == bolt.ErrTimeout {
+		return nil, ErrDatabaseLocked
+	}
+	return b, err
 }
 
 func (d *Database) closeDB() error {
@@ -300,6 +331,27 @@ func (d *Database) GetSearch() ([]*clinote.Note, error) {
 	return notes, err
 }
 
+// SaveTrashSearch stores the listing of the trash to the database. It's
+// kept apart from the saved search so listing the trash doesn't change
+// the note indexes.
+func (d *Database) SaveTrashSearch(notes []*clinote.Note) error {
+	data, err := json.Marshal(notes)
+	if err != nil {
+		return err
+	}
+	return d.storeData(cacheBucket, trashSearchCacheKey, data)
+}
+
+// GetTrashSearch gets the saved trash listing from the database.
+func (d *Database) GetTrashSearch() ([]*clinote.Note, error) {
+	var notes []*clinote.Note
+	data, err := d.getData(cacheBucket, trashSearchCacheKey)
+	if err == nil && data != nil {
+		err = json.Unmarshal(data, &notes)
+	}
+	return notes, err
+}
+
 // SaveNoteRecoveryPoint saves the note to the database so it can be
 // recovered in the case something fails.
 func (d *Database) SaveNoteRecoveryPoint(note *clinote.Note) error {
@@ -320,6 +372,53 @@ func (d *Database) GetNoteRecoveryPoint() (*clinote.Note, error) {
 	return &note, err
 }
 
+// AddRecoveryEntry adds the entry to the recovery journal. Once the
+// journal is full, the oldest entry is removed.
+func (d *Database) AddRecoveryEntry(entry *clinote.RecoveryEntry) error {
+	entries, err := d.GetRecoveryJournal()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > recoveryJournalSize {
+		entries = entries[len(entries)-recoveryJournalSize:]
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return d.storeData(cacheBucket, recoveryJournalKey, data)
+}
+
+// GetRecoveryJournal returns the entries in the recovery journal, oldest first.
+func (d *Database) GetRecoveryJournal() ([]*clinote.RecoveryEntry, error) {
+	var entries []*clinote.RecoveryEntry
+	data, err := d.getData(cacheBucket, recoveryJournalKey)
+	if err == nil && data != nil {
+		err = json.Unmarshal(data, &entries)
+	}
+	return entries, err
+}
+
+// GetLinkIndex returns the stored index of links between notes.
+func (d *Database) GetLinkIndex() (*clinote.LinkIndex, error) {
+	var index clinote.LinkIndex
+	data, err := d.getData(cacheBucket, linkIndexKey)
+	if err == nil && data != nil {
+		err = json.Unmarshal(data, &index)
+	}
+	return &index, err
+}
+
+// StoreLinkIndex saves the link index to the database.
+func (d *Database) StoreLinkIndex(index *clinote.LinkIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return d.storeData(cacheBucket, linkIndexKey, data)
+}
+
 // Close shuts down the connection to the database.
 func (d *Database) Close() error {
 	return d.closeDB()
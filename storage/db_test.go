@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/TcM1911/clinote"
 	"github.com/stretchr/testify/assert"
@@ -97,6 +98,17 @@ func TestSearchCaching(t *testing.T) {
 		assert.NoError(err, "Should not return an error")
 		assert.Equal(expected, actual, "Wrong data returned from store")
 	})
+
+	t.Run("Trash listing kept apart", func(t *testing.T) {
+		trashed := []*clinote.Note{&clinote.Note{Title: "Trashed", Deleted: true}}
+		assert.NoError(db.SaveTrashSearch(trashed), "Should not fail to store the trash listing")
+		actual, err := db.GetTrashSearch()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(trashed, actual, "Wrong trash listing returned")
+		actual, err = db.GetSearch()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected, actual, "The saved search should not change")
+	})
 }
 
 func TestRecoveryPoint(t *testing.T) {
@@ -118,6 +130,57 @@ func TestRecoveryPoint(t *testing.T) {
 	})
 }
 
+func TestRecoveryJournal(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	oldSize := recoveryJournalSize
+	recoveryJournalSize = 2
+	defer func() { recoveryJournalSize = oldSize }()
+
+	entries, err := db.GetRecoveryJournal()
+	assert.NoError(err, "Should not fail on an empty journal")
+	assert.Empty(entries, "Journal should be empty")
+
+	for _, title := range []string{"First", "Second", "Third"} {
+		err = db.AddRecoveryEntry(&clinote.RecoveryEntry{Note: &clinote.Note{Title: title}, Operation: "replace"})
+		assert.NoError(err, "Should not fail to add an entry")
+	}
+	entries, err = db.GetRecoveryJournal()
+	assert.NoError(err, "Should not fail to return the journal")
+	if assert.Len(entries, 2, "Oldest entry should be removed") {
+		assert.Equal("Second", entries[0].Note.Title)
+		assert.Equal("Third", entries[1].Note.Title)
+	}
+}
+
+func TestLinkIndex(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	expected := &clinote.LinkIndex{Notes: map[string]*clinote.LinkIndexEntry{
+		"GUID1": &clinote.LinkIndexEntry{Title: "Note 1", Links: []string{"GUID2"}},
+	}}
+
+	t.Run("Empty", func(t *testing.T) {
+		index, err := db.GetLinkIndex()
+		assert.NoError(err, "Should not fail on an empty index")
+		assert.Empty(index.Notes, "Index should be empty")
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		assert.NoError(db.StoreLinkIndex(expected), "Should not fail to save")
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		index, err := db.GetLinkIndex()
+		assert.NoError(err, "Should not fail to return the index")
+		assert.Equal(expected, index, "Wrong index returned")
+	})
+}
+
 func TestCredentialStore(t *testing.T) {
 	assert := assert.New(t)
 	db, tmpDir := setupTestDB(t)
@@ -183,6 +246,20 @@ func compareCacheList(assert *assert.Assertions, expected *clinote.NotebookCache
 	assert.True(expected.Timestamp.Equal(actual.Timestamp), "Wrong timestamp")
 }
 
+func TestOpenTimeoutLocked(t *testing.T) {
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+
+	_, err := OpenTimeout(tmpDir, 10*time.Millisecond)
+	assert.Equal(t, ErrDatabaseLocked, err, "Should fail when the database is open")
+	db.Close()
+	other, err := OpenTimeout(tmpDir, 10*time.Millisecond)
+	if assert.NoError(t, err, "Should open when the database is closed") {
+		other.Close()
+	}
+}
+
 func setupTestDB(t *testing.T) (*Database, string) {
 	tmpDir, err := ioutil.TempDir("", "clinote-test")
 	if err != nil {
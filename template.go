@@ -0,0 +1,244 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// templateFolder is the folder in the config folder holding the templates.
+	templateFolder = "templates"
+	// templateExt is the file extension of template files.
+	templateExt = ".md"
+	// templateNoteCount is the maximum number of templates read from the
+	// template notebook.
+	templateNoteCount = 100
+	// defaultTemplate is the content of a new template.
+	defaultTemplate = "# {{title}}\n\n{{date}} - {{user}}\n"
+)
+
+var (
+	// ErrNoTemplateFound is returned if no template with the name exists.
+	ErrNoTemplateFound = errors.New("no template found")
+	// ErrTemplateExists is returned when creating a template that already exists.
+	ErrTemplateExists = errors.New("template already exists")
+	// ErrInvalidTemplateName is returned if the template name can't be used
+	// as a file name.
+	ErrInvalidTemplateName = errors.New("invalid template name")
+)
+
+// Template is a markdown skeleton for new notes.
+type Template struct {
+	// Name is the name of the template.
+	Name string
+	// Content is the markdown content with placeholders.
+	Content string
+	// Notebook is the notebook the template is stored in. It's empty for
+	// templates stored in the config folder.
+	Notebook string
+}
+
+// TemplateVars are the values of the template placeholders.
+type TemplateVars struct {
+	// Title replaces {{title}}.
+	Title string
+	// User replaces {{user}}.
+	User string
+	// Notebook replaces {{notebook}}.
+	Notebook string
+}
+
+// RenderTemplate replaces the placeholders {{title}}, {{user}},
+// {{notebook}}, {{date}}, {{time}} and {{datetime}} in the template.
+func RenderTemplate(t *Template, vars TemplateVars) string {
+	ts := now()
+	r := strings.NewReplacer(
+		"{{title}}", vars.Title,
+		"{{user}}", vars.User,
+		"{{notebook}}", vars.Notebook,
+		"{{date}}", ts.Format(timeFormat),
+		"{{time}}", ts.Format("15:04"),
+		"{{datetime}}", ts.Format(timestampFormat),
+	)
+	return r.Replace(t.Content)
+}
+
+// GetTemplates returns the templates in the config folder followed by
+// the templates in the template notebook, if one is set.
+func GetTemplates(client *Client) ([]*Template, error) {
+	ts, err := localTemplates(client.Config)
+	if err != nil {
+		return nil, err
+	}
+	notes, notebook, err := templateNotes(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		ts = append(ts, &Template{Name: n.Title, Notebook: notebook})
+	}
+	return ts, nil
+}
+
+// GetTemplate returns the template with the name. Templates in the config
+// folder are used before templates in the template notebook.
+func GetTemplate(client *Client, name string) (*Template, error) {
+	if validTemplateName(name) {
+		content, err := ioutil.ReadFile(templatePath(client.Config, name))
+		if err == nil {
+			return &Template{Name: name, Content: string(content)}, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	notes, notebook, err := templateNotes(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		if n.Title != name {
+			continue
+		}
+		if err = loadNoteContent(client.Store, client.NoteStore, n); err != nil {
+			return nil, err
+		}
+		return &Template{Name: name, Content: n.MD, Notebook: notebook}, nil
+	}
+	return nil, ErrNoTemplateFound
+}
+
+// CreateNoteFromTemplate renders the template into the note and opens
+// the note in the editor before it's saved.
+func CreateNoteFromTemplate(client *Client, note *Note, name string, opts NoteOption) error {
+	t, err := GetTemplate(client, name)
+	if err != nil {
+		return err
+	}
+	note.MD = RenderTemplate(t, templateVars(client, note))
+	return CreateAndEditNewNote(client, note, opts&^RawNote)
+}
+
+// templateVars returns the placeholder values for the new note.
+func templateVars(client *Client, note *Note) TemplateVars {
+	vars := TemplateVars{Title: note.Title}
+	if note.Notebook != nil {
+		vars.Notebook = note.Notebook.Name
+	}
+	if settings, err := client.Store.GetSettings(); err == nil && settings.Credential != nil {
+		vars.User = settings.Credential.Name
+	}
+	if vars.User == "" {
+		vars.User = os.Getenv("USER")
+	}
+	return vars
+}
+
+// NewTemplate creates a new template in the config folder and opens it
+// in the editor.
+func NewTemplate(client *Client, name string) error {
+	fp := templatePath(client.Config, name)
+	if !validTemplateName(name) {
+		return ErrInvalidTemplateName
+	}
+	if _, err := os.Stat(fp); err == nil {
+		return ErrTemplateExists
+	}
+	if err := os.MkdirAll(filepath.Dir(fp), 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fp, []byte(defaultTemplate), 0600); err != nil {
+		return err
+	}
+	return editTemplateFile(client, fp)
+}
+
+// EditTemplate opens the template in the config folder in the editor.
+func EditTemplate(client *Client, name string) error {
+	fp := templatePath(client.Config, name)
+	if _, err := os.Stat(fp); os.IsNotExist(err) || !validTemplateName(name) {
+		return ErrNoTemplateFound
+	}
+	return editTemplateFile(client, fp)
+}
+
+func editTemplateFile(client *Client, fp string) error {
+	f, err := os.OpenFile(fp, os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	file := &FileCacheFile{file: f, fp: fp}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return client.Edit(file)
+}
+
+func localTemplates(cfg Configuration) ([]*Template, error) {
+	files, err := ioutil.ReadDir(filepath.Join(cfg.GetConfigFolder(), templateFolder))
+	if os.IsNotExist(err) {
+		return []*Template{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ts := []*Template{}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != templateExt {
+			continue
+		}
+		ts = append(ts, &Template{Name: strings.TrimSuffix(f.Name(), templateExt)})
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Name < ts[j].Name })
+	return ts, nil
+}
+
+// templateNotes returns the notes in the template notebook and the
+// notebook's name. If no template notebook is set, no notes are returned.
+func templateNotes(client *Client) ([]*Note, string, error) {
+	settings, err := client.Store.GetSettings()
+	if err != nil {
+		return nil, "", err
+	}
+	if settings.TemplateNotebook == "" {
+		return nil, "", nil
+	}
+	nb, err := FindNotebook(client.Store, client.NoteStore, settings.TemplateNotebook)
+	if err != nil {
+		return nil, "", err
+	}
+	notes, err := client.NoteStore.FindNotes(&NoteFilter{NotebookGUID: nb.GUID}, 0, templateNoteCount)
+	if err != nil {
+		return nil, "", err
+	}
+	return notes, nb.Name, nil
+}
+
+func templatePath(cfg Configuration, name string) string {
+	return filepath.Join(cfg.GetConfigFolder(), templateFolder, name+templateExt)
+}
+
+func validTemplateName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\`) && name != "." && name != ".."
+}
@@ -0,0 +1,121 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, 5, 1, 9, 5, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+	tmpl := &Template{Content: "# {{title}}\n{{date}} {{time}} by {{user}} in {{notebook}} ({{datetime}}) {{unknown}}"}
+	actual := RenderTemplate(tmpl, TemplateVars{Title: "Standup", User: "joakim", Notebook: "Work"})
+	assert.Equal(t, "# Standup\n2018-05-01 09:05 by joakim in Work (2018-05-01 09:05:00) {{unknown}}", actual)
+}
+
+func TestTemplates(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-templates")
+	if !assert.NoError(err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	settings := &Settings{Credential: &Credential{Name: "joakim"}}
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+		getSettings:       func() (*Settings, error) { return settings, nil },
+	}
+	ns := &mockNS{
+		getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{&Notebook{Name: "Templates", GUID: "TMPL"}}, nil },
+		findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			if f.NotebookGUID == "TMPL" || f.Words == "Incident" {
+				return []*Note{&Note{Title: "Incident", GUID: "INCIDENT"}}, nil
+			}
+			return []*Note{}, nil
+		},
+		getNoteContent: func(string) (string, error) {
+			return XMLHeader + "<en-note><h1>Incident {{date}}</h1></en-note>", nil
+		},
+	}
+	var edited []string
+	client := &Client{
+		Config:    &mockConfig{dir: dir, store: store},
+		Store:     store,
+		NoteStore: ns,
+		Editor: &mockEditor{edit: func(f CacheFile) error {
+			edited = append(edited, f.FilePath())
+			return nil
+		}},
+	}
+
+	t.Run("new template", func(t *testing.T) {
+		assert.NoError(NewTemplate(client, "meeting"), "Should not return an error")
+		fp := filepath.Join(dir, templateFolder, "meeting.md")
+		assert.Equal([]string{fp}, edited, "Template should be opened in the editor")
+		content, err := ioutil.ReadFile(fp)
+		assert.NoError(err, "Template file should be created")
+		assert.Equal(defaultTemplate, string(content), "Wrong default content")
+		assert.Equal(ErrTemplateExists, NewTemplate(client, "meeting"), "Wrong error returned")
+		assert.Equal(ErrInvalidTemplateName, NewTemplate(client, "../meeting"), "Wrong error returned")
+	})
+	t.Run("edit template", func(t *testing.T) {
+		edited = nil
+		assert.NoError(EditTemplate(client, "meeting"), "Should not return an error")
+		assert.Len(edited, 1, "Template should be opened in the editor")
+		assert.Equal(ErrNoTemplateFound, EditTemplate(client, "missing"), "Wrong error returned")
+	})
+	t.Run("list templates", func(t *testing.T) {
+		settings.TemplateNotebook = "Templates"
+		defer func() { settings.TemplateNotebook = "" }()
+		ts, err := GetTemplates(client)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Template{&Template{Name: "meeting"}, &Template{Name: "Incident", Notebook: "Templates"}}, ts)
+	})
+	t.Run("template from notebook", func(t *testing.T) {
+		settings.TemplateNotebook = "Templates"
+		defer func() { settings.TemplateNotebook = "" }()
+		tmpl, err := GetTemplate(client, "Incident")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("# Incident {{date}}", tmpl.Content, "Wrong template content")
+	})
+	t.Run("template not found", func(t *testing.T) {
+		_, err := GetTemplate(client, "Incident")
+		assert.Equal(ErrNoTemplateFound, err, "Wrong error returned")
+	})
+	t.Run("create note from template", func(t *testing.T) {
+		now = func() time.Time { return time.Date(2018, 5, 1, 9, 5, 0, 0, time.Local) }
+		defer func() { now = time.Now }()
+		buf := new(bytes.Buffer)
+		client.newCacheFile = func(*Client, string) (CacheFile, error) { return &mockCacheFile{buffer: buf}, nil }
+		var created *Note
+		ns.createNote = func(n *Note) error { created = n; return nil }
+		note := &Note{Title: "Standup", Notebook: &Notebook{Name: "Work"}}
+		err := CreateNoteFromTemplate(client, note, "meeting", DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("# Standup\n\n2018-05-01 - joakim", created.MD, "Template should be rendered")
+	})
+}
@@ -0,0 +1,69 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import "errors"
+
+var (
+	// ErrNoteNotInTrash is returned if a note from the saved search
+	// is used for a trash operation but hasn't been deleted.
+	ErrNoteNotInTrash = errors.New("note is not in the trash")
+)
+
+// GetTrash returns the notes in the trash can.
+func GetTrash(ns NotestoreClient, count int) ([]*Note, error) {
+	filter := &NoteFilter{Inactive: true, Order: NoteFilterOrderUpdated}
+	return ns.FindNotes(filter, 0, count)
+}
+
+// GetTrashedNote gets the note in the trash can. The reference is resolved
+// like in ResolveNote except that only the notes in the trash are searched
+// and an index refers to the last trash listing.
+func GetTrashedNote(db Storager, ns NotestoreClient, ref string) (*Note, error) {
+	n, err := resolveNote(db, ns, ref, "", true)
+	if err != nil {
+		return nil, err
+	}
+	if !n.Deleted {
+		return nil, ErrNoteNotInTrash
+	}
+	return n, nil
+}
+
+// RestoreNote moves the note from the trash can back to its notebook.
+func RestoreNote(db Storager, ns NotestoreClient, title string) error {
+	n, err := GetTrashedNote(db, ns, title)
+	if err != nil {
+		return err
+	}
+	return ns.RestoreNote(n)
+}
+
+// ExpungeNote permanently removes the note from the trash can.
+func ExpungeNote(db Storager, ns NotestoreClient, title string) error {
+	n, err := GetTrashedNote(db, ns, title)
+	if err != nil {
+		return err
+	}
+	return ns.ExpungeNote(n.GUID)
+}
+
+// EmptyTrash permanently removes all the notes in the trash can.
+func EmptyTrash(ns NotestoreClient) error {
+	return ns.EmptyTrash()
+}
@@ -0,0 +1,128 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash(t *testing.T) {
+	assert := assert.New(t)
+	trashed := &Note{Title: "Trashed", GUID: "GUID", Deleted: true}
+	ns := &mockNS{findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+		if !f.Inactive {
+			return []*Note{}, nil
+		}
+		return []*Note{trashed}, nil
+	}}
+
+	t.Run("list trash", func(t *testing.T) {
+		var filter *NoteFilter
+		ns := &mockNS{findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			filter = f
+			return []*Note{trashed}, nil
+		}}
+		notes, err := GetTrash(ns, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.True(filter.Inactive, "Should search the trash")
+		assert.Equal([]*Note{trashed}, notes)
+	})
+	t.Run("get trashed note by title", func(t *testing.T) {
+		n, err := GetTrashedNote(&mockStore{}, ns, "Trashed")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(trashed, n, "Wrong note returned")
+	})
+	t.Run("no trashed note found", func(t *testing.T) {
+		_, err := GetTrashedNote(&mockStore{}, ns, "Missing")
+		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+	})
+	t.Run("get trashed note by index", func(t *testing.T) {
+		store := &mockStore{
+			getSearch:      func() ([]*Note, error) { return []*Note{&Note{Title: "Active"}}, nil },
+			getTrashSearch: func() ([]*Note, error) { return []*Note{trashed}, nil },
+		}
+		n, err := GetTrashedNote(store, ns, "1")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(trashed, n, "Should use the trash listing")
+		n, err = GetTrashedNote(store, ns, "#1")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(trashed, n, "Should use the trash listing")
+	})
+	t.Run("index of note not in trash", func(t *testing.T) {
+		store := &mockStore{getTrashSearch: func() ([]*Note, error) { return []*Note{&Note{Title: "Active"}}, nil }}
+		_, err := GetTrashedNote(store, ns, "1")
+		assert.Equal(ErrNoteNotInTrash, err, "Wrong error returned")
+	})
+	t.Run("get trashed note by guid", func(t *testing.T) {
+		guid := "11111111-2222-3333-4444-555555555555"
+		ns := &mockNS{getNote: func(g string) (*Note, error) {
+			return &Note{Title: "Trashed", GUID: g, Deleted: true}, nil
+		}}
+		n, err := GetTrashedNote(&mockStore{}, ns, GUIDPrefix+guid)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(guid, n.GUID, "Wrong note returned")
+		ns.getNote = func(g string) (*Note, error) { return &Note{Title: "Active", GUID: g}, nil }
+		_, err = GetTrashedNote(&mockStore{}, ns, GUIDPrefix+guid)
+		assert.Equal(ErrNoteNotInTrash, err, "Wrong error returned")
+	})
+	t.Run("duplicate titles are ambiguous", func(t *testing.T) {
+		ns := &mockNS{findNotes: func(f *NoteFilter, offset, count int) ([]*Note, error) {
+			return []*Note{
+				&Note{Title: "Trashed", GUID: "GUID1", Deleted: true},
+				&Note{Title: "Trashed", GUID: "GUID2", Deleted: true},
+			}, nil
+		}}
+		var expunged bool
+		ns.expungeNote = func(string) error {
+			expunged = true
+			return nil
+		}
+		err := ExpungeNote(&mockStore{}, ns, "Trashed")
+		assert.IsType(&AmbiguousNoteError{}, err, "Wrong error returned")
+		assert.False(expunged, "No note should be expunged")
+	})
+	t.Run("restore note", func(t *testing.T) {
+		var restored *Note
+		ns.restoreNote = func(n *Note) error {
+			restored = n
+			return nil
+		}
+		assert.NoError(RestoreNote(&mockStore{}, ns, "Trashed"), "Should not return an error")
+		assert.Equal(trashed, restored, "Wrong note restored")
+	})
+	t.Run("expunge note", func(t *testing.T) {
+		var expunged string
+		ns.expungeNote = func(guid string) error {
+			expunged = guid
+			return nil
+		}
+		assert.NoError(ExpungeNote(&mockStore{}, ns, "Trashed"), "Should not return an error")
+		assert.Equal("GUID", expunged, "Wrong note expunged")
+	})
+	t.Run("empty trash", func(t *testing.T) {
+		called := false
+		ns.emptyTrash = func() error {
+			called = true
+			return nil
+		}
+		assert.NoError(EmptyTrash(ns), "Should not return an error")
+		assert.True(called, "Trash should be emptied")
+	})
+}
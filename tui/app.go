@@ -0,0 +1,544 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+// Package tui provides a full-screen terminal UI for browsing and
+// editing notes.
+package tui
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/TcM1911/clinote"
+)
+
+const (
+	// noteListSize is the number of notes fetched for the note list.
+	noteListSize = 100
+	// maxMove moves the selection to the first or last row.
+	maxMove = 1 << 20
+)
+
+// pane is one of the panes of the UI.
+type pane int
+
+const (
+	treePane pane = iota
+	listPane
+	previewPane
+	paneCount
+)
+
+// Options controls the UI.
+type Options struct {
+	// Color enables colors and styles.
+	Color bool
+	// NoteOptions are used when notes are edited and created.
+	NoteOptions clinote.NoteOption
+}
+
+// treeItem is a row in the notebook tree. It's either all notes, a
+// stack or a notebook.
+type treeItem struct {
+	label    string
+	depth    int
+	stack    string
+	notebook *clinote.Notebook
+}
+
+// prompt reads a line from the user in the status line.
+type prompt struct {
+	label string
+	input []rune
+	// names are used to complete the input with tab.
+	names []string
+	done  func(value string) error
+}
+
+// App is the terminal UI.
+type App struct {
+	client *clinote.Client
+	term   Terminal
+	opts   Options
+
+	focus   pane
+	tree    []*treeItem
+	treeSel int
+	// notes are the notes from the notestore and visible the notes
+	// matching the live search.
+	notes   []*clinote.Note
+	visible []*clinote.Note
+	listSel int
+	// query is the search sent to the notestore and filter the live
+	// search typed by the user.
+	query     string
+	filter    []rune
+	searching bool
+	// preview holds the markdown of the notes by GUID.
+	preview    map[string]string
+	previewTop int
+	prompt     *prompt
+	status     string
+	quit       bool
+	// height is the terminal's height when the UI was last drawn.
+	height int
+}
+
+// New returns the UI for the client's notes drawn on the terminal.
+func New(client *clinote.Client, term Terminal, opts Options) *App {
+	return &App{
+		client:  client,
+		term:    term,
+		opts:    opts,
+		focus:   listPane,
+		preview: make(map[string]string),
+	}
+}
+
+// Run shows the UI until the user quits.
+func (a *App) Run() error {
+	if err := a.loadNotebooks(false); err != nil {
+		return err
+	}
+	if err := a.loadNotes(); err != nil {
+		return err
+	}
+	for !a.quit {
+		if err := a.draw(); err != nil {
+			return err
+		}
+		k, err := a.term.ReadKey()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		a.status = ""
+		if err = a.handleKey(k); err != nil {
+			a.status = "Error: " + err.Error()
+		}
+	}
+	return nil
+}
+
+// loadNotebooks builds the tree of stacks and notebooks.
+func (a *App) loadNotebooks(sync bool) error {
+	bs, err := clinote.GetNotebooks(a.client.Store, a.client.NoteStore, sync)
+	if err != nil {
+		return err
+	}
+	var selected string
+	if a.treeSel < len(a.tree) {
+		selected = a.tree[a.treeSel].label
+	}
+	sorted := make([]*clinote.Notebook, len(bs))
+	copy(sorted, bs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Stack != sorted[j].Stack {
+			// Notebooks without a stack are listed last.
+			if sorted[i].Stack == "" || sorted[j].Stack == "" {
+				return sorted[j].Stack == ""
+			}
+			return strings.ToLower(sorted[i].Stack) < strings.ToLower(sorted[j].Stack)
+		}
+		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+	})
+	a.tree = []*treeItem{{label: "All notes"}}
+	stack := ""
+	for _, b := range sorted {
+		depth := 0
+		if b.Stack != "" {
+			if b.Stack != stack {
+				stack = b.Stack
+				a.tree = append(a.tree, &treeItem{label: stack, stack: stack})
+			}
+			depth = 1
+		}
+		a.tree = append(a.tree, &treeItem{label: b.Name, depth: depth, notebook: b})
+	}
+	a.treeSel = 0
+	for i, item := range a.tree {
+		if item.label == selected {
+			a.treeSel = i
+		}
+	}
+	return nil
+}
+
+// loadNotes searches for the notes in the selected notebook or stack.
+// The result is saved as the last search so the notes can be referred
+// to by index from the command line afterwards.
+func (a *App) loadNotes() error {
+	words, err := clinote.ExpandSearch(a.client.Store, a.client.NoteStore, a.query)
+	if err != nil {
+		return err
+	}
+	filter := &clinote.NoteFilter{Order: clinote.NoteFilterOrderUpdated}
+	item := a.tree[a.treeSel]
+	if item.notebook != nil {
+		filter.NotebookGUID = item.notebook.GUID
+	} else if item.stack != "" {
+		words = strings.TrimSpace(`stack:"` + item.stack + `" ` + words)
+	}
+	filter.Words = words
+	notes, err := clinote.FindNotes(a.client.NoteStore, filter, 0, noteListSize)
+	if err != nil {
+		return err
+	}
+	a.notes = notes
+	a.applyFilter()
+	return a.client.Store.SaveSearch(notes)
+}
+
+// applyFilter updates the visible notes with the live search. A note is
+// visible if its title contains all the words.
+func (a *App) applyFilter() {
+	words := strings.Fields(strings.ToLower(string(a.filter)))
+	a.visible = a.visible[:0]
+	for _, n := range a.notes {
+		title := strings.ToLower(n.Title)
+		match := true
+		for _, w := range words {
+			if !strings.Contains(title, w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			a.visible = append(a.visible, n)
+		}
+	}
+	a.selectNote(a.listSel)
+}
+
+func (a *App) selectNote(i int) {
+	if i >= len(a.visible) {
+		i = len(a.visible) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i != a.listSel {
+		a.previewTop = 0
+	}
+	a.listSel = i
+}
+
+func (a *App) selectedNote() *clinote.Note {
+	if a.listSel < len(a.visible) {
+		return a.visible[a.listSel]
+	}
+	return nil
+}
+
+// previewMarkdown returns the markdown of the selected note. The content
+// is fetched from the notestore the first time the note is previewed.
+func (a *App) previewMarkdown() (string, error) {
+	n := a.selectedNote()
+	if n == nil {
+		return "", nil
+	}
+	if md, ok := a.preview[n.GUID]; ok {
+		return md, nil
+	}
+	full, err := clinote.GetNoteWithContent(a.client.Store, a.client.NoteStore, clinote.GUIDPrefix+n.GUID)
+	if err != nil {
+		return "", err
+	}
+	a.preview[n.GUID] = full.MD
+	return full.MD, nil
+}
+
+func (a *App) handleKey(k Key) error {
+	if a.prompt != nil {
+		return a.handlePromptKey(k)
+	}
+	if a.searching {
+		return a.handleSearchKey(k)
+	}
+	switch k.Code {
+	case KeyCtrlC:
+		a.quit = true
+	case KeyTab, KeyRight:
+		a.focus = (a.focus + 1) % paneCount
+	case KeyBackTab, KeyLeft:
+		a.focus = (a.focus + paneCount - 1) % paneCount
+	case KeyUp:
+		a.move(-1)
+	case KeyDown:
+		a.move(1)
+	case KeyPgUp:
+		a.move(-a.pageSize())
+	case KeyPgDn:
+		a.move(a.pageSize())
+	case KeyHome:
+		a.move(-maxMove)
+	case KeyEnd:
+		a.move(maxMove)
+	case KeyEnter:
+		return a.enter()
+	case KeyEsc:
+		if a.query != "" {
+			a.query = ""
+			return a.loadNotes()
+		}
+	case KeyRune:
+		return a.handleCommand(k.Rune)
+	}
+	return nil
+}
+
+func (a *App) handleCommand(r rune) error {
+	switch r {
+	case 'q':
+		a.quit = true
+	case 'j':
+		a.move(1)
+	case 'k':
+		a.move(-1)
+	case 'h':
+		a.focus = (a.focus + paneCount - 1) % paneCount
+	case 'l':
+		a.focus = (a.focus + 1) % paneCount
+	case '/':
+		a.searching = true
+		a.filter = nil
+		a.focus = listPane
+	case 'R':
+		a.preview = make(map[string]string)
+		if err := a.loadNotebooks(true); err != nil {
+			return err
+		}
+		if err := a.loadNotes(); err != nil {
+			return err
+		}
+		a.status = "Refreshed"
+	case 'e':
+		return a.editNote()
+	case 'n':
+		a.prompt = &prompt{label: "New note title", done: a.createNote}
+	case 'r':
+		if n := a.selectedNote(); n != nil {
+			a.prompt = &prompt{label: "Rename to", input: []rune(n.Title), done: a.renameNote}
+		}
+	case 'm':
+		if a.selectedNote() != nil {
+			a.prompt = &prompt{label: "Move to notebook", names: a.notebookNames(), done: a.moveNote}
+		}
+	case 'd':
+		if n := a.selectedNote(); n != nil {
+			a.prompt = &prompt{label: "Delete \"" + n.Title + "\"? (y/N)", done: a.deleteNote}
+		}
+	}
+	return nil
+}
+
+// move moves the selection of the focused pane, or scrolls the preview.
+func (a *App) move(delta int) {
+	switch a.focus {
+	case treePane:
+		i := a.treeSel + delta
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(a.tree) {
+			i = len(a.tree) - 1
+		}
+		a.treeSel = i
+	case listPane:
+		a.selectNote(a.listSel + delta)
+	case previewPane:
+		a.previewTop += delta
+		if a.previewTop < 0 {
+			a.previewTop = 0
+		}
+	}
+}
+
+func (a *App) enter() error {
+	switch a.focus {
+	case treePane:
+		a.listSel = 0
+		a.previewTop = 0
+		a.focus = listPane
+		return a.loadNotes()
+	case listPane:
+		a.focus = previewPane
+	case previewPane:
+		return a.editNote()
+	}
+	return nil
+}
+
+func (a *App) handleSearchKey(k Key) error {
+	switch k.Code {
+	case KeyRune:
+		a.filter = append(a.filter, k.Rune)
+	case KeyBackspace:
+		if len(a.filter) > 0 {
+			a.filter = a.filter[:len(a.filter)-1]
+		}
+	case KeyCtrlU:
+		a.filter = nil
+	case KeyEsc, KeyCtrlC:
+		a.searching = false
+		a.filter = nil
+	case KeyEnter:
+		// The live search only filters the loaded notes, enter sends the
+		// search to the notestore.
+		a.searching = false
+		a.query = strings.TrimSpace(string(a.filter))
+		a.filter = nil
+		a.listSel = 0
+		return a.loadNotes()
+	case KeyUp:
+		a.move(-1)
+		return nil
+	case KeyDown:
+		a.move(1)
+		return nil
+	}
+	a.applyFilter()
+	return nil
+}
+
+func (a *App) handlePromptKey(k Key) error {
+	p := a.prompt
+	switch k.Code {
+	case KeyRune:
+		p.input = append(p.input, k.Rune)
+	case KeyBackspace:
+		if len(p.input) > 0 {
+			p.input = p.input[:len(p.input)-1]
+		}
+	case KeyCtrlU:
+		p.input = nil
+	case KeyTab:
+		p.input = []rune(complete(string(p.input), p.names))
+	case KeyEsc, KeyCtrlC:
+		a.prompt = nil
+	case KeyEnter:
+		a.prompt = nil
+		return p.done(strings.TrimSpace(string(p.input)))
+	}
+	return nil
+}
+
+// complete returns the name starting with the prefix. The prefix is
+// returned if no name or more than one name matches.
+func complete(prefix string, names []string) string {
+	match := ""
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			if match != "" {
+				return prefix
+			}
+			match = name
+		}
+	}
+	if match == "" {
+		return prefix
+	}
+	return match
+}
+
+func (a *App) notebookNames() []string {
+	var names []string
+	for _, item := range a.tree {
+		if item.notebook != nil {
+			names = append(names, item.notebook.Name)
+		}
+	}
+	return names
+}
+
+// editNote opens the selected note in the client's editor.
+func (a *App) editNote() error {
+	n := a.selectedNote()
+	if n == nil {
+		return nil
+	}
+	err := a.term.Suspend(func() error {
+		return clinote.EditNote(a.client, clinote.GUIDPrefix+n.GUID, a.opts.NoteOptions)
+	})
+	delete(a.preview, n.GUID)
+	if err != nil {
+		return err
+	}
+	a.status = "Saved \"" + n.Title + "\""
+	return a.loadNotes()
+}
+
+// createNote creates a note in the selected notebook and opens it in
+// the client's editor. The default notebook is used if no notebook is
+// selected.
+func (a *App) createNote(title string) error {
+	if title == "" {
+		return nil
+	}
+	n := &clinote.Note{Title: title}
+	if b := a.tree[a.treeSel].notebook; b != nil {
+		n.Notebook = b
+	}
+	err := a.term.Suspend(func() error {
+		return clinote.CreateAndEditNewNote(a.client, n, a.opts.NoteOptions)
+	})
+	if err != nil {
+		return err
+	}
+	a.status = "Created \"" + n.Title + "\""
+	return a.loadNotes()
+}
+
+func (a *App) renameNote(title string) error {
+	n := a.selectedNote()
+	if n == nil || title == "" || title == n.Title {
+		return nil
+	}
+	if err := clinote.ChangeTitle(a.client.Store, a.client.NoteStore, clinote.GUIDPrefix+n.GUID, title); err != nil {
+		return err
+	}
+	a.status = "Renamed to \"" + title + "\""
+	return a.loadNotes()
+}
+
+func (a *App) moveNote(notebook string) error {
+	n := a.selectedNote()
+	if n == nil || notebook == "" {
+		return nil
+	}
+	if err := clinote.MoveNote(a.client.Store, a.client.NoteStore, clinote.GUIDPrefix+n.GUID, notebook); err != nil {
+		return err
+	}
+	a.status = "Moved \"" + n.Title + "\" to " + notebook
+	return a.loadNotes()
+}
+
+func (a *App) deleteNote(answer string) error {
+	n := a.selectedNote()
+	if n == nil || (answer != "y" && answer != "yes") {
+		return nil
+	}
+	if err := clinote.DeleteNote(a.client.Store, a.client.NoteStore, clinote.GUIDPrefix+n.GUID, ""); err != nil {
+		return err
+	}
+	delete(a.preview, n.GUID)
+	a.status = "Moved \"" + n.Title + "\" to the trash"
+	return a.loadNotes()
+}
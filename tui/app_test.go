@@ -0,0 +1,279 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/clinote/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	guidWork   = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	guidHome   = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	guidInbox  = "cccccccc-cccc-cccc-cccc-cccccccccccc"
+	guidReport = "11111111-1111-1111-1111-111111111111"
+	guidGarden = "22222222-2222-2222-2222-222222222222"
+	guidNew    = testutil.NewNoteGUID
+)
+
+// fakeTerminal returns the keys in order and io.EOF once they are used up.
+type fakeTerminal struct {
+	bytes.Buffer
+	keys      []Key
+	suspended int
+}
+
+func (t *fakeTerminal) Size() (int, int, error) { return 100, 20, nil }
+
+func (t *fakeTerminal) ReadKey() (Key, error) {
+	if len(t.keys) == 0 {
+		return Key{}, io.EOF
+	}
+	k := t.keys[0]
+	t.keys = t.keys[1:]
+	return k, nil
+}
+
+func (t *fakeTerminal) Suspend(fn func() error) error {
+	t.suspended++
+	return fn()
+}
+
+func (t *fakeTerminal) Close() error { return nil }
+
+func newFakeNS() *testutil.Notestore {
+	ns := testutil.NewNotestore(
+		&clinote.Notebook{GUID: guidInbox, Name: "Inbox", Default: true},
+		&clinote.Notebook{GUID: guidWork, Name: "Work", Stack: "Life"},
+		&clinote.Notebook{GUID: guidHome, Name: "Home", Stack: "Life"})
+	ns.AddNote(&clinote.Note{GUID: guidReport, Title: "Weekly report", Notebook: &clinote.Notebook{GUID: guidWork}}, "<h1>Report</h1><p>All good</p>")
+	ns.AddNote(&clinote.Note{GUID: guidGarden, Title: "Garden plan", Notebook: &clinote.Notebook{GUID: guidHome}}, "<p>Tomatoes</p>")
+	return ns
+}
+
+// appendEditor adds a line to the end of the note.
+type appendEditor struct {
+	line  string
+	edits int
+}
+
+func (e *appendEditor) Edit(file clinote.CacheFile) error {
+	e.edits++
+	f, err := os.OpenFile(file.FilePath(), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(e.line + "\n")
+	return err
+}
+
+type testConfig struct {
+	dir string
+	db  clinote.Storager
+}
+
+func (c *testConfig) Close() error                           { return nil }
+func (c *testConfig) GetConfigFolder() string                { return c.dir }
+func (c *testConfig) GetCacheFolder() string                 { return c.dir }
+func (c *testConfig) Store() clinote.Storager                { return c.db }
+func (c *testConfig) UserStore() clinote.UserCredentialStore { return nil }
+
+func setupApp(t *testing.T, keys string) (*App, *testutil.Notestore, *fakeTerminal, *appendEditor, func()) {
+	db, dir, cleanup := testutil.OpenDB(t)
+	ns := newFakeNS()
+	client := clinote.NewClient(&testConfig{dir: dir, db: db}, db, ns, clinote.DefaultClientOptions)
+	editor := &appendEditor{line: "Edited"}
+	client.Editor = editor
+	term := &fakeTerminal{keys: parseKeys(t, keys)}
+	app := New(client, term, Options{})
+	return app, ns, term, editor, cleanup
+}
+
+func parseKeys(t *testing.T, s string) []Key {
+	var keys []Key
+	r := bufio.NewReader(strings.NewReader(s))
+	for {
+		k, err := readKey(r)
+		if err == io.EOF {
+			return keys
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, k)
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Key
+	}{
+		{"a\r", []Key{{Code: KeyRune, Rune: 'a'}, {Code: KeyEnter}}},
+		{"ö", []Key{{Code: KeyRune, Rune: 'ö'}}},
+		{"\x1b[A\x1b[B\x1bOC\x1b[D", []Key{{Code: KeyUp}, {Code: KeyDown}, {Code: KeyRight}, {Code: KeyLeft}}},
+		{"\x1b[5~\x1b[6~\x1b[3~\x1b[Z", []Key{{Code: KeyPgUp}, {Code: KeyPgDn}, {Code: KeyDelete}, {Code: KeyBackTab}}},
+		{"\x7f\t\x03\x15", []Key{{Code: KeyBackspace}, {Code: KeyTab}, {Code: KeyCtrlC}, {Code: KeyCtrlU}}},
+		{"\x01\x05\x04", []Key{{Code: KeyHome}, {Code: KeyEnd}, {Code: KeyCtrlD}}},
+		{"\x1b[99x", []Key{{Code: KeyUnknown}}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, parseKeys(t, test.input), "%q", test.input)
+	}
+	// An escape without anything buffered after it is the escape key.
+	r := bufio.NewReaderSize(strings.NewReader("\x1b"), 16)
+	k, err := readKey(r)
+	assert.NoError(t, err)
+	assert.Equal(t, KeyEsc, k.Code)
+}
+
+func TestFit(t *testing.T) {
+	assert.Equal(t, "abc  ", fit("abc", 5))
+	assert.Equal(t, "abcd", fit("abcdef", 4))
+	assert.Equal(t, "日本 ", fit("日本語", 5))
+	assert.Equal(t, "\x1b[1mab\x1b[0m"+reset+" ", fit("\x1b[1mab\x1b[0m", 3))
+	assert.Equal(t, "a    b  ", fit("a\tb", 8))
+	assert.Equal(t, "", fit("abc", 0))
+}
+
+func TestRender(t *testing.T) {
+	app, _, term, _, cleanup := setupApp(t, "")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+
+	lines := app.render(100, 20)
+	assert.Len(t, lines, 20)
+	for _, line := range lines {
+		assert.Equal(t, 100, len([]rune(line)), line)
+	}
+	screen := strings.Join(lines, "\n")
+	assert.Contains(t, lines[0], "clinote  All notes")
+	assert.Contains(t, lines[1], "> All notes")
+	assert.Contains(t, lines[2], "Life/")
+	assert.Contains(t, lines[3], "    Home")
+	assert.Contains(t, lines[4], "    Work")
+	assert.Contains(t, lines[5], "  Inbox *")
+	assert.Contains(t, screen, "> Weekly report")
+	assert.Contains(t, screen, "# Report")
+	assert.Contains(t, lines[19], "q quit")
+	assert.True(t, term.Len() > 0)
+}
+
+func TestNavigation(t *testing.T) {
+	// Select the Work notebook in the tree and open the preview.
+	app, ns, _, _, cleanup := setupApp(t, "\x1b[Z\x1b[B\x1b[B\x1b[B\r\r")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, "Work", app.tree[app.treeSel].label)
+	assert.Equal(t, guidWork, ns.LastFilter().NotebookGUID)
+	assert.Len(t, app.visible, 1)
+	assert.Equal(t, previewPane, app.focus)
+
+	// Selecting a stack searches the stack.
+	app, ns, _, _, cleanup2 := setupApp(t, "h\x1b[B\r")
+	defer cleanup2()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, `stack:"Life"`, ns.LastFilter().Words)
+}
+
+func TestLiveSearch(t *testing.T) {
+	app, ns, _, _, cleanup := setupApp(t, "/gard")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.True(t, app.searching)
+	assert.Len(t, app.visible, 1)
+	assert.Equal(t, "Garden plan", app.visible[0].Title)
+	assert.Len(t, ns.Filters, 1, "the live search doesn't use the notestore")
+
+	app.handleKey(Key{Code: KeyEnter})
+	assert.False(t, app.searching)
+	assert.Equal(t, "gard", app.query)
+	assert.Equal(t, "gard", ns.Filters[1].Words)
+
+	app.handleKey(Key{Code: KeyEsc})
+	assert.Equal(t, "", app.query)
+	assert.Len(t, app.visible, 2)
+}
+
+func TestRenameNote(t *testing.T) {
+	app, ns, _, _, cleanup := setupApp(t, "r\x15Monthly report\r")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, "Monthly report", ns.Notes[0].Title)
+	assert.Equal(t, `Renamed to "Monthly report"`, app.status)
+}
+
+func TestMoveNote(t *testing.T) {
+	app, ns, _, _, cleanup := setupApp(t, "mho\t\r")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, guidHome, ns.Notes[0].Notebook.GUID)
+	assert.Equal(t, `Moved "Weekly report" to Home`, app.status)
+
+	app, _, _, _, cleanup2 := setupApp(t, "mMissing\r")
+	defer cleanup2()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, "Error: "+clinote.ErrNoNotebookFound.Error(), app.status)
+}
+
+func TestDeleteNote(t *testing.T) {
+	app, ns, _, _, cleanup := setupApp(t, "dn\rjdy\r")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, []string{guidGarden}, ns.Deleted)
+}
+
+func TestEditNote(t *testing.T) {
+	app, ns, term, editor, cleanup := setupApp(t, "e")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, 1, editor.edits)
+	assert.Equal(t, 1, term.suspended)
+	assert.Contains(t, ns.Content[guidReport], "Edited")
+	assert.Equal(t, `Saved "Weekly report"`, app.status)
+}
+
+func TestCreateNote(t *testing.T) {
+	// Create the note in the Home notebook.
+	app, ns, term, editor, cleanup := setupApp(t, "h\x1b[B\x1b[B\rnShopping\r")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.Equal(t, 1, editor.edits)
+	assert.Equal(t, 1, term.suspended)
+	n := ns.Notes[len(ns.Notes)-1]
+	assert.Equal(t, "Shopping", n.Title)
+	assert.Equal(t, guidHome, n.Notebook.GUID)
+	assert.Contains(t, ns.Content[guidNew], "<p>Edited</p>")
+}
+
+func TestQuit(t *testing.T) {
+	app, _, term, _, cleanup := setupApp(t, "qj")
+	defer cleanup()
+	assert.NoError(t, app.Run())
+	assert.True(t, app.quit)
+	assert.Len(t, term.keys, 1)
+}
@@ -0,0 +1,352 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+var (
+	// ErrInterrupted is returned by ReadLine if the user presses ctrl+c.
+	ErrInterrupted = errors.New("interrupted")
+	// ErrUnterminatedQuote is returned if a quote in a line isn't closed.
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+)
+
+// maxCompletionsShown is the number of completions listed when tab is
+// pressed and more than one completion matches.
+const maxCompletionsShown = 50
+
+// Completer returns the completions of the line up to the cursor. The
+// completions replace the line from start.
+type Completer func(line string) (start int, completions []string)
+
+// LineEditor reads lines from a terminal with editing, history and tab
+// completion.
+type LineEditor struct {
+	// Complete completes the line when tab is pressed.
+	Complete Completer
+	// History holds the lines read, oldest first.
+	History []string
+	// tty is set if the keys are read from a terminal. It's put in raw
+	// mode while a line is read.
+	tty *os.File
+	// interactive is set if the line is edited, otherwise lines are read
+	// as is.
+	interactive bool
+	keys        *bufio.Reader
+	out         io.Writer
+}
+
+// NewLineEditor returns an editor reading from in and echoing to out.
+// The line is only edited if in is a terminal.
+func NewLineEditor(in io.Reader, out io.Writer) *LineEditor {
+	e := &LineEditor{keys: bufio.NewReader(in), out: out}
+	if f, ok := in.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			e.tty = f
+			e.interactive = true
+		}
+	}
+	return e
+}
+
+// Input returns the reader the keys are read from. Other input should be
+// read from it so input buffered by the editor isn't lost.
+func (e *LineEditor) Input() *bufio.Reader {
+	return e.keys
+}
+
+// ReadLine shows the prompt and reads a line. Non-empty lines are added
+// to the history. io.EOF is returned if the input ends or the user
+// presses ctrl+d on an empty line.
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+	if !e.interactive {
+		line, err := e.keys.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		line = strings.TrimRight(line, "\r\n")
+		e.addHistory(line)
+		return line, err
+	}
+	if e.tty != nil {
+		state, err := stty(e.tty, "-g")
+		if err != nil {
+			return "", ErrNoTerminal
+		}
+		if _, err = stty(e.tty, "raw", "-echo"); err != nil {
+			return "", ErrNoTerminal
+		}
+		defer stty(e.tty, state)
+	}
+	l := &lineState{prompt: prompt, history: len(e.History)}
+	for {
+		e.draw(l)
+		k, err := readKey(e.keys)
+		if err == io.EOF && len(l.buf) > 0 {
+			err = nil
+			k = Key{Code: KeyEnter}
+		}
+		if err != nil {
+			return "", err
+		}
+		switch k.Code {
+		case KeyEnter:
+			io.WriteString(e.out, "\r\n")
+			line := string(l.buf)
+			e.addHistory(line)
+			return line, nil
+		case KeyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case KeyCtrlD:
+			if len(l.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			l.delete()
+		case KeyRune:
+			l.insert([]rune{k.Rune})
+		case KeyBackspace:
+			if l.pos > 0 {
+				l.pos--
+				l.delete()
+			}
+		case KeyDelete:
+			l.delete()
+		case KeyLeft:
+			if l.pos > 0 {
+				l.pos--
+			}
+		case KeyRight:
+			if l.pos < len(l.buf) {
+				l.pos++
+			}
+		case KeyHome:
+			l.pos = 0
+		case KeyEnd:
+			l.pos = len(l.buf)
+		case KeyCtrlU:
+			l.buf = l.buf[l.pos:]
+			l.pos = 0
+		case KeyUp:
+			e.browseHistory(l, -1)
+		case KeyDown:
+			e.browseHistory(l, 1)
+		case KeyTab:
+			e.complete(l)
+		}
+	}
+}
+
+func (e *LineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.History); n > 0 && e.History[n-1] == line {
+		return
+	}
+	e.History = append(e.History, line)
+}
+
+// lineState is the line being edited.
+type lineState struct {
+	prompt string
+	buf    []rune
+	pos    int
+	// history is the index of the history entry shown and draft the
+	// line typed before the history was browsed.
+	history int
+	draft   []rune
+}
+
+func (l *lineState) insert(r []rune) {
+	buf := make([]rune, 0, len(l.buf)+len(r))
+	buf = append(buf, l.buf[:l.pos]...)
+	buf = append(buf, r...)
+	l.buf = append(buf, l.buf[l.pos:]...)
+	l.pos += len(r)
+}
+
+// delete removes the character at the cursor.
+func (l *lineState) delete() {
+	if l.pos < len(l.buf) {
+		l.buf = append(l.buf[:l.pos], l.buf[l.pos+1:]...)
+	}
+}
+
+func (e *LineEditor) browseHistory(l *lineState, delta int) {
+	i := l.history + delta
+	if i < 0 || i > len(e.History) {
+		return
+	}
+	if l.history == len(e.History) {
+		l.draft = l.buf
+	}
+	l.history = i
+	if i == len(e.History) {
+		l.buf = l.draft
+	} else {
+		l.buf = []rune(e.History[i])
+	}
+	l.pos = len(l.buf)
+}
+
+// complete completes the line up to the cursor. A single completion is
+// inserted followed by a space. If there are more, the common prefix is
+// inserted or the completions are listed.
+func (e *LineEditor) complete(l *lineState) {
+	if e.Complete == nil {
+		return
+	}
+	line := string(l.buf[:l.pos])
+	start, completions := e.Complete(line)
+	if len(completions) == 0 || start < 0 || start > len(line) {
+		return
+	}
+	rest := l.buf[l.pos:]
+	var replacement string
+	if len(completions) == 1 {
+		replacement = completions[0] + " "
+	} else {
+		replacement = commonPrefix(completions)
+		if len(replacement) <= len(line)-start {
+			e.listCompletions(completions)
+			return
+		}
+	}
+	l.buf = []rune(line[:start] + replacement)
+	l.pos = len(l.buf)
+	l.buf = append(l.buf, rest...)
+}
+
+func (e *LineEditor) listCompletions(completions []string) {
+	io.WriteString(e.out, "\r\n")
+	for i, c := range completions {
+		if i == maxCompletionsShown {
+			fmt.Fprintf(e.out, "... %d more", len(completions)-i)
+			break
+		}
+		io.WriteString(e.out, c+"  ")
+	}
+	io.WriteString(e.out, "\r\n")
+}
+
+// draw redraws the line and puts the cursor at its position.
+func (e *LineEditor) draw(l *lineState) {
+	s := "\r" + l.prompt + string(l.buf) + "\x1b[K"
+	if back := runewidth.StringWidth(string(l.buf[l.pos:])); back > 0 {
+		s += fmt.Sprintf("\x1b[%dD", back)
+	}
+	io.WriteString(e.out, s)
+}
+
+func commonPrefix(a []string) string {
+	prefix := []rune(a[0])
+	for _, s := range a[1:] {
+		r := []rune(s)
+		i := 0
+		for i < len(prefix) && i < len(r) && prefix[i] == r[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return string(prefix)
+}
+
+// SplitWords splits the line into words like a shell. Words can be quoted
+// with single or double quotes and a backslash escapes the next character
+// outside single quotes.
+func SplitWords(line string) ([]string, error) {
+	words, last, _, inWord, quote := splitWords(line)
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if inWord {
+		words = append(words, last)
+	}
+	return words, nil
+}
+
+// SplitPartial splits a line being typed into words. The last word is
+// the word being typed, it's empty if the line ends with a space. Start
+// is the index in the line where the last word begins.
+func SplitPartial(line string) (words []string, start int) {
+	words, last, start, _, _ := splitWords(line)
+	return append(words, last), start
+}
+
+// splitWords returns the complete words and the last word with the index
+// it starts at. inWord is false if the line doesn't end in a word and
+// quote is the quote that is still open.
+func splitWords(line string) (words []string, last string, start int, inWord bool, quote rune) {
+	var word []rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			word = append(word, r)
+			escaped = false
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == '\'':
+			word = append(word, r)
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			word = append(word, r)
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, string(word))
+				word = word[:0]
+				inWord = false
+			}
+			continue
+		default:
+			word = append(word, r)
+		}
+		if !inWord {
+			inWord = true
+			start = i
+		}
+	}
+	if !inWord {
+		start = len(line)
+	}
+	return words, string(word), start, inWord, quote
+}
+
+// QuoteWord quotes the word if it has characters SplitWords would split
+// or unquote.
+func QuoteWord(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\"'\\") {
+		return word
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(word) + `"`
+}
@@ -0,0 +1,167 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package tui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLineEditor(input string) (*LineEditor, *bytes.Buffer) {
+	out := new(bytes.Buffer)
+	e := NewLineEditor(strings.NewReader(input), out)
+	e.interactive = true
+	return e, out
+}
+
+func TestReadLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "note list\r", "note list"},
+		{"backspace", "notx\x7fe\r", "note"},
+		{"insert", "nte\x1b[D\x1b[Do\r", "note"},
+		{"home and end", "ote\x01n\x05s\r", "notes"},
+		{"delete", "nXote\x01\x1b[C\x1b[3~\r", "note"},
+		{"kill", "abc def\x1b[D\x1b[D\x1b[D\x15x\r", "xdef"},
+		{"end of input", "note", "note"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, _ := newTestLineEditor(test.input)
+			line, err := e.ReadLine("> ")
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, line)
+		})
+	}
+}
+
+func TestReadLineControl(t *testing.T) {
+	e, out := newTestLineEditor("abc\x03\x04")
+	_, err := e.ReadLine("> ")
+	assert.Equal(t, ErrInterrupted, err)
+	assert.Contains(t, out.String(), "^C")
+	_, err = e.ReadLine("> ")
+	assert.Equal(t, io.EOF, err)
+	assert.Empty(t, e.History)
+}
+
+func TestReadLineHistory(t *testing.T) {
+	e, _ := newTestLineEditor("one\rtwo\rtwo\r\x1b[A\x1b[A\x1b[B!\rdraft\x1b[A\x1b[B\r")
+	for _, expected := range []string{"one", "two", "two", "two!", "draft"} {
+		line, err := e.ReadLine("> ")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+	assert.Equal(t, []string{"one", "two", "two!", "draft"}, e.History)
+}
+
+func TestReadLineNotInteractive(t *testing.T) {
+	e := NewLineEditor(strings.NewReader("note list\r\nquit"), new(bytes.Buffer))
+	line, err := e.ReadLine("> ")
+	assert.NoError(t, err)
+	assert.Equal(t, "note list", line)
+	line, err = e.ReadLine("> ")
+	assert.NoError(t, err)
+	assert.Equal(t, "quit", line)
+	_, err = e.ReadLine("> ")
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadLineComplete(t *testing.T) {
+	names := []string{"notebook", "note", "stack"}
+	complete := func(line string) (int, []string) {
+		words, start := SplitPartial(line)
+		var a []string
+		for _, n := range names {
+			if strings.HasPrefix(n, words[len(words)-1]) {
+				a = append(a, n)
+			}
+		}
+		return start, a
+	}
+
+	e, _ := newTestLineEditor("st\t\r")
+	e.Complete = complete
+	line, _ := e.ReadLine("> ")
+	assert.Equal(t, "stack ", line)
+
+	// The common prefix is completed first and the completions are
+	// listed when tab is pressed again.
+	e, out := newTestLineEditor("x n\t\tb\t\r")
+	e.Complete = complete
+	line, _ = e.ReadLine("> ")
+	assert.Equal(t, "x notebook ", line)
+	assert.Contains(t, out.String(), "notebook  note  ")
+
+	// Text after the cursor is kept.
+	e, _ = newTestLineEditor("s list\x01\x1b[C\t\r")
+	e.Complete = complete
+	line, _ = e.ReadLine("> ")
+	assert.Equal(t, "stack  list", line)
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"note list", []string{"note", "list"}},
+		{"  note   edit  ", []string{"note", "edit"}},
+		{`note edit "My note"`, []string{"note", "edit", "My note"}},
+		{`note edit 'It''s' x`, []string{"note", "edit", "Its", "x"}},
+		{`a "b \"c\"" d\ e`, []string{"a", `b "c"`, "d e"}},
+		{`a '\n' ""`, []string{"a", `\n`, ""}},
+		{"", nil},
+	}
+	for _, test := range tests {
+		words, err := SplitWords(test.line)
+		assert.NoError(t, err, test.line)
+		assert.Equal(t, test.expected, words, test.line)
+	}
+	_, err := SplitWords(`note "open`)
+	assert.Equal(t, ErrUnterminatedQuote, err)
+}
+
+func TestSplitPartial(t *testing.T) {
+	words, start := SplitPartial(`note edit "My no`)
+	assert.Equal(t, []string{"note", "edit", "My no"}, words)
+	assert.Equal(t, 10, start)
+
+	words, start = SplitPartial("note ")
+	assert.Equal(t, []string{"note", ""}, words)
+	assert.Equal(t, 5, start)
+}
+
+func TestQuoteWord(t *testing.T) {
+	assert.Equal(t, "note", QuoteWord("note"))
+	assert.Equal(t, `"My note"`, QuoteWord("My note"))
+	assert.Equal(t, `"say \"hi\" \\o/"`, QuoteWord(`say "hi" \o/`))
+	assert.Equal(t, `""`, QuoteWord(""))
+	for _, w := range []string{"My note", `say "hi" \o/`, "it's"} {
+		words, err := SplitWords("x " + QuoteWord(w))
+		assert.NoError(t, err)
+		assert.Equal(t, w, words[1])
+	}
+}
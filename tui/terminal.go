@@ -0,0 +1,285 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNoTerminal is returned if the terminal can't be put in raw mode.
+var ErrNoTerminal = errors.New("a terminal with stty is required")
+
+// Escape sequences used to control the terminal.
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	cursorHide   = "\x1b[?25l"
+	cursorShow   = "\x1b[?25h"
+	cursorHome   = "\x1b[H"
+	clearScreen  = "\x1b[2J"
+	reverse      = "\x1b[7m"
+	bold         = "\x1b[1m"
+	dim          = "\x1b[2m"
+	reset        = "\x1b[0m"
+)
+
+// KeyCode identifies a key. Printable keys have the code KeyRune.
+type KeyCode int
+
+// The keys the UI handles.
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyBackTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPgUp
+	KeyPgDn
+	KeyHome
+	KeyEnd
+	KeyDelete
+	KeyCtrlC
+	KeyCtrlU
+	KeyCtrlD
+	KeyUnknown
+)
+
+// Key is a key pressed by the user.
+type Key struct {
+	Code KeyCode
+	// Rune is the character for KeyRune.
+	Rune rune
+}
+
+// Terminal is the terminal the UI is drawn on.
+type Terminal interface {
+	io.Writer
+	// Size returns the terminal's width and height.
+	Size() (width, height int, err error)
+	// ReadKey blocks until a key is pressed.
+	ReadKey() (Key, error)
+	// Suspend restores the terminal while fn is running, so fn can
+	// start programs like an editor.
+	Suspend(fn func() error) error
+	// Close restores the terminal.
+	Close() error
+}
+
+// TTY is the controlling terminal. It's put in raw mode with stty so
+// no platform specific system calls are needed.
+type TTY struct {
+	in    *os.File
+	out   io.Writer
+	keys  *bufio.Reader
+	state string
+}
+
+// OpenTTY puts the terminal in raw mode and switches to the alternate
+// screen. The terminal is restored by Close.
+func OpenTTY(out io.Writer) (*TTY, error) {
+	in, err := os.Open("/dev/tty")
+	if err != nil {
+		return nil, ErrNoTerminal
+	}
+	t := &TTY{in: in, out: out, keys: bufio.NewReader(in)}
+	if t.state, err = t.stty("-g"); err != nil {
+		in.Close()
+		return nil, ErrNoTerminal
+	}
+	if err = t.raw(); err != nil {
+		in.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *TTY) raw() error {
+	if _, err := t.stty("raw", "-echo"); err != nil {
+		return err
+	}
+	_, err := io.WriteString(t.out, altScreenOn+cursorHide+clearScreen)
+	return err
+}
+
+func (t *TTY) restore() error {
+	io.WriteString(t.out, reset+cursorShow+altScreenOff)
+	_, err := t.stty(t.state)
+	return err
+}
+
+func (t *TTY) stty(args ...string) (string, error) {
+	return stty(t.in, args...)
+}
+
+// stty runs stty with the terminal as stdin and returns its output.
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Write writes to the terminal.
+func (t *TTY) Write(p []byte) (int, error) {
+	return t.out.Write(p)
+}
+
+// Size returns the terminal's size from stty.
+func (t *TTY) Size() (int, int, error) {
+	return size(t.in)
+}
+
+// TerminalSize returns the size of the controlling terminal from stty.
+// ErrNoTerminal is returned if the process has no terminal.
+func TerminalSize() (int, int, error) {
+	in, err := os.Open("/dev/tty")
+	if err != nil {
+		return 0, 0, ErrNoTerminal
+	}
+	defer in.Close()
+	return size(in)
+}
+
+func size(in *os.File) (int, int, error) {
+	out, err := stty(in, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+	var width, height int
+	fields := strings.Fields(out)
+	if len(fields) == 2 {
+		height, _ = strconv.Atoi(fields[0])
+		width, _ = strconv.Atoi(fields[1])
+	}
+	if width <= 0 || height <= 0 {
+		return 0, 0, ErrNoTerminal
+	}
+	return width, height, nil
+}
+
+// ReadKey reads the next key from the terminal.
+func (t *TTY) ReadKey() (Key, error) {
+	return readKey(t.keys)
+}
+
+// Suspend restores the terminal while fn is running.
+func (t *TTY) Suspend(fn func() error) error {
+	if err := t.restore(); err != nil {
+		return err
+	}
+	err := fn()
+	if e := t.raw(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// Close restores the terminal.
+func (t *TTY) Close() error {
+	err := t.restore()
+	t.in.Close()
+	return err
+}
+
+// readKey decodes a key from the input. Escape sequences arrive in a
+// single read so an escape without buffered input is the escape key.
+func readKey(r *bufio.Reader) (Key, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+	switch c {
+	case '\r', '\n':
+		return Key{Code: KeyEnter}, nil
+	case '\t':
+		return Key{Code: KeyTab}, nil
+	case 0x7f, 0x08:
+		return Key{Code: KeyBackspace}, nil
+	case 0x03:
+		return Key{Code: KeyCtrlC}, nil
+	case 0x15:
+		return Key{Code: KeyCtrlU}, nil
+	case 0x04:
+		return Key{Code: KeyCtrlD}, nil
+	case 0x01:
+		return Key{Code: KeyHome}, nil
+	case 0x05:
+		return Key{Code: KeyEnd}, nil
+	case 0x1b:
+		if r.Buffered() == 0 {
+			return Key{Code: KeyEsc}, nil
+		}
+		return readEscape(r)
+	}
+	if c < 0x20 {
+		return Key{Code: KeyUnknown}, nil
+	}
+	if c < utf8.RuneSelf {
+		return Key{Code: KeyRune, Rune: rune(c)}, nil
+	}
+	r.UnreadByte()
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Code: KeyRune, Rune: ch}, nil
+}
+
+var escapeKeys = map[string]KeyCode{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[3~": KeyDelete,
+	"[5~": KeyPgUp, "[6~": KeyPgDn, "[Z": KeyBackTab,
+}
+
+// readEscape reads the rest of an escape sequence. The sequence ends
+// with a letter or a tilde.
+func readEscape(r *bufio.Reader) (Key, error) {
+	var seq []byte
+	for r.Buffered() > 0 {
+		c, err := r.ReadByte()
+		if err != nil {
+			return Key{}, err
+		}
+		seq = append(seq, c)
+		if len(seq) > 1 && (c == '~' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')) {
+			break
+		}
+		if len(seq) == 1 && c != '[' && c != 'O' {
+			// Alt and a key, the alt is ignored.
+			return Key{Code: KeyRune, Rune: rune(c)}, nil
+		}
+	}
+	if code, ok := escapeKeys[string(seq)]; ok {
+		return Key{Code: code}, nil
+	}
+	return Key{Code: KeyUnknown}, nil
+}
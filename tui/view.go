@@ -0,0 +1,269 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package tui
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TcM1911/clinote/markdown"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+const (
+	// minTreeWidth and maxTreeWidth limit the width of the notebook tree.
+	minTreeWidth = 16
+	maxTreeWidth = 30
+	// separator is drawn between the panes.
+	separator = "│"
+	help      = "tab pane  / search  enter open  e edit  n new  r rename  m move  d delete  R refresh  q quit"
+)
+
+// draw renders the UI and writes it to the terminal.
+func (a *App) draw() error {
+	width, height, err := a.term.Size()
+	if err != nil {
+		return err
+	}
+	a.height = height
+	lines := a.render(width, height)
+	_, err = a.term.Write([]byte(cursorHome + strings.Join(lines, "\r\n")))
+	return err
+}
+
+// pageSize is the number of rows in a pane.
+func (a *App) pageSize() int {
+	if a.height > 3 {
+		return a.height - 2
+	}
+	return 1
+}
+
+// render returns the lines of the screen. Every line fills the width.
+func (a *App) render(width, height int) []string {
+	rows := height - 2
+	if rows < 1 || width < 3 {
+		return nil
+	}
+	treeWidth := width / 5
+	if treeWidth < minTreeWidth {
+		treeWidth = minTreeWidth
+	}
+	if treeWidth > maxTreeWidth {
+		treeWidth = maxTreeWidth
+	}
+	if treeWidth > width/3 {
+		treeWidth = width / 3
+	}
+	listWidth := (width - treeWidth - 2) * 2 / 5
+	previewWidth := width - treeWidth - listWidth - 2
+
+	tree := a.renderTree(treeWidth, rows)
+	list := a.renderList(listWidth, rows)
+	preview := a.renderPreview(previewWidth, rows)
+	sep := separator
+	if a.opts.Color {
+		sep = dim + separator + reset
+	}
+	lines := make([]string, 0, height)
+	lines = append(lines, a.renderHeader(width))
+	for i := 0; i < rows; i++ {
+		lines = append(lines, tree[i]+sep+list[i]+sep+preview[i])
+	}
+	return append(lines, a.renderStatus(width))
+}
+
+func (a *App) renderHeader(width int) string {
+	s := " clinote  " + a.tree[a.treeSel].label
+	if a.query != "" {
+		s += "  search: " + a.query
+	}
+	if a.opts.Color {
+		return reverse + fit(s, width) + reset
+	}
+	return fit(s, width)
+}
+
+func (a *App) renderStatus(width int) string {
+	switch {
+	case a.prompt != nil:
+		return fit(a.prompt.label+": "+string(a.prompt.input)+"▏", width)
+	case a.searching:
+		return fit("/"+string(a.filter)+"▏  enter searches the notestore, esc cancels", width)
+	case a.status != "":
+		return fit(a.status, width)
+	}
+	return a.style(dim, fit(help, width))
+}
+
+func (a *App) renderTree(width, rows int) []string {
+	labels := make([]string, len(a.tree))
+	for i, item := range a.tree {
+		label := strings.Repeat("  ", item.depth) + item.label
+		if item.stack != "" {
+			label += "/"
+		}
+		if item.notebook != nil && item.notebook.Default {
+			label += " *"
+		}
+		labels[i] = label
+	}
+	return a.renderRows(labels, a.treeSel, a.focus == treePane, width, rows)
+}
+
+func (a *App) renderList(width, rows int) []string {
+	labels := make([]string, len(a.visible))
+	for i, n := range a.visible {
+		date := ""
+		if n.Updated > 0 && width > 30 {
+			date = " " + time.Unix(n.Updated/1000, 0).Format("2006-01-02")
+		}
+		labels[i] = fit(n.Title, width-2-len(date)) + date
+	}
+	if len(labels) == 0 {
+		labels = []string{"No notes found"}
+	}
+	return a.renderRows(labels, a.listSel, a.focus == listPane, width, rows)
+}
+
+// renderRows renders a scrolled list with the selected row marked.
+func (a *App) renderRows(labels []string, selected int, focused bool, width, rows int) []string {
+	top := 0
+	if selected >= rows {
+		top = selected - rows + 1
+	}
+	lines := make([]string, rows)
+	for i := range lines {
+		j := top + i
+		if j >= len(labels) {
+			lines[i] = fit("", width)
+			continue
+		}
+		if j != selected {
+			lines[i] = fit("  "+labels[j], width)
+			continue
+		}
+		line := fit("> "+labels[j], width)
+		if focused {
+			line = a.style(reverse, line)
+		} else {
+			line = a.style(bold, line)
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func (a *App) renderPreview(width, rows int) []string {
+	var text []string
+	if n := a.selectedNote(); n != nil {
+		md, err := a.previewMarkdown()
+		if err != nil {
+			md = "Failed to load the note: " + err.Error()
+		}
+		body := markdown.ToTerminal(md, markdown.TerminalOptions{Color: a.opts.Color, Width: width - 1})
+		text = append([]string{a.style(bold, n.Title), ""}, strings.Split(body, "\n")...)
+	}
+	if max := len(text) - rows; a.previewTop > max {
+		a.previewTop = max
+	}
+	if a.previewTop < 0 {
+		a.previewTop = 0
+	}
+	lines := make([]string, rows)
+	for i := range lines {
+		line := ""
+		if j := a.previewTop + i; j < len(text) {
+			line = text[j]
+		}
+		lines[i] = fit(" "+line, width)
+	}
+	return lines
+}
+
+func (a *App) style(on, s string) string {
+	if !a.opts.Color {
+		return s
+	}
+	return on + s + reset
+}
+
+// fit truncates or pads the text to the width. Escape sequences don't
+// use any columns and the style is reset at the end of the text.
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.Replace(s, "\t", "    ", -1)
+	var b strings.Builder
+	used, styled := 0, false
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			j := escapeEnd(s, i)
+			b.WriteString(s[i:j])
+			styled = true
+			i = j
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if r < 0x20 {
+			continue
+		}
+		w := runewidth.RuneWidth(r)
+		if used+w > width {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	if styled {
+		b.WriteString(reset)
+	}
+	b.WriteString(strings.Repeat(" ", width-used))
+	return b.String()
+}
+
+// escapeEnd returns the index after the escape sequence starting at i.
+// Both CSI sequences and OSC hyperlinks are handled.
+func escapeEnd(s string, i int) int {
+	if i+1 >= len(s) {
+		return len(s)
+	}
+	switch s[i+1] {
+	case '[':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+	case ']':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+	default:
+		return i + 2
+	}
+	return len(s)
+}
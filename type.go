@@ -32,10 +32,23 @@ type Storager interface {
 	SaveSearch([]*Note) error
 	// GetSearch returns a saved note search from the database.
 	GetSearch() ([]*Note, error)
+	// SaveTrashSearch stores the last listing of the trash to the database.
+	SaveTrashSearch([]*Note) error
+	// GetTrashSearch returns the last listing of the trash from the database.
+	GetTrashSearch() ([]*Note, error)
 	// SaveNoteRecoveryPoint saves the note as a recovery point.
 	SaveNoteRecoveryPoint(*Note) error
 	// GetNoteREcoveryPoint returns the saved note.
 	GetNoteRecoveryPoint() (*Note, error)
+	// AddRecoveryEntry adds the entry to the recovery journal.
+	AddRecoveryEntry(*RecoveryEntry) error
+	// GetRecoveryJournal returns the entries in the recovery journal,
+	// oldest first.
+	GetRecoveryJournal() ([]*RecoveryEntry, error)
+	// GetLinkIndex returns the stored index of links between notes.
+	GetLinkIndex() (*LinkIndex, error)
+	// StoreLinkIndex saves the link index to the database.
+	StoreLinkIndex(*LinkIndex) error
 }
 
 // UserCredentialStore provides an interface to a backend that stores
@@ -57,6 +70,21 @@ type Settings struct {
 	APIKey string
 	// Credential holds the user's credential data.
 	Credential *Credential
+	// ConfirmEdit shows the changes made to a note and asks for
+	// confirmation before the note is saved.
+	ConfirmEdit bool
+	// Picker is the picker used for interactive note selection.
+	Picker string
+	// TemplateNotebook is the notebook holding shared note templates.
+	TemplateNotebook string
+	// JournalNotebook is the notebook holding the journal notes.
+	JournalNotebook string
+	// JournalTitle is the title pattern for journal notes.
+	JournalTitle string
+	// JournalTemplate is the template used for new journal notes.
+	JournalTemplate string
+	// SearchAliases are local search queries, keyed by the alias name.
+	SearchAliases map[string]string
 }
 
 // Credential is a struct that holds credential information.
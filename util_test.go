@@ -31,6 +31,86 @@ type mockNS struct {
 	createNote      func(n *Note) error
 	updateNotebook  func(b *Notebook) error
 	getNotebook     func(guid string) (*Notebook, error)
+	getNoteVersions func(guid string) ([]*NoteVersion, error)
+	getNoteVersion  func(guid string, usn int) (*Note, error)
+	restoreNote     func(n *Note) error
+	expungeNote     func(guid string) error
+	emptyTrash      func() error
+	expungeNotebook func(guid string) error
+	getNoteCounts   func() (map[string]int, error)
+	getNote         func(guid string) (*Note, error)
+	copyNote        func(guid, notebookGUID string) error
+	exportNote      func(guid string) (*Note, error)
+	getNoteTags     func(guid string) ([]string, error)
+	updateNoteTags  func(n *Note) error
+	noteLink        func(guid string) (string, error)
+	savedSearches   func() ([]*SavedSearch, error)
+	createSearch    func(s *SavedSearch) error
+	deleteSearch    func(guid string) error
+}
+
+func (s *mockNS) GetSavedSearches() ([]*SavedSearch, error) {
+	return s.savedSearches()
+}
+
+func (s *mockNS) CreateSavedSearch(search *SavedSearch) error {
+	return s.createSearch(search)
+}
+
+func (s *mockNS) DeleteSavedSearch(guid string) error {
+	return s.deleteSearch(guid)
+}
+
+func (s *mockNS) NoteLink(guid string) (string, error) {
+	return s.noteLink(guid)
+}
+
+func (s *mockNS) GetNoteTags(guid string) ([]string, error) {
+	return s.getNoteTags(guid)
+}
+
+func (s *mockNS) UpdateNoteTags(n *Note) error {
+	return s.updateNoteTags(n)
+}
+
+func (s *mockNS) CopyNote(guid, notebookGUID string) error {
+	return s.copyNote(guid, notebookGUID)
+}
+
+func (s *mockNS) ExportNote(guid string) (*Note, error) {
+	return s.exportNote(guid)
+}
+
+func (s *mockNS) GetNote(guid string) (*Note, error) {
+	return s.getNote(guid)
+}
+
+func (s *mockNS) ExpungeNotebook(guid string) error {
+	return s.expungeNotebook(guid)
+}
+
+func (s *mockNS) GetNoteCounts() (map[string]int, error) {
+	return s.getNoteCounts()
+}
+
+func (s *mockNS) RestoreNote(n *Note) error {
+	return s.restoreNote(n)
+}
+
+func (s *mockNS) ExpungeNote(guid string) error {
+	return s.expungeNote(guid)
+}
+
+func (s *mockNS) EmptyTrash() error {
+	return s.emptyTrash()
+}
+
+func (s *mockNS) GetNoteVersions(guid string) ([]*NoteVersion, error) {
+	return s.getNoteVersions(guid)
+}
+
+func (s *mockNS) GetNoteVersion(guid string, usn int) (*Note, error) {
+	return s.getNoteVersion(guid, usn)
 }
 
 func (s *mockNS) UpdateNotebook(b *Notebook) error {
@@ -77,8 +157,15 @@ type mockStore struct {
 	getNotebookCache      func() (*NotebookCacheList, error)
 	storeNotebookList     func(list *NotebookCacheList) error
 	getSearch             func() ([]*Note, error)
+	getTrashSearch        func() ([]*Note, error)
 	saveNoteRecoveryPoint func(*Note) error
 	getNoteRecoveryPoint  func() (*Note, error)
+	getSettings           func() (*Settings, error)
+	storeSettings         func(*Settings) error
+	addRecoveryEntry      func(*RecoveryEntry) error
+	getRecoveryJournal    func() ([]*RecoveryEntry, error)
+	getLinkIndex          func() (*LinkIndex, error)
+	storeLinkIndex        func(*LinkIndex) error
 }
 
 func (m *mockStore) SaveNoteRecoveryPoint(n *Note) error {
@@ -89,6 +176,30 @@ func (m *mockStore) GetNoteRecoveryPoint() (*Note, error) {
 	return m.getNoteRecoveryPoint()
 }
 
+// The link index is updated as a side effect of loading and saving notes
+// so a missing mock function is treated as an empty index.
+func (m *mockStore) GetLinkIndex() (*LinkIndex, error) {
+	if m.getLinkIndex == nil {
+		return new(LinkIndex), nil
+	}
+	return m.getLinkIndex()
+}
+
+func (m *mockStore) StoreLinkIndex(index *LinkIndex) error {
+	if m.storeLinkIndex == nil {
+		return nil
+	}
+	return m.storeLinkIndex(index)
+}
+
+func (m *mockStore) AddRecoveryEntry(e *RecoveryEntry) error {
+	return m.addRecoveryEntry(e)
+}
+
+func (m *mockStore) GetRecoveryJournal() ([]*RecoveryEntry, error) {
+	return m.getRecoveryJournal()
+}
+
 func (m *mockStore) SaveSearch([]*Note) error {
 	panic("not implemented")
 }
@@ -97,16 +208,24 @@ func (m *mockStore) GetSearch() ([]*Note, error) {
 	return m.getSearch()
 }
 
+func (m *mockStore) SaveTrashSearch([]*Note) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetTrashSearch() ([]*Note, error) {
+	return m.getTrashSearch()
+}
+
 func (m *mockStore) Close() error {
 	panic("not implemented")
 }
 
 func (m *mockStore) GetSettings() (*Settings, error) {
-	panic("not implemented")
+	return m.getSettings()
 }
 
-func (m *mockStore) StoreSettings(*Settings) error {
-	panic("not implemented")
+func (m *mockStore) StoreSettings(s *Settings) error {
+	return m.storeSettings(s)
 }
 
 func (m *mockStore) GetNotebookCache() (*NotebookCacheList, error) {
@@ -125,6 +244,14 @@ func (m *mockEditor) Edit(file CacheFile) error {
 	return m.edit(file)
 }
 
+type mockConfirmer struct {
+	confirm func(*Note, *Note, NoteOption) (EditAction, error)
+}
+
+func (m *mockConfirmer) Confirm(original, edited *Note, opts NoteOption) (EditAction, error) {
+	return m.confirm(original, edited, opts)
+}
+
 type mockCacheFile struct {
 	buffer *bytes.Buffer
 	write  func([]byte) (int, error)
@@ -191,3 +318,28 @@ func (m *mockCredentialStore) GetAll() ([]*Credential, error) {
 func (m *mockCredentialStore) GetByIndex(index int) (*Credential, error) {
 	return m.getByIndex(index)
 }
+
+type mockConfig struct {
+	dir   string
+	store Storager
+}
+
+func (c *mockConfig) Close() error {
+	return nil
+}
+
+func (c *mockConfig) GetConfigFolder() string {
+	return c.dir
+}
+
+func (c *mockConfig) GetCacheFolder() string {
+	return c.dir
+}
+
+func (c *mockConfig) Store() Storager {
+	return c.store
+}
+
+func (c *mockConfig) UserStore() UserCredentialStore {
+	panic("not implemented")
+}
@@ -0,0 +1,106 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+// NoteVersion is a version of a note saved by the notestore.
+type NoteVersion struct {
+	// USN is the update sequence number of the version.
+	USN int
+	// Title is the note's title in the version.
+	Title string
+	// Updated is when the note was modified.
+	Updated int64
+	// Saved is when the version was saved by the notestore.
+	Saved int64
+}
+
+// GetNoteVersions returns the saved versions of the note.
+func GetNoteVersions(db Storager, ns NotestoreClient, title string) ([]*NoteVersion, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	return ns.GetNoteVersions(n.GUID)
+}
+
+// GetNoteVersionWithContent returns the note with content as it was
+// at the version with the update sequence number.
+func GetNoteVersionWithContent(db Storager, ns NotestoreClient, title string, usn int) (*Note, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	return getNoteVersion(ns, n, usn)
+}
+
+// DiffNoteVersion writes a unified diff between the version and
+// the current note using the writer.
+func DiffNoteVersion(w io.Writer, db Storager, ns NotestoreClient, title string, usn int, opts NoteOption) error {
+	current, err := GetNoteWithContent(db, ns, title)
+	if err != nil {
+		return err
+	}
+	version, err := getNoteVersion(ns, current, usn)
+	if err != nil {
+		return err
+	}
+	return WriteNoteDiff(w, version, current, "version "+strconv.Itoa(usn), "current", opts)
+}
+
+// RestoreNoteVersion replaces the note's title and content with the
+// title and content of the version.
+func RestoreNoteVersion(db Storager, ns NotestoreClient, title string, usn int) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	v, err := getNoteVersion(ns, n, usn)
+	if err != nil {
+		return err
+	}
+	n.Title = v.Title
+	n.Body = v.Body
+	return saveChanges(ns, n, true, true)
+}
+
+func getNoteVersion(ns NotestoreClient, n *Note, usn int) (*Note, error) {
+	v, err := ns.GetNoteVersion(n.GUID, usn)
+	if err != nil {
+		return nil, err
+	}
+	content := v.Body
+	v.Body = ""
+	if err = decodeXML(content, v); err != nil {
+		return nil, err
+	}
+	v.MD, err = markdown.FromHTML(v.Body)
+	if err != nil {
+		return nil, err
+	}
+	if v.Notebook == nil || v.Notebook.GUID == "" {
+		v.Notebook = n.Notebook
+	}
+	return v, nil
+}
@@ -0,0 +1,95 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteVersions(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	versionContent := XMLHeader + "<en-note><p>Old content</p></en-note>"
+	setupNS := func() (*mockNS, *Note) {
+		note := &Note{Title: "Note", GUID: "GUID", Notebook: &Notebook{GUID: "Notebook GUID"}}
+		ns := nsWithNote(note)
+		ns.getNoteContent = func(string) (string, error) {
+			return XMLHeader + "<en-note><p>New content</p></en-note>", nil
+		}
+		ns.getNoteVersion = func(guid string, usn int) (*Note, error) {
+			if guid != note.GUID || usn != 12 {
+				return nil, errors.New("wrong version")
+			}
+			return &Note{Title: "Old note", GUID: guid, Body: versionContent}, nil
+		}
+		return ns, note
+	}
+
+	t.Run("list versions", func(t *testing.T) {
+		ns, _ := setupNS()
+		expected := []*NoteVersion{&NoteVersion{USN: 12}}
+		ns.getNoteVersions = func(guid string) ([]*NoteVersion, error) { return expected, nil }
+		vs, err := GetNoteVersions(store, ns, "Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expected, vs, "Wrong versions returned")
+	})
+	t.Run("get version with content", func(t *testing.T) {
+		ns, note := setupNS()
+		v, err := GetNoteVersionWithContent(store, ns, "Note", 12)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Old note", v.Title, "Wrong title")
+		assert.Equal("<p>Old content</p>", v.Body, "Body should be decoded")
+		assert.Equal("Old content", v.MD, "Body should be converted to markdown")
+		assert.Equal(note.Notebook, v.Notebook, "Should use the note's notebook")
+	})
+	t.Run("diff version", func(t *testing.T) {
+		ns, _ := setupNS()
+		buf := new(bytes.Buffer)
+		err := DiffNoteVersion(buf, store, ns, "Note", 12, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(buf.String(), "--- version 12")
+		assert.Contains(buf.String(), "-title: Old note")
+		assert.Contains(buf.String(), "+title: Note")
+		assert.Contains(buf.String(), "-Old content")
+		assert.Contains(buf.String(), "+New content")
+	})
+	t.Run("restore version", func(t *testing.T) {
+		ns, note := setupNS()
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		err := RestoreNoteVersion(store, ns, "Note", 12)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(note.GUID, saved.GUID, "Wrong note updated")
+		assert.Equal("Old note", saved.Title, "Title should be restored")
+		assert.Equal(XMLHeader+"<en-note><p>Old content</p></en-note>", saved.Body, "Content should be restored")
+	})
+	t.Run("return error from GetNoteVersion", func(t *testing.T) {
+		ns, _ := setupNS()
+		expectedErr := errors.New("expected error")
+		ns.getNoteVersion = func(string, int) (*Note, error) { return nil, expectedErr }
+		err := RestoreNoteVersion(store, ns, "Note", 12)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
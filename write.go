@@ -18,20 +18,31 @@
 package clinote
 
 import (
+	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
 )
 
-const timeFormat = "2006-01-02"
+const (
+	timeFormat      = "2006-01-02"
+	timestampFormat = "2006-01-02 15:04:05"
+)
 
 var (
 	noteListingHeader     = []string{"#", "Title", "Notebook", "Modified", "Created"}
-	notebookListingHeader = []string{"#", "Name"}
-	credentialHeader      = append(notebookListingHeader, "Type")
+	notebookListingHeader = []string{"#", "Name", "Stack"}
+	credentialHeader      = []string{"#", "Name", "Type"}
 	settingsHeader        = []string{"Setting", "Arguments", "Description"}
+	noteVersionHeader     = []string{"USN", "Title", "Modified", "Saved"}
+	templateHeader        = []string{"Name", "Source"}
+	recoveryHeader        = []string{"#", "Title", "Operation", "Changed"}
+	linkHeader            = []string{"#", "Title", "GUID"}
+	savedSearchHeader     = []string{"Name", "Query"}
+	searchAliasHeader     = []string{"Alias", "Query"}
 )
 
 // WriteNoteListing creates and writes a note listing table using the writer.
@@ -55,13 +66,123 @@ func WriteNoteListing(w io.Writer, ns []*Note, nbs []*Notebook) {
 	table.Render()
 }
 
+// WriteNoteVersionListing creates and writes a note version listing table using the writer.
+func WriteNoteVersionListing(w io.Writer, vs []*NoteVersion) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(noteVersionHeader)
+	for _, v := range vs {
+		modified := time.Unix(v.Updated/1000, 0).Format(timestampFormat)
+		saved := time.Unix(v.Saved/1000, 0).Format(timestampFormat)
+		table.Append([]string{strconv.Itoa(v.USN), v.Title, modified, saved})
+	}
+	table.Render()
+}
+
 // WriteNotebookListing creates and writes a notebook listing table using the writer.
 func WriteNotebookListing(w io.Writer, nbs []*Notebook) {
 	table := tablewriter.NewWriter(w)
 	table.SetHeader(notebookListingHeader)
 	for i, nb := range nbs {
 		index := strconv.Itoa(i + 1)
-		table.Append([]string{index, nb.Name})
+		table.Append([]string{index, displayNotebookName(nb), nb.Stack})
+	}
+	table.Render()
+}
+
+// WriteNotebookTree writes the notebooks grouped by stack as a tree. The
+// number of notes in each notebook is taken from counts, keyed by the
+// notebook's GUID. Notebooks without a stack are written after the stacks.
+func WriteNotebookTree(w io.Writer, nbs []*Notebook, counts map[string]int) {
+	for _, s := range groupByStack(nbs) {
+		fmt.Fprintln(w, s.Name)
+		for i, nb := range s.Notebooks {
+			branch := "├── "
+			if i == len(s.Notebooks)-1 {
+				branch = "└── "
+			}
+			fmt.Fprintf(w, "%s%s (%d)\n", branch, displayNotebookName(nb), counts[nb.GUID])
+		}
+	}
+	var unstacked []*Notebook
+	for _, nb := range nbs {
+		if nb.Stack == "" {
+			unstacked = append(unstacked, nb)
+		}
+	}
+	sort.Slice(unstacked, func(i, j int) bool { return unstacked[i].Name < unstacked[j].Name })
+	for _, nb := range unstacked {
+		fmt.Fprintf(w, "%s (%d)\n", displayNotebookName(nb), counts[nb.GUID])
+	}
+}
+
+// displayNotebookName returns the notebook's name with the default notebook marked.
+func displayNotebookName(nb *Notebook) string {
+	if nb.Default {
+		return nb.Name + " (default)"
+	}
+	return nb.Name
+}
+
+// WriteTemplateListing creates and writes a template listing table using the writer.
+func WriteTemplateListing(w io.Writer, ts []*Template) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(templateHeader)
+	for _, t := range ts {
+		source := "config folder"
+		if t.Notebook != "" {
+			source = "notebook: " + t.Notebook
+		}
+		table.Append([]string{t.Name, source})
+	}
+	table.Render()
+}
+
+// WriteRecoveryJournal creates and writes a recovery journal listing table using the writer.
+func WriteRecoveryJournal(w io.Writer, entries []*RecoveryEntry) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(recoveryHeader)
+	for i, e := range entries {
+		changed := time.Unix(e.Time, 0).Format(timestampFormat)
+		table.Append([]string{strconv.Itoa(i + 1), e.Note.Title, e.Operation, changed})
+	}
+	table.Render()
+}
+
+// WriteLinkListing creates and writes a listing of linked notes using the writer.
+func WriteLinkListing(w io.Writer, notes []*Note) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(linkHeader)
+	for i, n := range notes {
+		title := n.Title
+		if title == "" {
+			title = "(not found)"
+		}
+		table.Append([]string{strconv.Itoa(i + 1), title, n.GUID})
+	}
+	table.Render()
+}
+
+// WriteSavedSearchListing creates and writes a saved search listing table using the writer.
+func WriteSavedSearchListing(w io.Writer, searches []*SavedSearch) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(savedSearchHeader)
+	for _, s := range searches {
+		table.Append([]string{s.Name, s.Query})
+	}
+	table.Render()
+}
+
+// WriteSearchAliasListing creates and writes a search alias listing table using the writer.
+func WriteSearchAliasListing(w io.Writer, aliases map[string]string) {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(searchAliasHeader)
+	for _, name := range names {
+		table.Append([]string{SearchAliasPrefix + name, aliases[name]})
 	}
 	table.Render()
 }
@@ -27,8 +27,8 @@ import (
 func TestWritingNoteAndNotebookTables(t *testing.T) {
 	assert := assert.New(t)
 	nbs := []*Notebook{
-		&Notebook{GUID: "GUID1", Name: "Notebook1"},
-		&Notebook{GUID: "GUID2", Name: "Notebook2"},
+		&Notebook{GUID: "GUID1", Name: "Notebook1", Default: true},
+		&Notebook{GUID: "GUID2", Name: "Notebook2", Stack: "Stack1"},
 		&Notebook{GUID: "GUID3", Name: "Notebook3"},
 	}
 	notes := []*Note{
@@ -43,6 +43,12 @@ func TestWritingNoteAndNotebookTables(t *testing.T) {
 		assert.Equal(expectedNotebooklist, string(buf.Bytes()), "Notebook list table doesn't match")
 	})
 
+	t.Run("NotebookTree", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		WriteNotebookTree(buf, nbs, map[string]int{"GUID1": 3, "GUID2": 1})
+		assert.Equal(expectedNotebookTree, string(buf.Bytes()), "Notebook tree doesn't match")
+	})
+
 	t.Run("NoteList", func(t *testing.T) {
 		buf := new(bytes.Buffer)
 		WriteNoteListing(buf, notes, nbs)
@@ -78,13 +84,18 @@ func TestSettingsTable(t *testing.T) {
 	assert.Equal(expectedSettingList, string(buf.Bytes()))
 }
 
-const expectedNotebooklist = `+---+-----------+
-| # |   NAME    |
-+---+-----------+
-| 1 | Notebook1 |
-| 2 | Notebook2 |
-| 3 | Notebook3 |
-+---+-----------+
+const expectedNotebooklist = `+---+---------------------+--------+
+| # |        NAME         | STACK  |
++---+---------------------+--------+
+| 1 | Notebook1 (default) |        |
+| 2 | Notebook2           | Stack1 |
+| 3 | Notebook3           |        |
++---+---------------------+--------+
+`
+const expectedNotebookTree = `Stack1
+└── Notebook2 (1)
+Notebook1 (default) (3)
+Notebook3 (0)
 `
 const expectedNotelist = `+---+-------+-----------+------------+------------+
 | # | TITLE | NOTEBOOK  |  MODIFIED  |  CREATED   |